@@ -0,0 +1,153 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package format implements the `buresu fmt` command.
+package format
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/bassosimone/buresu/cmd/internal/cliutils"
+	"github.com/bassosimone/buresu/internal/textdiff"
+	"github.com/bassosimone/buresu/pkg/formatter"
+	"github.com/spf13/pflag"
+)
+
+// NewCommand creates the `buresu fmt` [cliutils.Command].
+func NewCommand() cliutils.Command {
+	return command{}
+}
+
+// command implements [cliutils.command].
+type command struct{}
+
+var _ cliutils.Command = command{}
+
+//go:embed README.txt
+var readme string
+
+// Help implements [cliutils.Command].
+func (cmd command) Help(argv ...string) error {
+	fmt.Fprintf(os.Stdout, "%s\n", readme)
+	return nil
+}
+
+// Main implements [cliutils.Command].
+func (cmd command) Main(ctx context.Context, argv ...string) error {
+	// 1. intercept and handle -h, --help, help
+	if cliutils.HelpRequested(argv...) {
+		return cmd.Help()
+	}
+
+	// 2. create command line parser
+	clip := pflag.NewFlagSet("buresu fmt", pflag.ContinueOnError)
+
+	// 3. add options to the parser
+	var diff, write bool
+	clip.BoolVarP(&diff, "diff", "d", false, "Print a diff instead of the formatted source")
+	clip.BoolVarP(&write, "write", "w", false, "Write the formatted source back to the files")
+
+	// 4. parse the command line
+	if err := clip.Parse(argv[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "buresu fmt: %s\n", err.Error())
+		fmt.Fprintf(os.Stderr, "Run `buresu fmt --help` for usage.\n")
+		return err
+	}
+	if diff && write {
+		err := errors.New("cannot use both --diff and --write")
+		fmt.Fprintf(os.Stderr, "buresu fmt: %s\n", err.Error())
+		fmt.Fprintf(os.Stderr, "Run `buresu fmt --help` for usage.\n")
+		return err
+	}
+
+	// 5. format the standard input when there are no positional arguments
+	args := clip.Args()
+	if len(args) <= 0 {
+		if write {
+			err := errors.New("cannot use --write with the standard input")
+			fmt.Fprintf(os.Stderr, "buresu fmt: %s\n", err.Error())
+			return err
+		}
+		source, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "buresu fmt: %s\n", err.Error())
+			return err
+		}
+		return cmd.formatSource("<stdin>", source, diff, false)
+	}
+
+	// 6. otherwise, format all the files and directories, continuing
+	// on errors such that we report all the malformed files
+	var failed error
+	for _, arg := range args {
+		err := filepath.WalkDir(arg, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() || (path != arg && filepath.Ext(path) != ".brs") {
+				return nil
+			}
+			if err := cmd.formatFile(path, diff, write); err != nil {
+				failed = err
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "buresu fmt: %s\n", err.Error())
+			failed = err
+		}
+	}
+	return failed
+}
+
+// formatFile formats the given file.
+func (cmd command) formatFile(path string, diff, write bool) error {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "buresu fmt: %s\n", err.Error())
+		return err
+	}
+	return cmd.formatSource(path, source, diff, write)
+}
+
+// formatSource formats the given source and prints the result, a diff, or writes
+// the result back to the file with the given path, depending on the flags.
+func (cmd command) formatSource(path string, source []byte, diff, write bool) error {
+	formatted, err := formatter.Format(path, source)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "buresu fmt: %s\n", err.Error())
+		return err // already wrapped
+	}
+
+	switch {
+	case diff:
+		fmt.Fprint(os.Stdout, textdiff.Unified(path+".orig", path, string(source), string(formatted)))
+		return nil
+
+	case write:
+		if bytes.Equal(source, formatted) {
+			return nil
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "buresu fmt: %s\n", err.Error())
+			return err
+		}
+		if err := os.WriteFile(path, formatted, info.Mode().Perm()); err != nil {
+			fmt.Fprintf(os.Stderr, "buresu fmt: %s\n", err.Error())
+			return err
+		}
+		return nil
+
+	default:
+		_, err := os.Stdout.Write(formatted)
+		return err
+	}
+}
@@ -0,0 +1,240 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package lint implements the `buresu lint` command.
+package lint
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/bassosimone/buresu/cmd/internal/cliutils"
+	"github.com/bassosimone/buresu/pkg/diagnostics"
+	"github.com/bassosimone/buresu/pkg/includer"
+	"github.com/bassosimone/buresu/pkg/lint"
+	"github.com/bassosimone/buresu/pkg/parser"
+	"github.com/bassosimone/buresu/pkg/scanner"
+	"github.com/spf13/pflag"
+)
+
+// NewCommand creates the `buresu lint` [cliutils.Command].
+func NewCommand() cliutils.Command {
+	return command{}
+}
+
+// command implements [cliutils.command].
+type command struct{}
+
+var _ cliutils.Command = command{}
+
+//go:embed README.txt
+var readme string
+
+// Help implements [cliutils.Command].
+func (cmd command) Help(argv ...string) error {
+	fmt.Fprintf(os.Stdout, "%s\n", readme)
+	return nil
+}
+
+// errProblemsFound indicates that the linter found problems.
+var errProblemsFound = errors.New("found problems")
+
+// Main implements [cliutils.Command].
+func (cmd command) Main(ctx context.Context, argv ...string) error {
+	// 1. intercept and handle -h, --help, help
+	if cliutils.HelpRequested(argv...) {
+		return cmd.Help()
+	}
+
+	// 2. create command line parser
+	clip := pflag.NewFlagSet("buresu lint", pflag.ContinueOnError)
+
+	// 3. add options to the parser
+	var includeDirs []string
+	clip.StringArrayVarP(&includeDirs, "include-dir", "I", []string{}, "Add directory to the include search path")
+	var stdlibDir string
+	clip.StringVar(&stdlibDir, "stdlib-dir", "", "Read the standard library from directory instead of using the embedded one")
+	var enable, disable []string
+	clip.StringArrayVar(&enable, "enable", []string{}, "Only run the given rule (can be used multiple times)")
+	clip.StringArrayVar(&disable, "disable", []string{}, "Do not run the given rule (can be used multiple times)")
+	var listRules bool
+	clip.BoolVar(&listRules, "list-rules", false, "List the available rules and exit")
+	var format string
+	clip.StringVar(&format, "format", "text", "Output format: text or json")
+
+	// 4. parse the command line
+	if err := clip.Parse(argv[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "buresu lint: %s\n", err.Error())
+		fmt.Fprintf(os.Stderr, "Run `buresu lint --help` for usage.\n")
+		return err
+	}
+	if format != "text" && format != "json" {
+		err := fmt.Errorf("invalid --format value: %s", format)
+		fmt.Fprintf(os.Stderr, "buresu lint: %s\n", err.Error())
+		fmt.Fprintf(os.Stderr, "Run `buresu lint --help` for usage.\n")
+		return err
+	}
+
+	// 5. select the rules to run
+	rules, err := selectRules(enable, disable)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "buresu lint: %s\n", err.Error())
+		fmt.Fprintf(os.Stderr, "Run `buresu lint --list-rules` for the available rules.\n")
+		return err
+	}
+
+	// 6. handle the request to list the rules
+	if listRules {
+		for _, rule := range lint.Rules() {
+			fmt.Fprintf(os.Stdout, "%-20s %s\n", rule.Name, rule.Doc)
+		}
+		return nil
+	}
+
+	// 7. make sure there is at least a positional argument
+	args := clip.Args()
+	if len(args) <= 0 {
+		err := errors.New("expected at least one FILE or DIR argument")
+		fmt.Fprintf(os.Stderr, "buresu lint: %s\n", err.Error())
+		fmt.Fprintf(os.Stderr, "Run `buresu lint --help` for usage.\n")
+		return err
+	}
+
+	// 8. lint all the files and directories, continuing on errors such
+	// that we report the problems of all the files, where, using the JSON
+	// format, we report the errors along with the problems, such that
+	// tools reading the output see why we could not lint a file
+	searchPath := includer.NewSearchPath(includeDirs, cliutils.StdlibLocation(stdlibDir))
+	diagnostics := []lint.Diagnostic{}
+	var failed error
+	report := func(err error) {
+		failed = err
+		if format == "json" {
+			diagnostics = append(diagnostics, errorDiagnostic(err))
+			return
+		}
+		fmt.Fprintf(os.Stderr, "buresu lint: %s\n", err.Error())
+	}
+	for _, arg := range args {
+		err := filepath.WalkDir(arg, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() || (path != arg && filepath.Ext(path) != ".brs") {
+				return nil
+			}
+			found, errs := cmd.lintFile(searchPath, path, rules)
+			for _, err := range errs {
+				report(err)
+			}
+			diagnostics = append(diagnostics, found...)
+			return nil
+		})
+		if err != nil {
+			report(err)
+		}
+	}
+
+	// 9. print the diagnostics
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(diagnostics); err != nil {
+			fmt.Fprintf(os.Stderr, "buresu lint: %s\n", err.Error())
+			return err
+		}
+	default:
+		for _, diag := range diagnostics {
+			fmt.Fprintf(os.Stdout, "%s\n", diag.String())
+		}
+	}
+
+	// 10. fail when we could not lint or found problems
+	if failed == nil && len(diagnostics) > 0 {
+		failed = errProblemsFound
+	}
+	return failed
+}
+
+// selectRules returns the rules to run given the enabled and disabled ones.
+func selectRules(enable, disable []string) ([]*lint.Rule, error) {
+	for _, name := range append(slices.Clone(enable), disable...) {
+		if _, found := lint.Lookup(name); !found {
+			return nil, fmt.Errorf("unknown rule: %s", name)
+		}
+	}
+	var rules []*lint.Rule
+	for _, rule := range lint.Rules() {
+		if len(enable) > 0 && !slices.Contains(enable, rule.Name) {
+			continue
+		}
+		if slices.Contains(disable, rule.Name) {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// errorDiagnostic converts an error preventing us from linting a file to a
+// [lint.Diagnostic] whose rule is the phase that failed (e.g., `parser`), or
+// `error` when the error does not come from a phase (e.g., reading the file).
+func errorDiagnostic(err error) lint.Diagnostic {
+	diag := diagnostics.FromError(err)
+	rule, pos := diag.Phase, diag.Span.Start
+	if rule == "" {
+		rule = "error"
+	}
+	var pathErr *fs.PathError
+	if !diag.HasSpan() && errors.As(err, &pathErr) {
+		pos.FileName = pathErr.Path
+	}
+	return lint.Diagnostic{Pos: pos, Rule: rule, Message: diag.Message}
+}
+
+// lintFile scans, parses and includes the given file and returns the
+// diagnostics of the given rules that refer to the file itself, or the
+// errors that prevented us from linting the file.
+func (cmd command) lintFile(searchPath []includer.Location, path string, rules []*lint.Rule) ([]lint.Diagnostic, []error) {
+	// 1. scan the file to produce tokens
+	filep, err := os.Open(path)
+	if err != nil {
+		return nil, []error{err}
+	}
+	defer filep.Close()
+	tokens, err := scanner.Scan(path, filep)
+	if err != nil {
+		return nil, []error{err} // already wrapped
+	}
+
+	// 2. parse the tokens to produce an AST, reporting all the errors
+	// rather than just the first one, in which case we do not run the
+	// rules, since their diagnostics would refer to a partial AST
+	nodes, errs := parser.ParseWithRecovery(tokens)
+	if len(errs) > 0 {
+		return nil, errs // already wrapped
+	}
+
+	// 3. include the other files such that we know their symbols
+	nodes, err = includer.Include(searchPath, nodes)
+	if err != nil {
+		return nil, []error{err} // already wrapped
+	}
+
+	// 4. run the rules and only keep the diagnostics of this file, since
+	// we lint the included files when they are passed as arguments
+	var diagnostics []lint.Diagnostic
+	for _, diag := range lint.Run(nodes, rules) {
+		if diag.Pos.FileName == path {
+			diagnostics = append(diagnostics, diag)
+		}
+	}
+	return diagnostics, nil
+}
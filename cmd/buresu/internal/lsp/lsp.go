@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package lsp implements the `buresu lsp` command.
+package lsp
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"os"
+
+	"github.com/bassosimone/buresu/cmd/internal/cliutils"
+	"github.com/bassosimone/buresu/pkg/includer"
+	"github.com/bassosimone/buresu/pkg/langserver"
+	"github.com/kballard/go-shellquote"
+	"github.com/spf13/pflag"
+)
+
+// NewCommand creates the `buresu lsp` [cliutils.Command].
+func NewCommand() cliutils.Command {
+	return command{}
+}
+
+// command implements [cliutils.command].
+type command struct{}
+
+var _ cliutils.Command = command{}
+
+//go:embed README.txt
+var readme string
+
+// Help implements [cliutils.Command].
+func (cmd command) Help(argv ...string) error {
+	fmt.Fprintf(os.Stdout, "%s\n", readme)
+	return nil
+}
+
+// Main implements [cliutils.Command].
+func (cmd command) Main(ctx context.Context, argv ...string) error {
+	// 1. intercept and handle -h, --help, help
+	if cliutils.HelpRequested(argv...) {
+		return cmd.Help()
+	}
+
+	// 2. create command line parser
+	clip := pflag.NewFlagSet("buresu lsp", pflag.ContinueOnError)
+
+	// 3. add options to the parser
+	var includeDirs []string
+	clip.StringArrayVarP(&includeDirs, "include-dir", "I", []string{}, "Add directory to the include search path")
+	var stdlibDir string
+	clip.StringVar(&stdlibDir, "stdlib-dir", "", "Read the standard library from directory instead of using the embedded one")
+
+	// 4. parse the command line
+	if err := clip.Parse(argv[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "buresu lsp: %s\n", err.Error())
+		fmt.Fprintf(os.Stderr, "Run `buresu lsp --help` for usage.\n")
+		return err
+	}
+
+	// 5. make sure there are no positional arguments
+	if args := clip.Args(); len(args) > 0 {
+		err := fmt.Errorf("expected no argument, got: %v", shellquote.Join(args...))
+		fmt.Fprintf(os.Stderr, "buresu lsp: %s\n", err.Error())
+		fmt.Fprintf(os.Stderr, "Run `buresu lsp --help` for usage.\n")
+		return err
+	}
+
+	// 6. serve the client using the standard input and output, which
+	// means that we must only write errors on the standard error
+	stdlibLoc := cliutils.StdlibLocation(stdlibDir)
+	server := langserver.NewServer(includer.NewSearchPath(includeDirs, stdlibLoc), stdlibLoc)
+	if err := server.Serve(ctx, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "buresu lsp: %s\n", err.Error())
+		return err
+	}
+	return nil
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/bassosimone/buresu/cmd/internal/cliutils"
 	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/diagnostics"
 	"github.com/bassosimone/buresu/pkg/evaluator"
 	"github.com/bassosimone/buresu/pkg/includer"
 	"github.com/bassosimone/buresu/pkg/parser"
@@ -64,6 +65,12 @@ func (cmd command) Main(ctx context.Context, argv ...string) error {
 	// 3. add options to the parser
 	var features []string
 	clip.StringArrayVarP(&features, "feature", "X", []string{}, "Enable experimental features (e.g., typechecker)")
+	var includeDirs []string
+	clip.StringArrayVarP(&includeDirs, "include-dir", "I", []string{}, "Add directory to the include search path")
+	var stdlibDir string
+	clip.StringVar(&stdlibDir, "stdlib-dir", "", "Read the standard library from directory instead of using the embedded one")
+	var diagnosticsFormat string
+	clip.StringVar(&diagnosticsFormat, "diagnostics", "auto", "Format of the diagnostics: auto, text, color, or json")
 
 	// 4. parse the command line
 	if err := clip.Parse(argv[1:]); err != nil {
@@ -81,13 +88,31 @@ func (cmd command) Main(ctx context.Context, argv ...string) error {
 		return err
 	}
 
-	// 6. create a map of enabled features
+	// 6. create a map of enabled features, the include search path and the
+	// cache, which lives in memory such that we do not parse again included
+	// files unless they change during the REPL session
 	enabledFeatures := make(map[string]struct{})
 	for _, feature := range features {
 		enabledFeatures[feature] = struct{}{}
 	}
+	stdlibLoc := cliutils.StdlibLocation(stdlibDir)
+	searchPath := includer.NewSearchPath(includeDirs, stdlibLoc)
+	cache := includer.NewMemoryCache()
 
-	// 7. initialize the readline library
+	// 7. create the diagnostics renderer, which also needs to know about
+	// the search path to show the source code of the included files
+	format, err := diagnostics.ParseFormat(diagnosticsFormat, os.Stderr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "buresu repl: %s\n", err.Error())
+		fmt.Fprintf(os.Stderr, "Run `buresu repl --help` for usage.\n")
+		return err
+	}
+	renderer := diagnostics.NewRenderer(format)
+	for _, loc := range searchPath {
+		renderer.AddLocation(loc)
+	}
+
+	// 8. initialize the readline library
 	rl, err := readline.New("> ")
 	if err != nil {
 		err = fmt.Errorf("failed to initialize readline: %w", err)
@@ -96,75 +121,133 @@ func (cmd command) Main(ctx context.Context, argv ...string) error {
 	}
 	defer rl.Close()
 
-	// 8. create the runtime environment
+	// 9. create the runtime environment, stopping the generators that the
+	// program did not exhaust when we are done
 	rootScope := evaluator.NewGlobalEnvironment(os.Stdout)
-	tcEnv, err := typechecker.NewGlobalEnvironment(ctx, ".")
+	defer rootScope.StopGenerators()
+	tcEnv, err := typechecker.NewGlobalEnvironment(ctx, stdlibLoc, cache)
 	if err != nil {
 		err = fmt.Errorf("failed to load the standard library runtime: %w", err)
 		fmt.Fprintf(os.Stderr, "buresu repl: %s\n", err.Error())
 		return err
 	}
+	if err := evaluator.LoadPrelude(ctx, rootScope, stdlibLoc, cache); err != nil {
+		err = fmt.Errorf("failed to load the standard library prelude: %w", err)
+		fmt.Fprintf(os.Stderr, "buresu repl: %s\n", err.Error())
+		return err
+	}
 
-	// 9. arrange for buffer and prompt reset
-	buffer := ""
-	prompt := ">>> "
-	resetBufferAndPrompt := func() {
-		buffer = ""
-		prompt = ">>> "
+	// 10. arrange for reading the input lazily and for resetting it after
+	// errors, where we name each input differently such that diagnostics
+	// can refer to previous inputs, whose source code we must register
+	input := &lineReader{rl: rl}
+	inputs := 0
+	var forms *parser.Parser
+	resetInput := func() {
+		if inputs > 0 {
+			renderer.AddSource(input.fileName, input.source.String())
+		}
+		inputs++
+		input.reset(fmt.Sprintf("<stdin:%d>", inputs))
+		forms = parser.New(scanner.New(input.fileName, input))
+	}
+	resetInput()
+	report := func(err error) {
+		renderer.AddSource(input.fileName, input.source.String())
+		renderer.Render(os.Stderr, diagnostics.FromError(err))
 	}
 
-	// 10. start the REPL loop
+	// 11. start the REPL loop, where we parse, include and evaluate one
+	// top-level form at a time, reading new lines only when needed
 	for {
-		rl.SetPrompt(prompt)
-		line, err := rl.Readline()
-		if err != nil {
-			if errors.Is(err, io.EOF) {
-				return err
-			}
-			if errors.Is(err, readline.ErrInterrupt) {
-				resetBufferAndPrompt()
-				continue
-			}
-			fmt.Fprintf(os.Stderr, "error reading input: %s\n", err.Error())
+		input.prompt = ">>> "
+		node, err := forms.ParseNext()
+		switch {
+		case errors.Is(err, io.EOF) || input.eof:
+			return io.EOF
+
+		case errors.Is(err, readline.ErrInterrupt):
+			resetInput()
+			continue
+
+		case err != nil:
+			report(err)
+			resetInput()
 			continue
 		}
 
-		buffer += line
-		tokens, err := scanner.Scan("<stdin>", strings.NewReader(buffer))
+		nodes, err := includer.IncludeWithCache(cache, searchPath, []ast.Node{node})
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "error scanning input: %s\n", err.Error())
-			resetBufferAndPrompt()
+			report(err)
+			resetInput()
 			continue
 		}
 
-		nodes, err := parser.Parse(tokens)
-		if err != nil {
-			if parser.IsErrIncompleteInput(err) {
-				prompt = "... "
-				continue
-			}
-			fmt.Fprintf(os.Stderr, "syntax error: %s\n", err.Error())
-			resetBufferAndPrompt()
+		if err := evaluate(rootScope, tcEnv, nodes, enabledFeatures); err != nil {
+			report(err)
+			resetInput()
 			continue
 		}
+	}
+}
+
+// lineReader is an [io.Reader] reading the input one line at a time
+// using readline, which allows the scanner to read the input lazily.
+type lineReader struct {
+	// rl is the readline instance.
+	rl *readline.Instance
+
+	// eof indicates that the user closed the input.
+	eof bool
+
+	// fileName is the file name of the current input.
+	fileName string
+
+	// pending contains the part of the current line we did not return yet.
+	pending string
 
-		nodes, err = includer.Include(".", nodes)
+	// prompt is the prompt to show when reading the next line.
+	prompt string
+
+	// source contains the source code of the current input.
+	source strings.Builder
+}
+
+// reset discards the current input and starts a new one with the given file name.
+func (r *lineReader) reset(fileName string) {
+	r.fileName = fileName
+	r.pending = ""
+	r.source.Reset()
+}
+
+// Read implements [io.Reader].
+func (r *lineReader) Read(data []byte) (int, error) {
+	for r.pending == "" {
+		r.rl.SetPrompt(r.prompt)
+		line, err := r.rl.Readline()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "include error: %s\n", err.Error())
-			resetBufferAndPrompt()
-			continue
+			r.eof = errors.Is(err, io.EOF)
+			return 0, err
 		}
+		r.pending = line + "\n"
+		r.source.WriteString(r.pending)
 
-		evaluate(rootScope, tcEnv, nodes, enabledFeatures)
-		resetBufferAndPrompt()
+		// once we read some code, we are in the middle of a form until
+		// we finish parsing it and start reading the next form
+		if strings.TrimSpace(line) != "" {
+			r.prompt = "... "
+		}
 	}
+	count := copy(data, r.pending)
+	r.pending = r.pending[count:]
+	return count, nil
 }
 
 func evaluate(
 	rootScope *evaluator.Environment,
 	tcEnv *typechecker.Environment,
 	nodes []ast.Node,
-	enabledFeatures map[string]struct{}) {
+	enabledFeatures map[string]struct{}) error {
 	// 1. create cancellable context for interrupt evaluation
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -185,17 +268,16 @@ func evaluate(
 	for _, node := range nodes {
 		if _, typecheckerEnabled := enabledFeatures["typechecker"]; typecheckerEnabled {
 			if _, err := typechecker.Check(ctx, tcEnv, node); err != nil {
-				fmt.Fprintf(os.Stderr, "typechecking error: %s\n", err.Error())
-				return
+				return err
 			}
 		}
 
 		value, err := evaluator.Eval(ctx, rootScope, node)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "evaluation error: %s\n", err.Error())
-			return
+			return err
 		}
 
 		fmt.Printf("%s\n", value.String())
 	}
+	return nil
 }
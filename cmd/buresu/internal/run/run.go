@@ -7,12 +7,18 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/bassosimone/buresu/cmd/internal/cliutils"
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/bundle"
+	"github.com/bassosimone/buresu/pkg/diagnostics"
 	"github.com/bassosimone/buresu/pkg/dumper"
 	"github.com/bassosimone/buresu/pkg/evaluator"
 	"github.com/bassosimone/buresu/pkg/includer"
+	"github.com/bassosimone/buresu/pkg/langspec"
 	"github.com/bassosimone/buresu/pkg/parser"
 	"github.com/bassosimone/buresu/pkg/scanner"
 	"github.com/bassosimone/buresu/pkg/typechecker"
@@ -52,8 +58,20 @@ func (cmd command) Main(ctx context.Context, argv ...string) error {
 	// 3. add options to the parser
 	var emit string
 	var features []string
-	clip.StringVarP(&emit, "emit", "E", "", "Emit specific output (tokens, ast)")
+	clip.StringVarP(&emit, "emit", "E", "", "Emit specific output (tokens, tokens_with_trivia, ast)")
 	clip.StringArrayVarP(&features, "feature", "X", []string{}, "Enable experimental features (e.g., typechecker)")
+	var includeDirs []string
+	clip.StringArrayVarP(&includeDirs, "include-dir", "I", []string{}, "Add directory to the include search path")
+	var stdlibDir string
+	clip.StringVar(&stdlibDir, "stdlib-dir", "", "Read the standard library from directory instead of using the embedded one")
+	var cacheDir string
+	clip.StringVar(&cacheDir, "cache-dir", "", "Cache the parsed included files inside directory")
+	var noCache bool
+	clip.BoolVar(&noCache, "no-cache", false, "Do not cache the parsed included files")
+	var diagnosticsFormat string
+	clip.StringVar(&diagnosticsFormat, "diagnostics", "auto", "Format of the diagnostics: auto, text, color, or json")
+	var langVersion string
+	clip.StringVar(&langVersion, "lang-version", "latest", "Parse the program using the given language version")
 
 	// 4. parse the command line
 	if err := clip.Parse(argv[1:]); err != nil {
@@ -79,64 +97,154 @@ func (cmd command) Main(ctx context.Context, argv ...string) error {
 	}
 	scriptFile := args[0]
 
-	// 6. create a map of enabled features
-	enabledFeatures := make(map[string]struct{})
-	for _, feature := range features {
-		enabledFeatures[feature] = struct{}{}
-	}
-
-	// 7. scan the script to produce tokens
-	filep, err := os.Open(scriptFile)
+	// 6. create the diagnostics renderer and select the language version
+	format, err := diagnostics.ParseFormat(diagnosticsFormat, os.Stderr)
 	if err != nil {
-		err := fmt.Errorf("buresu: cannot open script: %s", err.Error())
 		fmt.Fprintf(os.Stderr, "buresu run: %s\n", err.Error())
+		fmt.Fprintf(os.Stderr, "Run `buresu run --help` for usage.\n")
 		return err
 	}
-	defer filep.Close()
-	tokens, err := scanner.Scan(scriptFile, filep)
+	version, err := langspec.ParseVersion(langVersion)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "buresu run: %s\n", err.Error())
-		return err // already wrapped
+		fmt.Fprintf(os.Stderr, "Run `buresu run --help` for usage.\n")
+		return err
 	}
-	if emit == "tokens" {
-		return dumper.DumpTokens(os.Stdout, tokens)
+	renderer := diagnostics.NewRenderer(format)
+	report := func(err error) error {
+		renderer.Render(os.Stderr, diagnostics.FromError(err))
+		return err
 	}
 
-	// 8. parse the tokens to produce an AST
-	nodes, err := parser.Parse(tokens)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "buresu run: %s\n", err.Error())
-		return err // already wrapped
-	}
-	if emit == "ast" {
-		return dumper.DumpAST(os.Stdout, nodes)
+	// 7. create a map of enabled features, the include search path and the cache
+	enabledFeatures := make(map[string]struct{})
+	for _, feature := range features {
+		enabledFeatures[feature] = struct{}{}
 	}
+	stdlibLoc := cliutils.StdlibLocation(stdlibDir)
+	searchPath := includer.NewSearchPath(includeDirs, stdlibLoc)
+	cache := cliutils.NewDiskCache(cacheDir, noCache)
 
-	// 9. service requests to include other files
-	nodes, err = includer.Include(".", nodes)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "buresu run: %s\n", err.Error())
-		return err // already wrapped
-	}
-	if emit == "ast_after_include" {
-		return dumper.DumpAST(os.Stdout, nodes)
+	// 8. open the program, which, for bundles, includes the entry point
+	var forms formReader
+	if bundle.IsBundle(scriptFile) {
+		b, err := bundle.Open(scriptFile)
+		if err != nil {
+			err := fmt.Errorf("buresu: cannot open bundle: %s", err.Error())
+			fmt.Fprintf(os.Stderr, "buresu run: %s\n", err.Error())
+			return err
+		}
+		defer b.Close()
+		searchPath = append([]includer.Location{b.Location}, searchPath...)
+		if emit == "tokens" || emit == "tokens_with_trivia" {
+			err := errors.New("cannot emit tokens for a bundle")
+			fmt.Fprintf(os.Stderr, "buresu run: %s\n", err.Error())
+			return err
+		}
+		if emit == "ast" {
+			return dumper.DumpAST(os.Stdout, b.Nodes())
+		}
+		forms = &nodesReader{b.Nodes()}
+	} else {
+		fileName, filep, err := openScript(scriptFile)
+		if err != nil {
+			err := fmt.Errorf("buresu: cannot open script: %s", err.Error())
+			fmt.Fprintf(os.Stderr, "buresu run: %s\n", err.Error())
+			return err
+		}
+		defer filep.Close()
+		if fileName == stdinFileName {
+			// the renderer cannot read the standard input again
+			// hence we need to retain the source code we read
+			var source strings.Builder
+			filep = io.NopCloser(io.TeeReader(filep, &source))
+			report = func(err error) error {
+				renderer.AddSource(fileName, source.String())
+				renderer.Render(os.Stderr, diagnostics.FromError(err))
+				return err
+			}
+		}
+		if emit == "tokens" || emit == "tokens_with_trivia" || emit == "ast" {
+			return cmd.emitScript(fileName, filep, emit, version, report)
+		}
+		forms = parser.NewWithVersion(scanner.New(fileName, filep), version)
 	}
 
-	// 10. create the runtime environment
+	// 9. create the runtime environment, stopping the generators that the
+	// program did not exhaust when we are done
 	rootScope := evaluator.NewGlobalEnvironment(os.Stdout)
-	tcEnv, err := typechecker.NewGlobalEnvironment(ctx, ".")
+	defer rootScope.StopGenerators()
+	tcEnv, err := typechecker.NewGlobalEnvironment(ctx, stdlibLoc, cache)
 	if err != nil {
 		err = fmt.Errorf("failed to load the standard library runtime: %w", err)
 		fmt.Fprintf(os.Stderr, "buresu run: %s\n", err.Error())
 		return err
 	}
+	if err := evaluator.LoadPrelude(ctx, rootScope, stdlibLoc, cache); err != nil {
+		err = fmt.Errorf("failed to load the standard library prelude: %w", err)
+		fmt.Fprintf(os.Stderr, "buresu run: %s\n", err.Error())
+		return err
+	}
+
+	// 10. read, include, typecheck and evaluate the program. The typechecker
+	// must see the whole program before we evaluate it, such that a type error
+	// prevents running any form. Without the typechecker, we process the program
+	// one top-level form at a time, such that we can run huge or piped programs
+	// without reading them completely. We do the same for files and the standard
+	// input, such that a program behaves in the same way regardless of its source.
+	for _, loc := range searchPath {
+		renderer.AddLocation(loc)
+	}
+	_, typecheck := enabledFeatures["typechecker"]
+	streaming := !typecheck
+	inc := includer.NewWithVersion(cache, searchPath, version)
+	var program []ast.Node
+	for {
+		// 10.1. parse the next form
+		form, err := forms.ParseNext()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return report(err)
+		}
+
+		// 10.2. service requests to include other files
+		nodes, err := inc.Include([]ast.Node{form})
+		if err != nil {
+			return report(err)
+		}
+		if !streaming || emit == "ast_after_include" {
+			program = append(program, nodes...)
+			continue
+		}
+
+		// 10.3. typecheck and evaluate the form we have just read
+		if err := cmd.execute(ctx, tcEnv, rootScope, nodes, emit, enabledFeatures); err != nil {
+			return report(err)
+		}
+	}
+	if emit == "ast_after_include" {
+		return dumper.DumpAST(os.Stdout, program)
+	}
 
-	// 11. potentially typecheck
+	// 11. typecheck and evaluate the whole program we have read
+	if err := cmd.execute(ctx, tcEnv, rootScope, program, emit, enabledFeatures); err != nil {
+		return report(err)
+	}
+	return nil
+}
+
+// execute typechecks the given nodes, if the typechecker is enabled, and then
+// evaluates them. We typecheck all the nodes before evaluating any of them.
+func (cmd command) execute(ctx context.Context, tcEnv *typechecker.Environment,
+	rootScope *evaluator.Environment, nodes []ast.Node, emit string,
+	enabledFeatures map[string]struct{}) error {
+	// 1. potentially typecheck
 	if _, ok := enabledFeatures["typechecker"]; ok {
 		for _, node := range nodes {
 			kind, err := typechecker.Check(ctx, tcEnv, node)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "buresu run: %s\n", err.Error())
 				return err
 			}
 			if emit == "typechecker" {
@@ -149,12 +257,73 @@ func (cmd command) Main(ctx context.Context, argv ...string) error {
 		}
 	}
 
-	// 12. evaluate the script
+	// 2. evaluate
 	for _, node := range nodes {
 		if _, err := evaluator.Eval(ctx, rootScope, node); err != nil {
-			fmt.Fprintf(os.Stderr, "buresu run: %s\n", err.Error())
-			return err // already wrapped
+			return err
 		}
 	}
 	return nil
 }
+
+// stdinFileName is the file name we use when reading the standard input.
+const stdinFileName = "<stdin>"
+
+// openScript opens the given script, where `-` means the standard
+// input, and returns the file name to use for diagnostics.
+func openScript(scriptFile string) (string, io.ReadCloser, error) {
+	if scriptFile == "-" {
+		return stdinFileName, io.NopCloser(os.Stdin), nil
+	}
+	filep, err := os.Open(scriptFile)
+	if err != nil {
+		return "", nil, err
+	}
+	return scriptFile, filep, nil
+}
+
+// emitScript scans and parses the given script and emits the tokens or
+// the AST. We use the given report function to print scanner and parser errors.
+func (cmd command) emitScript(fileName string, filep io.Reader, emit string,
+	version langspec.Version, report func(error) error) error {
+	// 1. scan the script to produce tokens
+	scan := scanner.Scan
+	if emit == "tokens_with_trivia" {
+		scan = scanner.ScanWithTrivia
+	}
+	tokens, err := scan(fileName, filep)
+	if err != nil {
+		return report(err)
+	}
+	if emit == "tokens" || emit == "tokens_with_trivia" {
+		return dumper.DumpTokens(os.Stdout, tokens)
+	}
+
+	// 2. parse the tokens to produce an AST
+	nodes, err := parser.ParseWithVersion(tokens, version)
+	if err != nil {
+		return report(err)
+	}
+	return dumper.DumpAST(os.Stdout, nodes)
+}
+
+// formReader reads the top-level forms of a program one at a time.
+type formReader interface {
+	// ParseNext returns the next form or [io.EOF].
+	ParseNext() (ast.Node, error)
+}
+
+// nodesReader is a [formReader] reading the forms from a slice.
+type nodesReader struct {
+	nodes []ast.Node
+}
+
+// ParseNext implements [formReader].
+func (r *nodesReader) ParseNext() (ast.Node, error) {
+	if len(r.nodes) <= 0 {
+		return nil, io.EOF
+	}
+	node := r.nodes[0]
+	r.nodes = r.nodes[1:]
+	return node, nil
+}
@@ -7,6 +7,9 @@ import (
 	_ "embed"
 	"os"
 
+	"github.com/bassosimone/buresu/cmd/buresu/internal/format"
+	"github.com/bassosimone/buresu/cmd/buresu/internal/lint"
+	"github.com/bassosimone/buresu/cmd/buresu/internal/lsp"
 	"github.com/bassosimone/buresu/cmd/buresu/internal/repl"
 	"github.com/bassosimone/buresu/cmd/buresu/internal/run"
 	"github.com/bassosimone/buresu/cmd/internal/climain"
@@ -25,6 +28,9 @@ var readme string
 // newCommand constructs a new [cliutils.Command] for the `buresu` command.
 func newCommand() cliutils.Command {
 	return cliutils.NewCommandWithSubCommands("buresu", readme, map[string]cliutils.Command{
+		"fmt":  format.NewCommand(),
+		"lint": lint.NewCommand(),
+		"lsp":  lsp.NewCommand(),
 		"repl": repl.NewCommand(),
 		"run":  run.NewCommand(),
 	})
@@ -0,0 +1,29 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package cliutils
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/bassosimone/buresu/pkg/includer"
+)
+
+// NewDiskCache returns the [*includer.Cache] using the given directory or, if
+// the directory is empty, the `buresu` directory inside the user cache directory.
+//
+// We return a nil cache, which disables caching, if we cannot determine the
+// user cache directory or the cache has been explicitly disabled.
+func NewDiskCache(cacheDir string, disabled bool) *includer.Cache {
+	if disabled {
+		return nil
+	}
+	if cacheDir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return nil
+		}
+		cacheDir = filepath.Join(userCacheDir, "buresu")
+	}
+	return includer.NewDiskCache(cacheDir)
+}
@@ -0,0 +1,18 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package cliutils
+
+import (
+	"github.com/bassosimone/buresu/pkg/includer"
+	"github.com/bassosimone/buresu/stdlib"
+)
+
+// StdlibLocation returns the location of the standard library, which is
+// the one embedded into the binary unless we are given a directory from
+// which to read it instead (e.g., when developing the standard library).
+func StdlibLocation(stdlibDir string) includer.Location {
+	if stdlibDir != "" {
+		return includer.DirLocation(stdlibDir)
+	}
+	return stdlib.Location()
+}
@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package textdiff computes line-based unified diffs.
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines around each change.
+const contextLines = 3
+
+// opKind is the kind of an edit operation.
+type opKind byte
+
+const (
+	opEqual  = opKind(' ')
+	opDelete = opKind('-')
+	opInsert = opKind('+')
+)
+
+// op is an edit operation transforming the old lines into the new lines.
+type op struct {
+	kind opKind
+	line string
+
+	// oldIdx and newIdx are the zero-based indexes of the
+	// line in the old and new lines before applying the op.
+	oldIdx, newIdx int
+}
+
+// Unified returns the unified diff between the old and the new text using
+// the given file names in the header, or an empty string if they are equal.
+func Unified(oldName, newName, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+	for start := 0; start < len(ops); {
+		// skip to the next change, if any
+		for start < len(ops) && ops[start].kind == opEqual {
+			start++
+		}
+		if start >= len(ops) {
+			break
+		}
+
+		// extend the hunk until we find enough unchanged lines
+		first := max(start-contextLines, 0)
+		end, equal := start, 0
+		for end < len(ops) && equal <= 2*contextLines {
+			if ops[end].kind == opEqual {
+				equal++
+			} else {
+				equal = 0
+			}
+			end++
+		}
+		last := end - max(equal-contextLines, 0)
+
+		writeHunk(&out, ops[first:last])
+		start = last
+	}
+	return out.String()
+}
+
+// writeHunk writes a hunk containing the given operations.
+func writeHunk(out *strings.Builder, ops []op) {
+	var oldCount, newCount int
+	for _, o := range ops {
+		if o.kind != opInsert {
+			oldCount++
+		}
+		if o.kind != opDelete {
+			newCount++
+		}
+	}
+	fmt.Fprintf(out, "@@ -%s +%s @@\n",
+		hunkRange(ops[0].oldIdx, oldCount), hunkRange(ops[0].newIdx, newCount))
+	for _, o := range ops {
+		fmt.Fprintf(out, "%c%s\n", o.kind, o.line)
+	}
+}
+
+// hunkRange formats the range of a hunk header given the zero-based
+// index of the first line and the number of lines.
+func hunkRange(idx, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", idx) // by convention, the line before the hunk
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", idx+1)
+	}
+	return fmt.Sprintf("%d,%d", idx+1, count)
+}
+
+// splitLines splits the text into lines without the final newline.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines computes the edit operations using the longest common subsequence.
+func diffLines(oldLines, newLines []string) []op {
+	// lcs[i][j] is the length of the LCS of oldLines[i:] and newLines[j:]
+	lcs := make([][]int, len(oldLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []op
+	i, j := 0, 0
+	for i < len(oldLines) || j < len(newLines) {
+		switch {
+		case i < len(oldLines) && j < len(newLines) && oldLines[i] == newLines[j]:
+			ops = append(ops, op{kind: opEqual, line: oldLines[i], oldIdx: i, newIdx: j})
+			i, j = i+1, j+1
+
+		case j >= len(newLines) || (i < len(oldLines) && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, op{kind: opDelete, line: oldLines[i], oldIdx: i, newIdx: j})
+			i++
+
+		default:
+			ops = append(ops, op{kind: opInsert, line: newLines[j], oldIdx: i, newIdx: j})
+			j++
+		}
+	}
+	return ops
+}
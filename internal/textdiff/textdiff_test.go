@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package textdiff
+
+import "testing"
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldText  string
+		newText  string
+		expected string
+	}{{
+		name:     "equal texts",
+		oldText:  "a\nb\n",
+		newText:  "a\nb\n",
+		expected: "",
+	}, {
+		name:     "single change",
+		oldText:  "a\nb\nc\n",
+		newText:  "a\nx\nc\n",
+		expected: "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n",
+	}, {
+		name:     "insertion into empty text",
+		oldText:  "",
+		newText:  "a\n",
+		expected: "--- old\n+++ new\n@@ -0,0 +1 @@\n+a\n",
+	}, {
+		name:    "distant changes use distinct hunks",
+		oldText: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+		newText: "x\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ny\n",
+		expected: "--- old\n+++ new\n" +
+			"@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n 4\n" +
+			"@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+y\n",
+	}, {
+		name:     "close changes share the hunk",
+		oldText:  "1\n2\n3\n4\n5\n",
+		newText:  "x\n2\n3\n4\ny\n",
+		expected: "--- old\n+++ new\n@@ -1,5 +1,5 @@\n-1\n+x\n 2\n 3\n 4\n-5\n+y\n",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Unified("old", "new", tt.oldText, tt.newText)
+			if got != tt.expected {
+				t.Fatalf("expected:\n%s\ngot:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
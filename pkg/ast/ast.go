@@ -5,6 +5,9 @@
 // The parser package generates AST nodes from a sequence of tokens.
 //
 // Packages like the intepreter will then traverse and manipulate the AST.
+//
+// Each node contains the Token starting the node source code and the End
+// position right after its last token, which together define the node span.
 package ast
 
 import (
@@ -12,6 +15,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"unicode"
 
 	"github.com/bassosimone/buresu/pkg/token"
 )
@@ -22,9 +26,167 @@ type Node interface {
 	String() string
 }
 
+// NodeToken returns the token of the given node, which is the first token
+// of the node source code (e.g., the OPEN token of the forms).
+func NodeToken(node Node) token.Token {
+	switch node := node.(type) {
+	case *BlockExpr:
+		return node.Token
+	case *BreakStmt:
+		return node.Token
+	case *CallExpr:
+		return node.Token
+	case *CharLiteral:
+		return node.Token
+	case *CondExpr:
+		return node.Token
+	case *ContinueStmt:
+		return node.Token
+	case *DeclareExpr:
+		return node.Token
+	case *DefineExpr:
+		return node.Token
+	case *EllipsisLiteral:
+		return node.Token
+	case *ErrorExpr:
+		return node.Token
+	case *FalseLiteral:
+		return node.Token
+	case *FloatLiteral:
+		return node.Token
+	case *ForExpr:
+		return node.Token
+	case *ForeachExpr:
+		return node.Token
+	case *ImportStmt:
+		return node.Token
+	case *IncludeStmt:
+		return node.Token
+	case *IntLiteral:
+		return node.Token
+	case *KeywordLiteral:
+		return node.Token
+	case *LambdaExpr:
+		return node.Token
+	case *LetExpr:
+		return node.Token
+	case *LetStarExpr:
+		return node.Token
+	case *LetrecExpr:
+		return node.Token
+	case *ModuleStmt:
+		return node.Token
+	case *QuasiquoteExpr:
+		return node.Token
+	case *QuoteExpr:
+		return node.Token
+	case *RationalLiteral:
+		return node.Token
+	case *ReturnStmt:
+		return node.Token
+	case *SetExpr:
+		return node.Token
+	case *StringLiteral:
+		return node.Token
+	case *SymbolName:
+		return node.Token
+	case *TrueLiteral:
+		return node.Token
+	case *UnitExpr:
+		return node.Token
+	case *UnquoteExpr:
+		return node.Token
+	case *WhileExpr:
+		return node.Token
+	case *YieldStmt:
+		return node.Token
+	default:
+		return token.Token{}
+	}
+}
+
+// NodeSpan returns the source code span of the given node.
+func NodeSpan(node Node) token.Span {
+	switch node := node.(type) {
+	case *BlockExpr:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *BreakStmt:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *CallExpr:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *CharLiteral:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *CondExpr:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *ContinueStmt:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *DeclareExpr:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *DefineExpr:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *EllipsisLiteral:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *ErrorExpr:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *FalseLiteral:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *FloatLiteral:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *ForExpr:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *ForeachExpr:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *ImportStmt:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *IncludeStmt:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *IntLiteral:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *KeywordLiteral:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *LambdaExpr:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *LetExpr:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *LetStarExpr:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *LetrecExpr:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *ModuleStmt:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *QuasiquoteExpr:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *QuoteExpr:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *RationalLiteral:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *ReturnStmt:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *SetExpr:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *StringLiteral:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *SymbolName:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *TrueLiteral:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *UnitExpr:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *UnquoteExpr:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *WhileExpr:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	case *YieldStmt:
+		return token.Span{Start: node.Token.TokenPos, End: node.End}
+	default:
+		return token.Span{}
+	}
+}
+
 // BlockExpr represents a block of expressions executed sequentially.
 type BlockExpr struct {
 	Token token.Token
+	End   token.Position
 	Exprs []Node
 }
 
@@ -37,10 +199,23 @@ func (blk *BlockExpr) String() string {
 	return fmt.Sprintf("(block %s)", strings.Join(exprs, " "))
 }
 
+// BreakStmt represents a break statement to interrupt
+// the innermost enclosing while loop.
+type BreakStmt struct {
+	Token token.Token
+	End   token.Position
+}
+
+// String converts the BreakStmt node back to lisp source code.
+func (brk *BreakStmt) String() string {
+	return "(break!)"
+}
+
 // CallExpr represents a call to a given callable with a list
 // of arguments and a return type.
 type CallExpr struct {
 	Token    token.Token
+	End      token.Position
 	Callable Node
 	Args     []Node
 }
@@ -58,6 +233,29 @@ func (call *CallExpr) String() string {
 	)
 }
 
+// CharLiteral represents a character value (e.g., `#\a`).
+type CharLiteral struct {
+	Token token.Token
+	End   token.Position
+	Value rune
+}
+
+// String converts the CharLiteral node back to lisp source code.
+func (chrLit *CharLiteral) String() string {
+	if chrLit.Token.Source != "" {
+		return chrLit.Token.Source
+	}
+	for name, value := range token.CharNames {
+		if value == chrLit.Value {
+			return `#\` + name
+		}
+	}
+	if !unicode.IsPrint(chrLit.Value) {
+		return fmt.Sprintf(`#\u{%X}`, chrLit.Value)
+	}
+	return `#\` + string(chrLit.Value)
+}
+
 // CondCase represents a single case in a conditional expression.
 type CondCase struct {
 	Predicate Node
@@ -67,6 +265,7 @@ type CondCase struct {
 // CondExpr represents a conditional expression with multiple branches.
 type CondExpr struct {
 	Token    token.Token
+	End      token.Position
 	Cases    []CondCase
 	ElseExpr Node
 }
@@ -81,9 +280,22 @@ func (cond *CondExpr) String() string {
 	return fmt.Sprintf("(cond %s%s)", strings.Join(cases, " "), elseExpr)
 }
 
+// ContinueStmt represents a continue statement to skip to the
+// next iteration of the innermost enclosing while loop.
+type ContinueStmt struct {
+	Token token.Token
+	End   token.Position
+}
+
+// String converts the ContinueStmt node back to lisp source code.
+func (cont *ContinueStmt) String() string {
+	return "(continue!)"
+}
+
 // DeclareExpr declares a value in a variable within the current scope.
 type DeclareExpr struct {
 	Token  token.Token
+	End    token.Position
 	Symbol string
 	Expr   Node
 }
@@ -96,6 +308,7 @@ func (decl *DeclareExpr) String() string {
 // DefineExpr saves a value in a variable within the current scope.
 type DefineExpr struct {
 	Token  token.Token
+	End    token.Position
 	Symbol string
 	Expr   Node
 }
@@ -110,6 +323,7 @@ func (def *DefineExpr) String() string {
 // We use `...` to represent unspecified lambda bodies.
 type EllipsisLiteral struct {
 	Token token.Token
+	End   token.Position
 }
 
 // String converts the Ellipsis node back to lisp source code.
@@ -117,9 +331,24 @@ func (ell *EllipsisLiteral) String() string {
 	return "..."
 }
 
+// ErrorExpr represents a top-level form we could not parse, which
+// only appears in the partial AST returned when parsing with error
+// recovery. The Err field contains the corresponding parsing error.
+type ErrorExpr struct {
+	Token token.Token
+	End   token.Position
+	Err   error
+}
+
+// String converts the ErrorExpr node back to lisp source code.
+func (bad *ErrorExpr) String() string {
+	return "<error>"
+}
+
 // FalseLiteral represents a boolean false value.
 type FalseLiteral struct {
 	Token token.Token
+	End   token.Position
 }
 
 // String converts the FalseLiteral node back to lisp source code.
@@ -130,6 +359,7 @@ func (fal *FalseLiteral) String() string {
 // FloatLiteral represents a floating-point value.
 type FloatLiteral struct {
 	Token token.Token
+	End   token.Position
 	Value string
 }
 
@@ -138,9 +368,41 @@ func (fltLit *FloatLiteral) String() string {
 	return fltLit.Value
 }
 
+// ForExpr represents a loop binding the symbol, in a new block scope
+// for each iteration, to the integers from From (included) to To (excluded).
+type ForExpr struct {
+	Token  token.Token
+	End    token.Position
+	Symbol string
+	From   Node
+	To     Node
+	Expr   Node
+}
+
+// String converts the ForExpr node back to lisp source code.
+func (loop *ForExpr) String() string {
+	return fmt.Sprintf("(for (%s %s %s) %s)", loop.Symbol, loop.From.String(), loop.To.String(), loop.Expr.String())
+}
+
+// ForeachExpr represents a loop binding the symbol, in a new block
+// scope for each iteration, to the elements of the Seq sequence.
+type ForeachExpr struct {
+	Token  token.Token
+	End    token.Position
+	Symbol string
+	Seq    Node
+	Expr   Node
+}
+
+// String converts the ForeachExpr node back to lisp source code.
+func (loop *ForeachExpr) String() string {
+	return fmt.Sprintf("(foreach (%s %s) %s)", loop.Symbol, loop.Seq.String(), loop.Expr.String())
+}
+
 // IncludeStmt represents an include expression with a file path.
 type IncludeStmt struct {
 	Token    token.Token
+	End      token.Position
 	FilePath string
 }
 
@@ -149,9 +411,30 @@ func (inc *IncludeStmt) String() string {
 	return fmt.Sprintf("(include! %s)", jsonMarshalWithoutEscaping(inc.FilePath))
 }
 
+// ImportStmt represents an import statement, which binds the exports of
+// the module defined in the given file path as `<alias>/<name>`.
+type ImportStmt struct {
+	Token    token.Token
+	End      token.Position
+	FilePath string
+	Alias    string
+
+	// Nodes contains the nodes of the imported module, which the includer
+	// loads, and is empty until the includer has processed this statement.
+	//
+	// Imports of the same file share the same Nodes slice.
+	Nodes []Node
+}
+
+// String converts the ImportStmt node back to lisp source code.
+func (imp *ImportStmt) String() string {
+	return fmt.Sprintf("(import %s as %s)", jsonMarshalWithoutEscaping(imp.FilePath), imp.Alias)
+}
+
 // IntLiteral represents an integer value.
 type IntLiteral struct {
 	Token token.Token
+	End   token.Position
 	Value string
 }
 
@@ -160,9 +443,23 @@ func (intLit *IntLiteral) String() string {
 	return intLit.Value
 }
 
+// KeywordLiteral represents a self-evaluating keyword (e.g., `:name`),
+// whose Value includes the leading colon.
+type KeywordLiteral struct {
+	Token token.Token
+	End   token.Position
+	Value string
+}
+
+// String converts the KeywordLiteral node back to lisp source code.
+func (kwLit *KeywordLiteral) String() string {
+	return kwLit.Value
+}
+
 // LambdaExpr represents an inline function definition with docs.
 type LambdaExpr struct {
 	Token  token.Token
+	End    token.Position
 	Params []string
 	Docs   string
 	Expr   Node
@@ -186,21 +483,125 @@ func jsonMarshalWithoutEscaping(v string) string {
 	return strings.TrimSpace(buff.String())
 }
 
+// LetBinding represents a single symbol binding in a let, let* or letrec form.
+type LetBinding struct {
+	Symbol string
+	Expr   Node
+}
+
+// letBindingsString converts the bindings back to lisp source code.
+func letBindingsString(bindings []LetBinding) string {
+	parts := make([]string, len(bindings))
+	for idx, binding := range bindings {
+		parts[idx] = fmt.Sprintf("(%s %s)", binding.Symbol, binding.Expr.String())
+	}
+	return fmt.Sprintf("(%s)", strings.Join(parts, " "))
+}
+
+// LetExpr binds symbols in a new scope and evaluates an expression in it.
+//
+// All the binding expressions are evaluated in the enclosing scope.
+type LetExpr struct {
+	Token    token.Token
+	End      token.Position
+	Bindings []LetBinding
+	Expr     Node
+}
+
+// String converts the LetExpr node back to lisp source code.
+func (let *LetExpr) String() string {
+	return fmt.Sprintf("(let %s %s)", letBindingsString(let.Bindings), let.Expr.String())
+}
+
+// LetStarExpr is like LetExpr except that each binding expression is
+// evaluated in a scope where the previous bindings are visible.
+type LetStarExpr struct {
+	Token    token.Token
+	End      token.Position
+	Bindings []LetBinding
+	Expr     Node
+}
+
+// String converts the LetStarExpr node back to lisp source code.
+func (let *LetStarExpr) String() string {
+	return fmt.Sprintf("(let* %s %s)", letBindingsString(let.Bindings), let.Expr.String())
+}
+
+// LetrecExpr is like LetExpr except that all the binding expressions are
+// evaluated in the new scope, which allows mutually recursive lambdas.
+type LetrecExpr struct {
+	Token    token.Token
+	End      token.Position
+	Bindings []LetBinding
+	Expr     Node
+}
+
+// String converts the LetrecExpr node back to lisp source code.
+func (let *LetrecExpr) String() string {
+	return fmt.Sprintf("(letrec %s %s)", letBindingsString(let.Bindings), let.Expr.String())
+}
+
+// ModuleStmt declares that the current file is a module with the
+// given name, exporting the given symbols to the importers.
+type ModuleStmt struct {
+	Token   token.Token
+	End     token.Position
+	Name    string
+	Exports []string
+}
+
+// String converts the ModuleStmt node back to lisp source code.
+func (mod *ModuleStmt) String() string {
+	return fmt.Sprintf("(module %s (export %s))", mod.Name, strings.Join(mod.Exports, " "))
+}
+
+// QuasiquoteExpr represents a quasiquoted expression (e.g., "`(a ,b)"), which
+// is a quoted expression in which we evaluate the unquoted expressions.
+type QuasiquoteExpr struct {
+	Token token.Token
+	End   token.Position
+	Expr  Node
+}
+
+// String converts the QuasiquoteExpr node back to lisp source code.
+func (qq *QuasiquoteExpr) String() string {
+	return "`" + qq.Expr.String()
+}
+
 // QuoteExpr represents a quoted expression.
 type QuoteExpr struct {
 	Token token.Token
+	End   token.Position
 	Expr  Node
 }
 
 // String converts the QuoteExpr node back to lisp source code.
+//
+// We use the `'x` shorthand when the source code uses it.
 func (quote *QuoteExpr) String() string {
+	if quote.Token.TokenType == token.QUOTE {
+		return "'" + quote.Expr.String()
+	}
 	return fmt.Sprintf("(quote %s)", quote.Expr.String())
 }
 
+// RationalLiteral represents a rational value (e.g., `1/3`).
+type RationalLiteral struct {
+	Token token.Token
+	End   token.Position
+	Value string
+}
+
+// String converts the RationalLiteral node back to lisp source code.
+func (ratLit *RationalLiteral) String() string {
+	return ratLit.Value
+}
+
 // ReturnStmt represents a return statement to interrupt
 // the current function and return a value.
 type ReturnStmt struct {
 	Token token.Token
+	End   token.Position
 	Expr  Node
 }
 
@@ -213,6 +614,7 @@ func (ret *ReturnStmt) String() string {
 // be in the current scope or in a parent scope.
 type SetExpr struct {
 	Token  token.Token
+	End    token.Position
 	Symbol string
 	Expr   Node
 }
@@ -222,20 +624,28 @@ func (set *SetExpr) String() string {
 	return fmt.Sprintf("(set! %s %s)", set.Symbol, set.Expr.String())
 }
 
-// StringLiteral represents a string value containing ASCII text.
+// StringLiteral represents a string value containing Unicode text.
 type StringLiteral struct {
 	Token token.Token
+	End   token.Position
 	Value string
 }
 
 // String converts the StringLiteral node back to lisp source code.
+//
+// We use the literal as written in the source code, if available, such
+// that we preserve raw and multiline strings and escape sequences.
 func (strLit *StringLiteral) String() string {
+	if strLit.Token.Source != "" {
+		return strLit.Token.Source
+	}
 	return jsonMarshalWithoutEscaping(strLit.Value)
 }
 
 // SymbolName represents a symbol that may represent a variable or a function.
 type SymbolName struct {
 	Token token.Token
+	End   token.Position
 	Value string
 }
 
@@ -247,6 +657,7 @@ func (sym *SymbolName) String() string {
 // TrueLiteral represents a boolean true value.
 type TrueLiteral struct {
 	Token token.Token
+	End   token.Position
 }
 
 // String converts the TrueLiteral node back to lisp source code.
@@ -257,6 +668,7 @@ func (tru *TrueLiteral) String() string {
 // UnitExpr represents an expression returning the value of the Unit type.
 type UnitExpr struct {
 	Token token.Token
+	End   token.Position
 }
 
 // String converts the UnitExpr node back to lisp source code.
@@ -264,9 +676,28 @@ func (unit *UnitExpr) String() string {
 	return "()"
 }
 
+// UnquoteExpr represents an unquoted expression inside a quasiquote, which
+// is either `,x`, inserting the value of x, or `,@x`, when Splicing is true,
+// inserting the elements of the value of x into the enclosing list.
+type UnquoteExpr struct {
+	Token    token.Token
+	End      token.Position
+	Splicing bool
+	Expr     Node
+}
+
+// String converts the UnquoteExpr node back to lisp source code.
+func (unquote *UnquoteExpr) String() string {
+	if unquote.Splicing {
+		return ",@" + unquote.Expr.String()
+	}
+	return "," + unquote.Expr.String()
+}
+
 // WhileExpr represents a while loop to execute a block of code repeatedly while the condition is true.
 type WhileExpr struct {
 	Token     token.Token
+	End       token.Position
 	Predicate Node
 	Expr      Node
 }
@@ -275,3 +706,16 @@ type WhileExpr struct {
 func (whl *WhileExpr) String() string {
 	return fmt.Sprintf("(while %s %s)", whl.Predicate.String(), whl.Expr.String())
 }
+
+// YieldStmt represents a yield statement suspending the generator
+// running the current function and handing over a value.
+type YieldStmt struct {
+	Token token.Token
+	End   token.Position
+	Expr  Node
+}
+
+// String converts the YieldStmt node back to lisp source code.
+func (yld *YieldStmt) String() string {
+	return fmt.Sprintf("(yield! %s)", yld.Expr.String())
+}
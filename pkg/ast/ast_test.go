@@ -3,6 +3,8 @@
 package ast
 
 import (
+	"errors"
+	"strconv"
 	"testing"
 
 	"github.com/bassosimone/buresu/pkg/token"
@@ -37,6 +39,23 @@ func TestCallExpr(t *testing.T) {
 	})
 }
 
+func TestCharLiteral(t *testing.T) {
+	tests := map[rune]string{'a': `#\a`, ' ': `#\space`, '\n': `#\newline`, 0: `#\nul`, '\u200b': `#\u{200B}`}
+	for value, expected := range tests {
+		expr := &CharLiteral{Token: token.Token{TokenType: token.CHAR, Value: string(value)}, Value: value}
+		if expr.String() != expected {
+			t.Errorf("expected %s, got %s", expected, expr.String())
+		}
+	}
+	t.Run("serialization using the source", func(t *testing.T) {
+		tok := token.Token{TokenType: token.CHAR, Value: "a", Source: `#\u{61}`}
+		expr := &CharLiteral{Token: tok, Value: 'a'}
+		if expr.String() != `#\u{61}` {
+			t.Errorf("expected %s, got %s", `#\u{61}`, expr.String())
+		}
+	})
+}
+
 func TestCondExpr(t *testing.T) {
 	tok := token.Token{TokenType: token.ATOM, Value: "cond"}
 	trueToken := token.Token{TokenType: token.ATOM, Value: "true"}
@@ -87,6 +106,17 @@ func TestEllipsisLiteral(t *testing.T) {
 	})
 }
 
+func TestErrorExpr(t *testing.T) {
+	tok := token.Token{TokenType: token.OPEN, Value: "("}
+	expr := &ErrorExpr{Token: tok}
+	expected := "<error>"
+	t.Run("serialization", func(t *testing.T) {
+		if expr.String() != expected {
+			t.Errorf("expected %s, got %s", expected, expr.String())
+		}
+	})
+}
+
 func TestFloatLiteral(t *testing.T) {
 	tok := token.Token{TokenType: token.NUMBER, Value: "3.14"}
 	expr := &FloatLiteral{Token: tok, Value: "3.14"}
@@ -109,6 +139,26 @@ func TestIncludeStmt(t *testing.T) {
 	})
 }
 
+func TestImportStmt(t *testing.T) {
+	tok := token.Token{TokenType: token.ATOM, Value: "import"}
+	expr := &ImportStmt{Token: tok, FilePath: "lib/math.brs", Alias: "math"}
+	expected := "(import \"lib/math.brs\" as math)"
+	t.Run("serialization", func(t *testing.T) {
+		if expr.String() != expected {
+			t.Errorf("expected %s, got %s", expected, expr.String())
+		}
+	})
+}
+
+func TestKeywordLiteral(t *testing.T) {
+	tok := token.Token{TokenType: token.KEYWORD, Value: ":name"}
+	expr := &KeywordLiteral{Token: tok, Value: ":name"}
+	expected := ":name"
+	if expr.String() != expected {
+		t.Errorf("expected %s, got %s", expected, expr.String())
+	}
+}
+
 func TestLambdaExpr(t *testing.T) {
 	tok := token.Token{TokenType: token.ATOM, Value: "lambda"}
 	param := "x"
@@ -122,6 +172,54 @@ func TestLambdaExpr(t *testing.T) {
 	})
 }
 
+func TestLetExpr(t *testing.T) {
+	tok := token.Token{TokenType: token.ATOM, Value: "let"}
+	bindings := []LetBinding{
+		{Symbol: "x", Expr: &IntLiteral{Token: token.Token{TokenType: token.NUMBER, Value: "1"}, Value: "1"}},
+		{Symbol: "y", Expr: &IntLiteral{Token: token.Token{TokenType: token.NUMBER, Value: "2"}, Value: "2"}},
+	}
+	body := &SymbolName{Token: token.Token{TokenType: token.ATOM, Value: "x"}, Value: "x"}
+	t.Run("let serialization", func(t *testing.T) {
+		expr := &LetExpr{Token: tok, Bindings: bindings, Expr: body}
+		expected := "(let ((x 1) (y 2)) x)"
+		if expr.String() != expected {
+			t.Errorf("expected %s, got %s", expected, expr.String())
+		}
+	})
+	t.Run("let* serialization", func(t *testing.T) {
+		expr := &LetStarExpr{Token: tok, Bindings: bindings, Expr: body}
+		expected := "(let* ((x 1) (y 2)) x)"
+		if expr.String() != expected {
+			t.Errorf("expected %s, got %s", expected, expr.String())
+		}
+	})
+	t.Run("letrec serialization", func(t *testing.T) {
+		expr := &LetrecExpr{Token: tok, Bindings: bindings, Expr: body}
+		expected := "(letrec ((x 1) (y 2)) x)"
+		if expr.String() != expected {
+			t.Errorf("expected %s, got %s", expected, expr.String())
+		}
+	})
+	t.Run("empty bindings serialization", func(t *testing.T) {
+		expr := &LetExpr{Token: tok, Bindings: nil, Expr: body}
+		expected := "(let () x)"
+		if expr.String() != expected {
+			t.Errorf("expected %s, got %s", expected, expr.String())
+		}
+	})
+}
+
+func TestModuleStmt(t *testing.T) {
+	tok := token.Token{TokenType: token.ATOM, Value: "module"}
+	expr := &ModuleStmt{Token: tok, Name: "math", Exports: []string{"square", "cube"}}
+	expected := "(module math (export square cube))"
+	t.Run("serialization", func(t *testing.T) {
+		if expr.String() != expected {
+			t.Errorf("expected %s, got %s", expected, expr.String())
+		}
+	})
+}
+
 func TestQuoteExpr(t *testing.T) {
 	tok := token.Token{TokenType: token.ATOM, Value: "quote"}
 	expr := &QuoteExpr{Token: tok, Expr: &IntLiteral{Token: token.Token{TokenType: token.NUMBER, Value: "42"}, Value: "42"}}
@@ -133,6 +231,162 @@ func TestQuoteExpr(t *testing.T) {
 	})
 }
 
+func TestQuasiquoteExpr(t *testing.T) {
+	sym := func(value string) *SymbolName {
+		return &SymbolName{Token: token.Token{TokenType: token.ATOM, Value: value}, Value: value}
+	}
+	// `(a ,b `(c ,d ,,e) ,@f)
+	b := &UnquoteExpr{Expr: sym("b")}
+	innerD := &UnquoteExpr{Expr: sym("d")}
+	e := &UnquoteExpr{Expr: sym("e")}
+	f := &UnquoteExpr{Splicing: true, Expr: sym("f")}
+	inner := &QuasiquoteExpr{Expr: &CallExpr{
+		Callable: sym("c"),
+		Args:     []Node{innerD, &UnquoteExpr{Expr: e}},
+	}}
+	expr := &QuasiquoteExpr{Expr: &CallExpr{Callable: sym("a"), Args: []Node{b, inner, f}}}
+	t.Run("serialization", func(t *testing.T) {
+		expected := "`(a ,b `(c ,d ,,e) ,@f)"
+		if expr.String() != expected {
+			t.Errorf("expected %s, got %s", expected, expr.String())
+		}
+	})
+	t.Run("unquotes", func(t *testing.T) {
+		unquotes := expr.Unquotes()
+		if len(unquotes) != 3 || unquotes[0] != b || unquotes[1] != e || unquotes[2] != f {
+			t.Errorf("unexpected unquotes: %v", unquotes)
+		}
+	})
+	t.Run("expand", func(t *testing.T) {
+		values := map[*UnquoteExpr][]Node{b: {sym("1")}, e: {sym("2")}, f: {sym("3"), sym("4")}}
+		node, err := expr.Expand(values)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "(a 1 `(c ,d ,2) 3 4)"
+		if node.String() != expected {
+			t.Errorf("expected %s, got %s", expected, node.String())
+		}
+		if expr.String() != "`(a ,b `(c ,d ,,e) ,@f)" {
+			t.Errorf("expand modified the original expression: %s", expr.String())
+		}
+	})
+	t.Run("splicing outside of a list", func(t *testing.T) {
+		expr := &QuasiquoteExpr{Expr: &DefineExpr{Symbol: "x", Expr: f}}
+		_, err := expr.Expand(map[*UnquoteExpr][]Node{f: {sym("3"), sym("4")}})
+		if !errors.Is(err, ErrSplicingOutsideList) {
+			t.Errorf("expected ErrSplicingOutsideList, got %v", err)
+		}
+	})
+}
+
+func TestQuoteShorthand(t *testing.T) {
+	tok := token.Token{TokenType: token.QUOTE, Value: "'"}
+	expr := &QuoteExpr{Token: tok, Expr: &UnitExpr{}}
+	expected := "'()"
+	if expr.String() != expected {
+		t.Errorf("expected %s, got %s", expected, expr.String())
+	}
+}
+
+func TestRationalLiteral(t *testing.T) {
+	tok := token.Token{TokenType: token.NUMBER, Value: "-1_000/3"}
+	expr := &RationalLiteral{Token: tok, Value: "-1_000/3"}
+	expected := "-1_000/3"
+	t.Run("serialization", func(t *testing.T) {
+		if expr.String() != expected {
+			t.Errorf("expected %s, got %s", expected, expr.String())
+		}
+	})
+	t.Run("value", func(t *testing.T) {
+		value, err := expr.Rat()
+		if err != nil || value.RatString() != "-1000/3" {
+			t.Errorf("expected -1000/3, got %v, %v", value, err)
+		}
+	})
+	t.Run("zero denominator", func(t *testing.T) {
+		expr := &RationalLiteral{Token: tok, Value: "1/0_0"}
+		if _, err := expr.Rat(); !errors.Is(err, ErrZeroDenominator) {
+			t.Errorf("expected ErrZeroDenominator, got %v", err)
+		}
+	})
+}
+
+func TestNumberValues(t *testing.T) {
+	ints := map[string]int{"0": 0, "-17": -17, "1_000": 1000, "0xff": 255, "-0o17": -15, "0b_1010": 10, "010": 10}
+	for value, expected := range ints {
+		got, err := (&IntLiteral{Value: value}).Int()
+		if err != nil || got != expected {
+			t.Errorf("%s: expected %d, got %d, %v", value, expected, got, err)
+		}
+	}
+	floats := map[string]float64{"3.14": 3.14, ".5": 0.5, "-.5": -0.5, "1.": 1, "1_0e-1": 1, "6.5E+2": 650}
+	for value, expected := range floats {
+		got, err := (&FloatLiteral{Value: value}).Float64()
+		if err != nil || got != expected {
+			t.Errorf("%s: expected %f, got %f, %v", value, expected, got, err)
+		}
+	}
+	if _, err := (&IntLiteral{Value: "9223372036854775808"}).Int(); !errors.Is(err, strconv.ErrRange) {
+		t.Errorf("expected strconv.ErrRange, got %v", err)
+	}
+}
+
+func TestBreakStmt(t *testing.T) {
+	tok := token.Token{TokenType: token.ATOM, Value: "break!"}
+	expr := &BreakStmt{Token: tok}
+	expected := "(break!)"
+	t.Run("serialization", func(t *testing.T) {
+		if expr.String() != expected {
+			t.Errorf("expected %s, got %s", expected, expr.String())
+		}
+	})
+}
+
+func TestContinueStmt(t *testing.T) {
+	tok := token.Token{TokenType: token.ATOM, Value: "continue!"}
+	expr := &ContinueStmt{Token: tok}
+	expected := "(continue!)"
+	t.Run("serialization", func(t *testing.T) {
+		if expr.String() != expected {
+			t.Errorf("expected %s, got %s", expected, expr.String())
+		}
+	})
+}
+
+func TestForExpr(t *testing.T) {
+	tok := token.Token{TokenType: token.ATOM, Value: "for"}
+	expr := &ForExpr{
+		Token:  tok,
+		Symbol: "i",
+		From:   &IntLiteral{Token: token.Token{TokenType: token.NUMBER, Value: "0"}, Value: "0"},
+		To:     &IntLiteral{Token: token.Token{TokenType: token.NUMBER, Value: "10"}, Value: "10"},
+		Expr:   &SymbolName{Token: token.Token{TokenType: token.ATOM, Value: "i"}, Value: "i"},
+	}
+	expected := "(for (i 0 10) i)"
+	t.Run("serialization", func(t *testing.T) {
+		if expr.String() != expected {
+			t.Errorf("expected %s, got %s", expected, expr.String())
+		}
+	})
+}
+
+func TestForeachExpr(t *testing.T) {
+	tok := token.Token{TokenType: token.ATOM, Value: "foreach"}
+	expr := &ForeachExpr{
+		Token:  tok,
+		Symbol: "x",
+		Seq:    &StringLiteral{Token: token.Token{TokenType: token.STRING, Value: "abc"}, Value: "abc"},
+		Expr:   &SymbolName{Token: token.Token{TokenType: token.ATOM, Value: "x"}, Value: "x"},
+	}
+	expected := `(foreach (x "abc") x)`
+	t.Run("serialization", func(t *testing.T) {
+		if expr.String() != expected {
+			t.Errorf("expected %s, got %s", expected, expr.String())
+		}
+	})
+}
+
 func TestReturnStmt(t *testing.T) {
 	tok := token.Token{TokenType: token.ATOM, Value: "return!"}
 	expr := &ReturnStmt{Token: tok, Expr: &IntLiteral{Token: token.Token{TokenType: token.NUMBER, Value: "42"}, Value: "42"}}
@@ -155,6 +409,24 @@ func TestSetExpr(t *testing.T) {
 	})
 }
 
+func TestStringLiteral(t *testing.T) {
+	t.Run("serialization using the source", func(t *testing.T) {
+		tok := token.Token{TokenType: token.STRING, Value: `say "hi"`, Source: `r#"say "hi""#`}
+		expr := &StringLiteral{Token: tok, Value: tok.Value}
+		expected := `r#"say "hi""#`
+		if expr.String() != expected {
+			t.Errorf("expected %s, got %s", expected, expr.String())
+		}
+	})
+	t.Run("serialization without the source", func(t *testing.T) {
+		expr := &StringLiteral{Token: token.Token{TokenType: token.STRING}, Value: "a\n\"b\""}
+		expected := `"a\n\"b\""`
+		if expr.String() != expected {
+			t.Errorf("expected %s, got %s", expected, expr.String())
+		}
+	})
+}
+
 func TestUnitExpr(t *testing.T) {
 	tok := token.Token{TokenType: token.ATOM, Value: "()"}
 	expr := &UnitExpr{Token: tok}
@@ -178,3 +450,14 @@ func TestWhileExpr(t *testing.T) {
 		}
 	})
 }
+
+func TestYieldStmt(t *testing.T) {
+	tok := token.Token{TokenType: token.ATOM, Value: "yield!"}
+	expr := &YieldStmt{Token: tok, Expr: &IntLiteral{Token: token.Token{TokenType: token.NUMBER, Value: "42"}, Value: "42"}}
+	expected := "(yield! 42)"
+	t.Run("serialization", func(t *testing.T) {
+		if expr.String() != expected {
+			t.Errorf("expected %s, got %s", expected, expr.String())
+		}
+	})
+}
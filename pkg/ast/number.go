@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package ast
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// The Value of the number literals is the source code of the literal, which
+// may contain underscores separating digits (e.g., `1_000`). The following
+// methods convert such source code to the corresponding value.
+
+// Int returns the value of the literal, which may have a radix prefix.
+//
+// The error wraps [strconv.ErrRange] if the value does not fit an int.
+func (intLit *IntLiteral) Int() (int, error) {
+	value := strings.ReplaceAll(intLit.Value, "_", "")
+	base := 10
+	if hasRadixPrefix(value) {
+		base = 0 // let strconv handle the prefix
+	}
+	number, err := strconv.ParseInt(value, base, strconv.IntSize)
+	return int(number), err
+}
+
+// hasRadixPrefix returns whether the number has a radix prefix (e.g., `0x`).
+func hasRadixPrefix(value string) bool {
+	value = strings.TrimPrefix(value, "-")
+	return len(value) > 2 && value[0] == '0' && strings.ContainsRune("xXoObB", rune(value[1]))
+}
+
+// Float64 returns the value of the literal.
+//
+// The error wraps [strconv.ErrRange] if the value does not fit a float64.
+func (fltLit *FloatLiteral) Float64() (float64, error) {
+	return strconv.ParseFloat(strings.ReplaceAll(fltLit.Value, "_", ""), 64)
+}
+
+// ErrZeroDenominator indicates that a rational literal has a zero denominator.
+var ErrZeroDenominator = errors.New("zero denominator")
+
+// ErrInvalidRational indicates that a rational literal is not well formed.
+var ErrInvalidRational = errors.New("invalid rational literal")
+
+// Rat returns the value of the literal, which has the `n/d` form.
+func (ratLit *RationalLiteral) Rat() (*big.Rat, error) {
+	value := strings.ReplaceAll(ratLit.Value, "_", "")
+	num, den, found := strings.Cut(value, "/")
+	if !found {
+		return nil, ErrInvalidRational
+	}
+	n, ok := new(big.Int).SetString(num, 10)
+	if !ok {
+		return nil, ErrInvalidRational
+	}
+	d, ok := new(big.Int).SetString(den, 10)
+	if !ok || d.Sign() < 0 {
+		return nil, ErrInvalidRational
+	}
+	if d.Sign() == 0 {
+		return nil, ErrZeroDenominator
+	}
+	return new(big.Rat).SetFrac(n, d), nil
+}
@@ -0,0 +1,345 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package ast
+
+import "errors"
+
+// ErrSplicingOutsideList indicates that an unquote-splicing does
+// not appear among the elements of a list (e.g., "`,@x").
+var ErrSplicingOutsideList = errors.New("unquote-splicing outside of a list")
+
+// Unquotes returns the unquoted expressions of the quasiquote that we need to
+// evaluate, in source order, skipping those belonging to nested quasiquotes.
+//
+// For example, in "`(a ,b `(c ,d ,,e))" we need to evaluate `b` and `e`.
+func (qq *QuasiquoteExpr) Unquotes() []*UnquoteExpr {
+	var unquotes []*UnquoteExpr
+	collectUnquotes(qq.Expr, 1, &unquotes)
+	return unquotes
+}
+
+// collectUnquotes appends to unquotes the unquoted expressions inside
+// the given node, where level is the current quasiquote nesting level.
+func collectUnquotes(node Node, level int, unquotes *[]*UnquoteExpr) {
+	switch node := node.(type) {
+	case *QuasiquoteExpr:
+		collectUnquotes(node.Expr, level+1, unquotes)
+
+	case *UnquoteExpr:
+		if level <= 1 {
+			*unquotes = append(*unquotes, node)
+			return
+		}
+		collectUnquotes(node.Expr, level-1, unquotes)
+
+	default:
+		for _, child := range quasiquoteChildren(node) {
+			collectUnquotes(child, level, unquotes)
+		}
+	}
+}
+
+// quasiquoteChildren returns the children of the given node.
+func quasiquoteChildren(node Node) []Node {
+	switch node := node.(type) {
+	case *BlockExpr:
+		return node.Exprs
+
+	case *CallExpr:
+		return append([]Node{node.Callable}, node.Args...)
+
+	case *CondExpr:
+		var nodes []Node
+		for _, c := range node.Cases {
+			nodes = append(nodes, c.Predicate, c.Expr)
+		}
+		return append(nodes, node.ElseExpr)
+
+	case *DeclareExpr:
+		return []Node{node.Expr}
+
+	case *DefineExpr:
+		return []Node{node.Expr}
+
+	case *ForExpr:
+		return []Node{node.From, node.To, node.Expr}
+
+	case *ForeachExpr:
+		return []Node{node.Seq, node.Expr}
+
+	case *LambdaExpr:
+		return []Node{node.Expr}
+
+	case *LetExpr:
+		return append(letBindingExprs(node.Bindings), node.Expr)
+
+	case *LetStarExpr:
+		return append(letBindingExprs(node.Bindings), node.Expr)
+
+	case *LetrecExpr:
+		return append(letBindingExprs(node.Bindings), node.Expr)
+
+	case *QuoteExpr:
+		return []Node{node.Expr}
+
+	case *ReturnStmt:
+		return []Node{node.Expr}
+
+	case *SetExpr:
+		return []Node{node.Expr}
+
+	case *WhileExpr:
+		return []Node{node.Predicate, node.Expr}
+
+	case *YieldStmt:
+		return []Node{node.Expr}
+
+	default:
+		return nil
+	}
+}
+
+// letBindingExprs returns the expressions of the given let bindings.
+func letBindingExprs(bindings []LetBinding) []Node {
+	nodes := make([]Node, 0, len(bindings))
+	for _, binding := range bindings {
+		nodes = append(nodes, binding.Expr)
+	}
+	return nodes
+}
+
+// Expand returns a copy of the quasiquoted expression where we replace each
+// unquoted expression returned by [*QuasiquoteExpr.Unquotes] with the nodes
+// in values, which must contain a single node unless we are splicing.
+//
+// The error is [ErrSplicingOutsideList] if the quasiquote contains an
+// unquote-splicing that does not appear among the elements of a list.
+func (qq *QuasiquoteExpr) Expand(values map[*UnquoteExpr][]Node) (Node, error) {
+	e := &expander{values: values}
+	return e.expandOne(qq.Expr, 1)
+}
+
+// expander expands quasiquoted expressions.
+type expander struct {
+	values map[*UnquoteExpr][]Node
+}
+
+// expandOne expands a node that cannot be replaced by multiple nodes.
+func (e *expander) expandOne(node Node, level int) (Node, error) {
+	nodes, err := e.expand(node, level)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) != 1 {
+		return nil, ErrSplicingOutsideList
+	}
+	return nodes[0], nil
+}
+
+// expandList expands the given elements of a list, splicing their values.
+func (e *expander) expandList(nodes []Node, level int) ([]Node, error) {
+	var out []Node
+	for _, node := range nodes {
+		expanded, err := e.expand(node, level)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, expanded...)
+	}
+	return out, nil
+}
+
+// expandBindings expands the expressions of the given let bindings.
+func (e *expander) expandBindings(bindings []LetBinding, level int) ([]LetBinding, error) {
+	out := make([]LetBinding, 0, len(bindings))
+	for _, binding := range bindings {
+		expr, err := e.expandOne(binding.Expr, level)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, LetBinding{Symbol: binding.Symbol, Expr: expr})
+	}
+	return out, nil
+}
+
+// expand expands the given node at the given quasiquote nesting level.
+func (e *expander) expand(node Node, level int) ([]Node, error) {
+	switch node := node.(type) {
+	case *BlockExpr:
+		exprs, err := e.expandList(node.Exprs, level)
+		if err != nil {
+			return nil, err
+		}
+		return []Node{&BlockExpr{Token: node.Token, End: node.End, Exprs: exprs}}, nil
+
+	case *CallExpr:
+		elements, err := e.expandList(append([]Node{node.Callable}, node.Args...), level)
+		if err != nil {
+			return nil, err
+		}
+		if len(elements) <= 0 {
+			return []Node{&UnitExpr{Token: node.Token, End: node.End}}, nil
+		}
+		return []Node{&CallExpr{Token: node.Token, End: node.End, Callable: elements[0], Args: elements[1:]}}, nil
+
+	case *CondExpr:
+		cases := make([]CondCase, 0, len(node.Cases))
+		for _, c := range node.Cases {
+			predicate, err := e.expandOne(c.Predicate, level)
+			if err != nil {
+				return nil, err
+			}
+			expr, err := e.expandOne(c.Expr, level)
+			if err != nil {
+				return nil, err
+			}
+			cases = append(cases, CondCase{Predicate: predicate, Expr: expr})
+		}
+		elseExpr, err := e.expandOne(node.ElseExpr, level)
+		if err != nil {
+			return nil, err
+		}
+		return []Node{&CondExpr{Token: node.Token, End: node.End, Cases: cases, ElseExpr: elseExpr}}, nil
+
+	case *DeclareExpr:
+		expr, err := e.expandOne(node.Expr, level)
+		if err != nil {
+			return nil, err
+		}
+		return []Node{&DeclareExpr{Token: node.Token, End: node.End, Symbol: node.Symbol, Expr: expr}}, nil
+
+	case *DefineExpr:
+		expr, err := e.expandOne(node.Expr, level)
+		if err != nil {
+			return nil, err
+		}
+		return []Node{&DefineExpr{Token: node.Token, End: node.End, Symbol: node.Symbol, Expr: expr}}, nil
+
+	case *ForExpr:
+		from, err := e.expandOne(node.From, level)
+		if err != nil {
+			return nil, err
+		}
+		to, err := e.expandOne(node.To, level)
+		if err != nil {
+			return nil, err
+		}
+		expr, err := e.expandOne(node.Expr, level)
+		if err != nil {
+			return nil, err
+		}
+		return []Node{&ForExpr{
+			Token: node.Token, End: node.End, Symbol: node.Symbol, From: from, To: to, Expr: expr}}, nil
+
+	case *ForeachExpr:
+		seq, err := e.expandOne(node.Seq, level)
+		if err != nil {
+			return nil, err
+		}
+		expr, err := e.expandOne(node.Expr, level)
+		if err != nil {
+			return nil, err
+		}
+		return []Node{&ForeachExpr{Token: node.Token, End: node.End, Symbol: node.Symbol, Seq: seq, Expr: expr}}, nil
+
+	case *LambdaExpr:
+		expr, err := e.expandOne(node.Expr, level)
+		if err != nil {
+			return nil, err
+		}
+		return []Node{&LambdaExpr{
+			Token: node.Token, End: node.End, Params: node.Params, Docs: node.Docs, Expr: expr}}, nil
+
+	case *LetExpr:
+		bindings, err := e.expandBindings(node.Bindings, level)
+		if err != nil {
+			return nil, err
+		}
+		expr, err := e.expandOne(node.Expr, level)
+		if err != nil {
+			return nil, err
+		}
+		return []Node{&LetExpr{Token: node.Token, End: node.End, Bindings: bindings, Expr: expr}}, nil
+
+	case *LetStarExpr:
+		bindings, err := e.expandBindings(node.Bindings, level)
+		if err != nil {
+			return nil, err
+		}
+		expr, err := e.expandOne(node.Expr, level)
+		if err != nil {
+			return nil, err
+		}
+		return []Node{&LetStarExpr{Token: node.Token, End: node.End, Bindings: bindings, Expr: expr}}, nil
+
+	case *LetrecExpr:
+		bindings, err := e.expandBindings(node.Bindings, level)
+		if err != nil {
+			return nil, err
+		}
+		expr, err := e.expandOne(node.Expr, level)
+		if err != nil {
+			return nil, err
+		}
+		return []Node{&LetrecExpr{Token: node.Token, End: node.End, Bindings: bindings, Expr: expr}}, nil
+
+	case *QuasiquoteExpr:
+		expr, err := e.expandOne(node.Expr, level+1)
+		if err != nil {
+			return nil, err
+		}
+		return []Node{&QuasiquoteExpr{Token: node.Token, End: node.End, Expr: expr}}, nil
+
+	case *QuoteExpr:
+		expr, err := e.expandOne(node.Expr, level)
+		if err != nil {
+			return nil, err
+		}
+		return []Node{&QuoteExpr{Token: node.Token, End: node.End, Expr: expr}}, nil
+
+	case *ReturnStmt:
+		expr, err := e.expandOne(node.Expr, level)
+		if err != nil {
+			return nil, err
+		}
+		return []Node{&ReturnStmt{Token: node.Token, End: node.End, Expr: expr}}, nil
+
+	case *SetExpr:
+		expr, err := e.expandOne(node.Expr, level)
+		if err != nil {
+			return nil, err
+		}
+		return []Node{&SetExpr{Token: node.Token, End: node.End, Symbol: node.Symbol, Expr: expr}}, nil
+
+	case *UnquoteExpr:
+		if level <= 1 {
+			return e.values[node], nil
+		}
+		expr, err := e.expandOne(node.Expr, level-1)
+		if err != nil {
+			return nil, err
+		}
+		return []Node{&UnquoteExpr{Token: node.Token, End: node.End, Splicing: node.Splicing, Expr: expr}}, nil
+
+	case *WhileExpr:
+		predicate, err := e.expandOne(node.Predicate, level)
+		if err != nil {
+			return nil, err
+		}
+		expr, err := e.expandOne(node.Expr, level)
+		if err != nil {
+			return nil, err
+		}
+		return []Node{&WhileExpr{Token: node.Token, End: node.End, Predicate: predicate, Expr: expr}}, nil
+
+	case *YieldStmt:
+		expr, err := e.expandOne(node.Expr, level)
+		if err != nil {
+			return nil, err
+		}
+		return []Node{&YieldStmt{Token: node.Token, End: node.End, Expr: expr}}, nil
+
+	default:
+		return []Node{node}, nil
+	}
+}
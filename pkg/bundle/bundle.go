@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package bundle contains code to open packaged multi-file programs.
+//
+// A bundle is a zip or txtar archive containing the program source
+// files along with a manifest, which is a JSON file named [ManifestName]
+// at the root of the archive declaring the program entry point.
+package bundle
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/includer"
+	"github.com/bassosimone/buresu/pkg/token"
+	"golang.org/x/tools/txtar"
+)
+
+// ManifestName is the name of the manifest inside a bundle.
+const ManifestName = "bundle.json"
+
+// Manifest describes a bundle.
+type Manifest struct {
+	// Entry is the slash-separated path of the file inside
+	// the bundle that we should run first.
+	Entry string `json:"entry"`
+}
+
+// Bundle is an open bundle.
+type Bundle struct {
+	// Location allows to include the files inside the bundle.
+	Location includer.Location
+
+	// Manifest is the bundle manifest.
+	Manifest Manifest
+
+	// close releases the resources used by the bundle.
+	close func() error
+}
+
+// Close releases the resources used by the bundle.
+func (b *Bundle) Close() error {
+	return b.close()
+}
+
+// Nodes returns the AST of the program contained in the bundle, which
+// consists of a statement including the entry point. To resolve it, the
+// includer search path must contain the bundle [Location].
+func (b *Bundle) Nodes() []ast.Node {
+	include := &ast.IncludeStmt{
+		Token: token.Token{
+			TokenPos: token.Position{
+				FileName:   "<bundle>",
+				LineNumber: 1,
+				LineColumn: 1,
+			},
+			TokenType: token.ATOM,
+			Value:     "include",
+		},
+		FilePath: b.Manifest.Entry,
+	}
+	return []ast.Node{include}
+}
+
+// IsBundle returns whether the given file name refers to a bundle,
+// which we determine by looking at the file name extension.
+func IsBundle(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".zip", ".txtar":
+		return true
+	default:
+		return false
+	}
+}
+
+// Open opens the bundle with the given file name.
+func Open(filename string) (*Bundle, error) {
+	var (
+		fsys  fs.FS
+		close = func() error { return nil }
+	)
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".zip":
+		reader, err := zip.OpenReader(filename)
+		if err != nil {
+			return nil, err
+		}
+		fsys, close = reader, reader.Close
+
+	case ".txtar":
+		archive, err := txtar.ParseFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		if fsys, err = txtar.FS(archive); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, fmt.Errorf("bundle %s: unsupported archive format", filename)
+	}
+
+	manifest, err := readManifest(fsys)
+	if err != nil {
+		close()
+		return nil, fmt.Errorf("bundle %s: %w", filename, err)
+	}
+	bundle := &Bundle{
+		Location: includer.Location{Name: filename, FS: fsys},
+		Manifest: manifest,
+		close:    close,
+	}
+	return bundle, nil
+}
+
+// readManifest reads and validates the manifest inside the given file system.
+func readManifest(fsys fs.FS) (Manifest, error) {
+	var manifest Manifest
+	data, err := fs.ReadFile(fsys, ManifestName)
+	if err != nil {
+		return manifest, fmt.Errorf("cannot read manifest: %w", err)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return manifest, fmt.Errorf("cannot parse manifest: %w", err)
+	}
+	if manifest.Entry == "" {
+		return manifest, errors.New("manifest does not declare the entry point")
+	}
+	if !fs.ValidPath(manifest.Entry) {
+		return manifest, fmt.Errorf("invalid entry point: %s", manifest.Entry)
+	}
+	return manifest, nil
+}
@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package bundle
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bassosimone/buresu/pkg/includer"
+)
+
+func TestOpen(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		expected string
+		err      string
+	}{
+		{
+			name:     "txtar bundle",
+			filename: "hello.txtar",
+			expected: `(define greet (lambda (name) "" (display "hello," name))) (greet "world")`,
+		},
+		{
+			name:     "zip bundle",
+			filename: "hello.zip",
+			expected: `(define greet (lambda (name) "" (display "hello," name))) (greet "world")`,
+		},
+		{
+			name:     "missing manifest",
+			filename: "no-manifest.txtar",
+			err:      "cannot read manifest",
+		},
+		{
+			name:     "missing entry point",
+			filename: "missing-entry.txtar",
+			err:      "manifest does not declare the entry point",
+		},
+		{
+			name:     "invalid entry point",
+			filename: "invalid-entry.txtar",
+			err:      "invalid entry point: ../main.brs",
+		},
+		{
+			name:     "unsupported archive format",
+			filename: "hello.tar",
+			err:      "unsupported archive format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := Open(filepath.Join("testdata", tt.filename))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error %s, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer b.Close()
+
+			nodes, err := includer.Include([]includer.Location{b.Location}, b.Nodes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, node := range nodes {
+				got = append(got, node.String())
+			}
+			if strings.Join(got, " ") != tt.expected {
+				t.Fatalf("expected %s, got %s", tt.expected, strings.Join(got, " "))
+			}
+		})
+	}
+}
+
+func TestIsBundle(t *testing.T) {
+	for filename, expected := range map[string]bool{
+		"bundle.zip":   true,
+		"bundle.ZIP":   true,
+		"bundle.txtar": true,
+		"script.brs":   false,
+		"zip":          false,
+	} {
+		if got := IsBundle(filename); got != expected {
+			t.Errorf("IsBundle(%q): expected %v, got %v", filename, expected, got)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package diagnostics converts the errors emitted by the scanner, the
+// parser, the includer, the typechecker, and the evaluator into a
+// [*Diagnostic] and renders it along with the offending source code.
+//
+// Use [FromError] to convert an error, [NewRenderer] to create a
+// [*Renderer], and [*Renderer.Render] to print diagnostics as plain
+// text, as colored text for terminals, or as JSON for tools.
+package diagnostics
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	evaluator "github.com/bassosimone/buresu/pkg/evaluator/simple"
+	"github.com/bassosimone/buresu/pkg/includer"
+	"github.com/bassosimone/buresu/pkg/parser"
+	"github.com/bassosimone/buresu/pkg/scanner"
+	"github.com/bassosimone/buresu/pkg/token"
+	typechecker "github.com/bassosimone/buresu/pkg/typechecker/simple"
+)
+
+// Severity is the severity of a [*Diagnostic].
+type Severity string
+
+const (
+	// SeverityError indicates an error.
+	SeverityError = Severity("error")
+
+	// SeverityWarning indicates a warning.
+	SeverityWarning = Severity("warning")
+)
+
+// Label is a secondary message attached to a span of the source code.
+type Label struct {
+	Span    token.Span
+	Message string
+}
+
+// Diagnostic is a problem found while processing the source code.
+type Diagnostic struct {
+	// Severity is the severity of the diagnostic.
+	Severity Severity
+
+	// Phase is the phase emitting the diagnostic (e.g., `parser`), which
+	// is empty for errors not associated with any phase.
+	Phase string
+
+	// Message is the main message of the diagnostic.
+	Message string
+
+	// Span is the span of the source code causing the diagnostic, which
+	// is the zero value when the position is not known.
+	Span token.Span
+
+	// Labels contains secondary messages about other spans.
+	Labels []Label
+
+	// Notes contains additional messages without a span.
+	Notes []string
+}
+
+// HasSpan returns whether the diagnostic refers to a span of the source code.
+func (d *Diagnostic) HasSpan() bool {
+	return d.Span.Start.LineNumber > 0
+}
+
+// String returns the diagnostic in the "file:line:col: phase: message" form
+// used by the error messages of the packages implementing the interpreter.
+func (d *Diagnostic) String() string {
+	var parts []string
+	if d.HasSpan() {
+		parts = append(parts, d.Span.Start.String())
+	}
+	if d.Phase != "" {
+		parts = append(parts, d.Phase)
+	}
+	parts = append(parts, d.Message)
+	return strings.Join(parts, ": ")
+}
+
+// FromError converts the given error to a [*Diagnostic].
+//
+// Errors not emitted by the packages implementing the interpreter
+// produce a diagnostic without phase and span.
+func FromError(err error) *Diagnostic {
+	d := &Diagnostic{Severity: SeverityError, Message: err.Error()}
+
+	var (
+		scanErr    *scanner.Error
+		parseErr   *parser.Error
+		includeErr *includer.Error
+		checkErr   *typechecker.Error
+		evalErr    *evaluator.Error
+	)
+	switch {
+	case errors.As(err, &scanErr):
+		d.Phase, d.Message, d.Span = "scanner", scanErr.Message, scanErr.Span()
+
+	case errors.As(err, &parseErr):
+		d.Phase, d.Message, d.Span = "parser", parseErr.Message, parseErr.Span()
+
+	case errors.As(err, &includeErr):
+		d.Phase, d.Message, d.Span = "includer", includeErr.Message, includeErr.Span()
+		if len(includeErr.Tried) > 0 {
+			d.Notes = append(d.Notes, fmt.Sprintf("tried: %s", strings.Join(includeErr.Tried, ", ")))
+		}
+
+	case errors.As(err, &checkErr):
+		// the innermost error is the one with the most precise span
+		for errors.As(checkErr.Err, &checkErr) {
+			// nothing
+		}
+		d.Phase, d.Message, d.Span = "typechecker", checkErr.Err.Error(), checkErr.Span()
+
+	case errors.As(err, &evalErr):
+		for errors.As(evalErr.Err, &evalErr) {
+			// nothing
+		}
+		d.Phase, d.Message, d.Span = "interpreter", evalErr.Err.Error(), evalErr.Span()
+	}
+
+	d.addPreviousDefinition(err)
+	return d
+}
+
+// addPreviousDefinition adds a label pointing to the previous
+// definition when the error is about an already defined symbol.
+func (d *Diagnostic) addPreviousDefinition(err error) {
+	var (
+		previous token.Span
+		found    bool
+	)
+	var checkErr *typechecker.SymbolAlreadyDefinedError
+	if errors.As(err, &checkErr) {
+		previous, found = checkErr.Previous, true
+	}
+	var evalErr *evaluator.SymbolAlreadyDefinedError
+	if errors.As(err, &evalErr) {
+		previous, found = evalErr.Previous, true
+	}
+	switch {
+	case !found:
+		// nothing
+	case previous.Start.LineNumber > 0:
+		d.Labels = append(d.Labels, Label{Span: previous, Message: "previous definition here"})
+	default:
+		d.Notes = append(d.Notes, "the symbol is a built-in")
+	}
+}
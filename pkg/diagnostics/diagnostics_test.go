@@ -0,0 +1,272 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package diagnostics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	evaluator "github.com/bassosimone/buresu/pkg/evaluator/simple"
+	"github.com/bassosimone/buresu/pkg/includer"
+	"github.com/bassosimone/buresu/pkg/parser"
+	"github.com/bassosimone/buresu/pkg/scanner"
+	typechecker "github.com/bassosimone/buresu/pkg/typechecker/simple"
+	"github.com/google/go-cmp/cmp"
+)
+
+// runSource scans, parses, includes, and evaluates the given source code
+// or, when check is true, typechecks it, returning the first error.
+func runSource(source string, check bool) error {
+	tokens, err := scanner.Scan("input.brs", strings.NewReader(source))
+	if err != nil {
+		return err
+	}
+	nodes, err := parser.Parse(tokens)
+	if err != nil {
+		return err
+	}
+	nodes, err = includer.Include(nil, nodes)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	evalEnv := evaluator.NewGlobalEnvironment(nil)
+	checkEnv := typechecker.NewEnvironment()
+	for _, node := range nodes {
+		if check {
+			_, err = typechecker.Check(ctx, checkEnv, node)
+		} else {
+			_, err = evaluator.Eval(ctx, evalEnv, node)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestRenderText(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		check  bool
+		expect string
+	}{{
+		name:   "scanner error",
+		source: "(display \"ciao)",
+		expect: `input.brs:1:10: scanner: expected '"', found: EOF
+  |
+1 | (display "ciao)
+  |          ^^^^^^
+`,
+	}, {
+		name:   "parser error with tab",
+		source: "(block\n\t(define 1 2))",
+		expect: `input.brs:2:10: parser: expected token ATOM, found NUMBER
+  |
+2 | 	(define 1 2))
+  | 	        ^
+`,
+	}, {
+		name:   "includer error",
+		source: "(include! \"missing.brs\")",
+		expect: `input.brs:1:1: includer: cannot find file missing.brs
+  |
+1 | (include! "missing.brs")
+  | ^^^^^^^^^^^^^^^^^^^^^^^^
+  = note: tried: missing.brs
+`,
+	}, {
+		name:   "typechecker error with previous definition",
+		source: "(define x 1)\n(define x \"x\")",
+		check:  true,
+		expect: `input.brs:2:1: typechecker: symbol already defined: x
+  |
+2 | (define x "x")
+  | ^^^^^^^^^^^^^^
+  |
+1 | (define x 1)
+  | ------------ previous definition here
+`,
+	}, {
+		name:   "interpreter error calling a built-in",
+		source: "(display (+ 1 \"a\"))",
+		expect: `input.brs:1:10: interpreter: wrong argument type
+  |
+1 | (display (+ 1 "a"))
+  |          ^^^^^^^^^
+`,
+	}, {
+		name:   "typechecker error calling a lambda",
+		source: "(define f (lambda (x) \":: (Callable (Int) Int)\" x))\n(f \"a\")",
+		check:  true,
+		expect: `input.brs:2:1: typechecker: failed to call (Callable (Int) Int):
+    wrong argument type for param #1 expected Int, got String
+  |
+2 | (f "a")
+  | ^^^^^^^
+`,
+	}, {
+		name:   "interpreter error with previous definition",
+		source: "(define f (lambda () 1))\n\n(define f 2)",
+		expect: `input.brs:3:1: interpreter: symbol already defined: f
+  |
+3 | (define f 2)
+  | ^^^^^^^^^^^^
+  |
+1 | (define f (lambda () 1))
+  | ------------------------ previous definition here
+`,
+	}, {
+		name:   "interpreter error redefining a built-in",
+		source: "(define display 1)",
+		expect: `input.brs:1:1: interpreter: symbol already defined: display
+  |
+1 | (define display 1)
+  | ^^^^^^^^^^^^^^^^^^
+  = note: the symbol is a built-in
+`,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runSource(tt.source, tt.check)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			r := NewRenderer(FormatText)
+			r.AddSource("input.brs", tt.source)
+			var buf bytes.Buffer
+			if err := r.Render(&buf, FromError(err)); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.expect, buf.String()); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestRenderLabelInOtherLocation(t *testing.T) {
+	source := "(include! \"lib.brs\")\n(define f 1)"
+	location := includer.Location{
+		Name: "<lib>",
+		FS:   fstest.MapFS{"lib.brs": {Data: []byte("(define f 0)\n")}},
+	}
+	tokens, err := scanner.Scan("input.brs", strings.NewReader(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes, err := parser.Parse(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes, err = includer.Include([]includer.Location{location}, nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := evaluator.NewGlobalEnvironment(nil)
+	for _, node := range nodes {
+		if _, err = evaluator.Eval(context.Background(), env, node); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	r := NewRenderer(FormatText)
+	r.AddSource("input.brs", source)
+	r.AddLocation(location)
+	var buf bytes.Buffer
+	if err := r.Render(&buf, FromError(err)); err != nil {
+		t.Fatal(err)
+	}
+	expect := `input.brs:2:1: interpreter: symbol already defined: f
+  |
+2 | (define f 1)
+  | ^^^^^^^^^^^^
+  ::: <lib>/lib.brs:1:1
+  |
+1 | (define f 0)
+  | ------------ previous definition here
+`
+	if diff := cmp.Diff(expect, buf.String()); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestRenderWithoutSource(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewRenderer(FormatText)
+	if err := r.Render(&buf, FromError(runSource("(1 2", false))); err != nil {
+		t.Fatal(err)
+	}
+	expect := "input.brs:1:4: parser: unexpected token EOF\n"
+	if diff := cmp.Diff(expect, buf.String()); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestRenderColor(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewRenderer(FormatColor)
+	source := "(define x 1)\n(define x 2)"
+	r.AddSource("input.brs", source)
+	if err := r.Render(&buf, FromError(runSource(source, false))); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), ansiRed+"^^^^^^^^^^^^"+ansiReset) {
+		t.Fatalf("expected colored carets, got %q", buf.String())
+	}
+}
+
+func TestRenderJSON(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewRenderer(FormatJSON)
+	err := runSource("(define x 1)\n(define x 2)", false)
+	if err := r.Render(&buf, FromError(err), FromError(errors.New("mascetti"))); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected two lines, got %d", len(lines))
+	}
+	var got []*Diagnostic
+	for _, line := range lines {
+		var d Diagnostic
+		if err := json.Unmarshal([]byte(line), &d); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, &d)
+	}
+	if got[0].Phase != "interpreter" || got[0].Span.String() != "input.brs:2:1-2:13" ||
+		len(got[0].Labels) != 1 || got[0].Labels[0].Span.String() != "input.brs:1:1-1:13" {
+		t.Fatalf("unexpected diagnostic: %+v", got[0])
+	}
+	expect := &Diagnostic{Severity: SeverityError, Message: "mascetti"}
+	if diff := cmp.Diff(expect, got[1]); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	for _, name := range []string{"text", "color", "json"} {
+		format, err := ParseFormat(name, nil)
+		if err != nil || string(format) != name {
+			t.Fatalf("ParseFormat(%q) = %q, %v", name, format, err)
+		}
+	}
+	if format, err := ParseFormat("auto", nil); err != nil || format != FormatText {
+		t.Fatalf("ParseFormat(auto) = %q, %v", format, err)
+	}
+	if _, err := ParseFormat("xml", nil); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,257 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package diagnostics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bassosimone/buresu/pkg/includer"
+	"github.com/bassosimone/buresu/pkg/token"
+)
+
+// Format is the format used by a [*Renderer].
+type Format string
+
+const (
+	// FormatText renders diagnostics as plain text.
+	FormatText = Format("text")
+
+	// FormatColor renders diagnostics as text using ANSI colors.
+	FormatColor = Format("color")
+
+	// FormatJSON renders each diagnostic as a JSON object on its own line.
+	FormatJSON = Format("json")
+)
+
+// ParseFormat parses the name of a [Format]. The `auto` name selects
+// [FormatColor] if file is a terminal and the NO_COLOR environment
+// variable is not set, and [FormatText] otherwise.
+func ParseFormat(name string, file *os.File) (Format, error) {
+	switch name {
+	case "auto":
+		if os.Getenv("NO_COLOR") == "" && isTerminal(file) {
+			return FormatColor, nil
+		}
+		return FormatText, nil
+	case string(FormatText), string(FormatColor), string(FormatJSON):
+		return Format(name), nil
+	default:
+		return "", fmt.Errorf("unknown diagnostics format: %s", name)
+	}
+}
+
+// isTerminal returns whether the given file is a terminal.
+func isTerminal(file *os.File) bool {
+	stat, err := file.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
+
+// Renderer renders diagnostics along with the offending source code.
+//
+// Use [NewRenderer] to construct.
+type Renderer struct {
+	// format is the rendering format.
+	format Format
+
+	// locations contains the locations where to find included files.
+	locations []includer.Location
+
+	// sources maps file names to the lines of their source code.
+	sources map[string][]string
+}
+
+// NewRenderer creates a new [*Renderer] using the given [Format].
+//
+// By default, the renderer reads the source code from the host file
+// system. Use [*Renderer.AddSource] for code not stored in files and
+// [*Renderer.AddLocation] for the locations of the search path.
+func NewRenderer(format Format) *Renderer {
+	return &Renderer{
+		format:    format,
+		locations: []includer.Location{},
+		sources:   make(map[string][]string),
+	}
+}
+
+// AddSource registers the source code of the given file name, replacing
+// the source code we previously registered or read for it.
+func (r *Renderer) AddSource(fileName string, content string) {
+	r.sources[fileName] = splitLines(content)
+}
+
+// AddLocation registers a location in which to search the source code of
+// files whose name starts with the location name (e.g., `<stdlib>`).
+func (r *Renderer) AddLocation(loc includer.Location) {
+	r.locations = append(r.locations, loc)
+}
+
+// splitLines splits the source code into lines without line terminators.
+func splitLines(content string) []string {
+	lines := strings.Split(content, "\n")
+	for idx, line := range lines {
+		lines[idx] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+// line returns the given 1-based line of the given file, if available.
+func (r *Renderer) line(fileName string, lineNumber int) (string, bool) {
+	lines, found := r.sources[fileName]
+	if !found {
+		lines = r.readSource(fileName)
+		r.sources[fileName] = lines
+	}
+	if lineNumber < 1 || lineNumber > len(lines) {
+		return "", false
+	}
+	return lines[lineNumber-1], true
+}
+
+// readSource reads the lines of the given file, first searching inside
+// the registered locations and then in the host file system.
+func (r *Renderer) readSource(fileName string) []string {
+	for _, loc := range r.locations {
+		rel, err := filepath.Rel(loc.Name, fileName)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if data, err := fs.ReadFile(loc.FS, filepath.ToSlash(rel)); err == nil {
+			return splitLines(string(data))
+		}
+	}
+	if data, err := os.ReadFile(fileName); err == nil {
+		return splitLines(string(data))
+	}
+	return nil
+}
+
+// Render writes the given diagnostics to the given writer.
+func (r *Renderer) Render(w io.Writer, diags ...*Diagnostic) error {
+	for _, d := range diags {
+		if err := r.render(w, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// render writes a single diagnostic to the given writer.
+func (r *Renderer) render(w io.Writer, d *Diagnostic) error {
+	if r.format == FormatJSON {
+		return json.NewEncoder(w).Encode(d)
+	}
+	var b strings.Builder
+	r.renderText(&b, d)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ANSI escape sequences used by [FormatColor].
+const (
+	ansiReset = "\033[0m"
+	ansiBold  = "\033[1m"
+	ansiRed   = "\033[1;31m"
+	ansiBlue  = "\033[1;34m"
+)
+
+// paint wraps the text using the given ANSI style when using colors.
+func (r *Renderer) paint(style, text string) string {
+	if r.format != FormatColor || text == "" {
+		return text
+	}
+	return style + text + ansiReset
+}
+
+// renderText formats the diagnostic as text.
+//
+// The output looks like the following:
+//
+//	input.brs:2:1: typechecker: symbol already defined: x
+//	  |
+//	2 | (define x 2)
+//	  | ^^^^^^^^^^^^
+//	  |
+//	1 | (define x 1)
+//	  | ------------ previous definition here
+//	  = note: ...
+func (r *Renderer) renderText(b *strings.Builder, d *Diagnostic) {
+	// 1. write the header, which is the traditional error message
+	fmt.Fprintf(b, "%s\n", r.paint(ansiBold, d.String()))
+
+	// 2. compute the width of the gutter containing line numbers
+	width := 0
+	if d.HasSpan() {
+		width = len(strconv.Itoa(d.Span.Start.LineNumber))
+	}
+	for _, label := range d.Labels {
+		width = max(width, len(strconv.Itoa(label.Span.Start.LineNumber)))
+	}
+	gutter := strings.Repeat(" ", width)
+
+	// 3. write the primary snippet and the labels
+	if d.HasSpan() {
+		r.renderSnippet(b, gutter, d.Span, "^", ansiRed, "")
+	}
+	for _, label := range d.Labels {
+		if label.Span.Start.FileName != d.Span.Start.FileName {
+			fmt.Fprintf(b, "%s %s %s\n", gutter, r.paint(ansiBlue, ":::"), label.Span.Start)
+		}
+		r.renderSnippet(b, gutter, label.Span, "-", ansiBlue, label.Message)
+	}
+
+	// 4. write the notes
+	for _, note := range d.Notes {
+		fmt.Fprintf(b, "%s %s %s\n", gutter, r.paint(ansiBlue, "="), r.paint(ansiBold, "note:")+" "+note)
+	}
+}
+
+// renderSnippet writes the first line of the given span underlining the
+// span with the given marker, style, and message. Spans covering many lines
+// are underlined until the end of their first line. If the source code
+// is not available, this method does not write anything.
+func (r *Renderer) renderSnippet(b *strings.Builder,
+	gutter string, span token.Span, marker, style, message string) {
+	// 1. obtain the offending line
+	line, found := r.line(span.Start.FileName, span.Start.LineNumber)
+	if !found {
+		return
+	}
+	runes := []rune(line)
+
+	// 2. compute the underlined columns, making sure we underline at least a
+	// column, which matters for the empty span of the EOF token
+	start := min(max(span.Start.LineColumn-1, 0), len(runes))
+	end := len(runes)
+	if span.End.LineNumber == span.Start.LineNumber {
+		end = min(max(span.End.LineColumn-1, 0), len(runes))
+	}
+	count := max(end-start, 1)
+
+	// 3. indent the markers preserving tabs so they are aligned with the line
+	var indent strings.Builder
+	for _, ch := range runes[:start] {
+		if ch == '\t' {
+			indent.WriteRune('\t')
+			continue
+		}
+		indent.WriteRune(' ')
+	}
+
+	// 4. write the snippet
+	bar := r.paint(ansiBlue, "|")
+	lineNumber := fmt.Sprintf("%*d", len(gutter), span.Start.LineNumber)
+	underline := r.paint(style, strings.Repeat(marker, count))
+	if message != "" {
+		underline += " " + r.paint(style, message)
+	}
+	fmt.Fprintf(b, "%s %s\n", gutter, bar)
+	fmt.Fprintf(b, "%s %s %s\n", r.paint(ansiBlue, lineNumber), bar, line)
+	fmt.Fprintf(b, "%s %s %s%s\n", gutter, bar, indent.String(), underline)
+}
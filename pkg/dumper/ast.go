@@ -56,10 +56,17 @@ func wrapNode(node ast.Node) ast.Node {
 			Type: "BlockExpr",
 			Value: &ast.BlockExpr{
 				Token: nx.Token,
+				End:   nx.End,
 				Exprs: wrappedExprs,
 			},
 		}
 
+	case *ast.BreakStmt:
+		return &nodeWrapper{
+			Type:  "BreakStmt",
+			Value: nx,
+		}
+
 	case *ast.CallExpr:
 		wrappedArgs := make([]ast.Node, len(nx.Args))
 		for i, arg := range nx.Args {
@@ -69,11 +76,18 @@ func wrapNode(node ast.Node) ast.Node {
 			Type: "CallExpr",
 			Value: &ast.CallExpr{
 				Token:    nx.Token,
+				End:      nx.End,
 				Callable: wrapNode(nx.Callable),
 				Args:     wrappedArgs,
 			},
 		}
 
+	case *ast.CharLiteral:
+		return &nodeWrapper{
+			Type:  "CharLiteral",
+			Value: nx,
+		}
+
 	case *ast.CondExpr:
 		wrappedCases := make([]ast.CondCase, len(nx.Cases))
 		for i, c := range nx.Cases {
@@ -86,16 +100,24 @@ func wrapNode(node ast.Node) ast.Node {
 			Type: "CondExpr",
 			Value: &ast.CondExpr{
 				Token:    nx.Token,
+				End:      nx.End,
 				Cases:    wrappedCases,
 				ElseExpr: wrapNode(nx.ElseExpr),
 			},
 		}
 
+	case *ast.ContinueStmt:
+		return &nodeWrapper{
+			Type:  "ContinueStmt",
+			Value: nx,
+		}
+
 	case *ast.DeclareExpr:
 		return &nodeWrapper{
 			Type: "DeclareExpr",
 			Value: &ast.DeclareExpr{
 				Token:  nx.Token,
+				End:    nx.End,
 				Symbol: nx.Symbol,
 				Expr:   wrapNode(nx.Expr),
 			},
@@ -106,6 +128,7 @@ func wrapNode(node ast.Node) ast.Node {
 			Type: "DefineExpr",
 			Value: &ast.DefineExpr{
 				Token:  nx.Token,
+				End:    nx.End,
 				Symbol: nx.Symbol,
 				Expr:   wrapNode(nx.Expr),
 			},
@@ -116,6 +139,17 @@ func wrapNode(node ast.Node) ast.Node {
 			Type: "EllipsisLiteral",
 			Value: &ast.EllipsisLiteral{
 				Token: nx.Token,
+				End:   nx.End,
+			},
+		}
+
+	case *ast.ErrorExpr:
+		return &nodeWrapper{
+			Type: "ErrorExpr",
+			Value: &ast.ErrorExpr{
+				Token: nx.Token,
+				End:   nx.End,
+				Err:   nx.Err,
 			},
 		}
 
@@ -131,37 +165,135 @@ func wrapNode(node ast.Node) ast.Node {
 			Value: nx,
 		}
 
+	case *ast.ForExpr:
+		return &nodeWrapper{
+			Type: "ForExpr",
+			Value: &ast.ForExpr{
+				Token:  nx.Token,
+				End:    nx.End,
+				Symbol: nx.Symbol,
+				From:   wrapNode(nx.From),
+				To:     wrapNode(nx.To),
+				Expr:   wrapNode(nx.Expr),
+			},
+		}
+
+	case *ast.ForeachExpr:
+		return &nodeWrapper{
+			Type: "ForeachExpr",
+			Value: &ast.ForeachExpr{
+				Token:  nx.Token,
+				End:    nx.End,
+				Symbol: nx.Symbol,
+				Seq:    wrapNode(nx.Seq),
+				Expr:   wrapNode(nx.Expr),
+			},
+		}
+
+	case *ast.ImportStmt:
+		return &nodeWrapper{
+			Type: "ImportStmt",
+			Value: &ast.ImportStmt{
+				Token:    nx.Token,
+				End:      nx.End,
+				FilePath: nx.FilePath,
+				Alias:    nx.Alias,
+				Nodes:    wrapNodes(nx.Nodes),
+			},
+		}
+
 	case *ast.IntLiteral:
 		return &nodeWrapper{
 			Type:  "IntLiteral",
 			Value: nx,
 		}
 
+	case *ast.KeywordLiteral:
+		return &nodeWrapper{
+			Type:  "KeywordLiteral",
+			Value: nx,
+		}
+
 	case *ast.LambdaExpr:
 		return &nodeWrapper{
 			Type: "LambdaExpr",
 			Value: &ast.LambdaExpr{
 				Token:  nx.Token,
+				End:    nx.End,
 				Params: nx.Params,
 				Docs:   nx.Docs,
 				Expr:   wrapNode(nx.Expr),
 			},
 		}
 
+	case *ast.LetExpr:
+		return &nodeWrapper{
+			Type: "LetExpr",
+			Value: &ast.LetExpr{
+				Token:    nx.Token,
+				End:      nx.End,
+				Bindings: wrapLetBindings(nx.Bindings),
+				Expr:     wrapNode(nx.Expr),
+			},
+		}
+	case *ast.LetStarExpr:
+		return &nodeWrapper{
+			Type: "LetStarExpr",
+			Value: &ast.LetStarExpr{
+				Token:    nx.Token,
+				End:      nx.End,
+				Bindings: wrapLetBindings(nx.Bindings),
+				Expr:     wrapNode(nx.Expr),
+			},
+		}
+	case *ast.LetrecExpr:
+		return &nodeWrapper{
+			Type: "LetrecExpr",
+			Value: &ast.LetrecExpr{
+				Token:    nx.Token,
+				End:      nx.End,
+				Bindings: wrapLetBindings(nx.Bindings),
+				Expr:     wrapNode(nx.Expr),
+			},
+		}
+	case *ast.ModuleStmt:
+		return &nodeWrapper{
+			Type:  "ModuleStmt",
+			Value: nx,
+		}
+
+	case *ast.QuasiquoteExpr:
+		return &nodeWrapper{
+			Type: "QuasiquoteExpr",
+			Value: &ast.QuasiquoteExpr{
+				Token: nx.Token,
+				End:   nx.End,
+				Expr:  wrapNode(nx.Expr),
+			},
+		}
+
 	case *ast.QuoteExpr:
 		return &nodeWrapper{
 			Type: "QuoteExpr",
 			Value: &ast.QuoteExpr{
 				Token: nx.Token,
+				End:   nx.End,
 				Expr:  wrapNode(nx.Expr),
 			},
 		}
 
+	case *ast.RationalLiteral:
+		return &nodeWrapper{
+			Type:  "RationalLiteral",
+			Value: nx,
+		}
+
 	case *ast.ReturnStmt:
 		return &nodeWrapper{
 			Type: "ReturnStmt",
 			Value: &ast.ReturnStmt{
 				Token: nx.Token,
+				End:   nx.End,
 				Expr:  wrapNode(nx.Expr),
 			},
 		}
@@ -171,6 +303,7 @@ func wrapNode(node ast.Node) ast.Node {
 			Type: "SetExpr",
 			Value: &ast.SetExpr{
 				Token:  nx.Token,
+				End:    nx.End,
 				Symbol: nx.Symbol,
 				Expr:   wrapNode(nx.Expr),
 			},
@@ -200,16 +333,38 @@ func wrapNode(node ast.Node) ast.Node {
 			Value: nx,
 		}
 
+	case *ast.UnquoteExpr:
+		return &nodeWrapper{
+			Type: "UnquoteExpr",
+			Value: &ast.UnquoteExpr{
+				Token:    nx.Token,
+				End:      nx.End,
+				Splicing: nx.Splicing,
+				Expr:     wrapNode(nx.Expr),
+			},
+		}
+
 	case *ast.WhileExpr:
 		return &nodeWrapper{
 			Type: "WhileExpr",
 			Value: &ast.WhileExpr{
 				Token:     nx.Token,
+				End:       nx.End,
 				Predicate: wrapNode(nx.Predicate),
 				Expr:      wrapNode(nx.Expr),
 			},
 		}
 
+	case *ast.YieldStmt:
+		return &nodeWrapper{
+			Type: "YieldStmt",
+			Value: &ast.YieldStmt{
+				Token: nx.Token,
+				End:   nx.End,
+				Expr:  wrapNode(nx.Expr),
+			},
+		}
+
 	default:
 		return &nodeWrapper{
 			Type:  "Unknown",
@@ -217,3 +372,15 @@ func wrapNode(node ast.Node) ast.Node {
 		}
 	}
 }
+
+// wrapLetBindings wraps the expressions of the given let bindings.
+func wrapLetBindings(bindings []ast.LetBinding) []ast.LetBinding {
+	wrappedBindings := make([]ast.LetBinding, len(bindings))
+	for i, b := range bindings {
+		wrappedBindings[i] = ast.LetBinding{
+			Symbol: b.Symbol,
+			Expr:   wrapNode(b.Expr),
+		}
+	}
+	return wrappedBindings
+}
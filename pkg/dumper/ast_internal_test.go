@@ -32,7 +32,7 @@ func TestNodeWrapper(t *testing.T) {
 		})
 
 		t.Run("MarshalJSON method", func(t *testing.T) {
-			expectedJSON := `{"Type":"IntLiteral","Value":{"Token":{"TokenPos":{"FileName":"","LineNumber":0,"LineColumn":0},"TokenType":"NUMBER","Value":"42"},"Value":"42"}}`
+			expectedJSON := `{"Type":"IntLiteral","Value":{"Token":{"TokenPos":{"FileName":"","LineNumber":0,"LineColumn":0,"Offset":0},"TokenType":"NUMBER","Value":"42","TokenEnd":{"FileName":"","LineNumber":0,"LineColumn":0,"Offset":0}},"End":{"FileName":"","LineNumber":0,"LineColumn":0,"Offset":0},"Value":"42"}}`
 			jsonBytes, err := json.Marshal(wrappedNode)
 			if err != nil {
 				t.Fatalf("failed to marshal JSON: %v", err)
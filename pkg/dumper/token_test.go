@@ -44,6 +44,35 @@ func TestDumpTokens(t *testing.T) {
 		}
 	})
 
+	t.Run("dumping tokens with trivia", func(t *testing.T) {
+		testCases, err := txtartesting.LoadTestCases(filepath.Join("testdata", "token_trivia"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.Name, func(t *testing.T) {
+				tokens, err := scanner.ScanWithTrivia("input.txt", bytes.NewReader([]byte(tc.Input)))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var buf bytes.Buffer
+				err = dumper.DumpTokens(&buf, tokens)
+				if err != nil {
+					t.Fatalf("failed to dump tokens: %v", err)
+				}
+				if !json.Valid(buf.Bytes()) {
+					t.Fatalf("invalid JSON output: %s", buf.String())
+				}
+
+				if err := tc.CompareTextOutput(buf.String()); err != nil {
+					t.Error(err)
+				}
+			})
+		}
+	})
+
 	t.Run("with writer error", func(t *testing.T) {
 		errWriter := &failingWriter{}
 		tokens := []token.Token{
@@ -40,6 +40,7 @@ import (
 	"github.com/bassosimone/buresu/pkg/ast"
 	"github.com/bassosimone/buresu/pkg/evaluator/simple"
 	"github.com/bassosimone/buresu/pkg/evaluator/visitor"
+	"github.com/bassosimone/buresu/pkg/includer"
 )
 
 // Value is the type of value returned by the evaluator.
@@ -48,11 +49,20 @@ type Value = visitor.Value
 // Environment is the execution environment used by the evaluator.
 type Environment = simple.Environment
 
+// Error is the error containing the token of the node that failed to evaluate.
+type Error = simple.Error
+
 // NewGlobalEnvironment creates a new global environment.
 func NewGlobalEnvironment(writer io.Writer) *Environment {
 	return simple.NewGlobalEnvironment(writer)
 }
 
+// LoadPrelude evaluates the standard library prelude, which we read from
+// the given standard library location using the given optional cache.
+func LoadPrelude(ctx context.Context, env *Environment, stdlib includer.Location, cache *includer.Cache) error {
+	return simple.LoadPrelude(ctx, env, stdlib, cache)
+}
+
 // Eval evaluates a node in the AST and returns the result.
 func Eval(ctx context.Context, env *Environment, node ast.Node) (Value, error) {
 	return visitor.Eval(ctx, env, node)
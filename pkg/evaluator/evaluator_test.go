@@ -4,9 +4,13 @@ package evaluator
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/parser"
+	"github.com/bassosimone/buresu/pkg/scanner"
 )
 
 func TestEval(t *testing.T) {
@@ -35,3 +39,22 @@ func TestEval(t *testing.T) {
 		t.Fatalf("expected true, got %v", result.String())
 	}
 }
+
+func TestErrorSpan(t *testing.T) {
+	tokens, err := scanner.Scan("input.brs", strings.NewReader("(block\n  (set! x (+ 1 2)))"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes, err := parser.Parse(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Eval(context.Background(), NewGlobalEnvironment(nil), nodes[0])
+	var eerr *Error
+	if !errors.As(err, &eerr) {
+		t.Fatalf("expected *Error, got %v", err)
+	}
+	if got := eerr.Span().String(); got != "input.brs:2:3-2:19" {
+		t.Fatalf("unexpected span: %s", got)
+	}
+}
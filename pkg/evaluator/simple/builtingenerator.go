@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package simple
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bassosimone/buresu/pkg/evaluator/visitor"
+)
+
+// NewBuiltInGenerator creates a new built-in function that creates
+// a generator running the given lambda, which takes no arguments, whose
+// coroutine is owned by the given environment.
+func NewBuiltInGenerator(env *Environment) *BuiltInFuncValue {
+	return &BuiltInFuncValue{
+		Name: "generator",
+		Fx: func(ctx context.Context, args ...visitor.Value) (visitor.Value, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("generator: %w", ErrWrongNumberOfArguments)
+			}
+			fn, ok := args[0].(visitor.Callable)
+			if !ok {
+				return nil, fmt.Errorf("generator: %w", ErrWrongArgumentType)
+			}
+			return NewGenerator(env, fn), nil
+		},
+	}
+}
+
+// NewBuiltInNext creates a new built-in function that returns the
+// next value yielded by a generator.
+func NewBuiltInNext() *BuiltInFuncValue {
+	return &BuiltInFuncValue{
+		Name: "next",
+		Fx: func(ctx context.Context, args ...visitor.Value) (visitor.Value, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("next: %w", ErrWrongNumberOfArguments)
+			}
+			gen, ok := args[0].(*Generator)
+			if !ok {
+				return nil, fmt.Errorf("next: %w", ErrWrongArgumentType)
+			}
+			value, ok, err := gen.Next(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return nil, fmt.Errorf("next: %w", ErrGeneratorExhausted)
+			}
+			return value, nil
+		},
+	}
+}
+
+// NewBuiltInDone creates a new built-in function that returns
+// whether a generator will not yield any other value.
+func NewBuiltInDone() *BuiltInFuncValue {
+	return &BuiltInFuncValue{
+		Name: "done?",
+		Fx: func(ctx context.Context, args ...visitor.Value) (visitor.Value, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("done?: %w", ErrWrongNumberOfArguments)
+			}
+			gen, ok := args[0].(*Generator)
+			if !ok {
+				return nil, fmt.Errorf("done?: %w", ErrWrongArgumentType)
+			}
+			done, err := gen.Done(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return &Bool{done}, nil
+		},
+	}
+}
@@ -0,0 +1,132 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package simple
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bassosimone/buresu/pkg/evaluator/visitor"
+)
+
+// NewBuiltInMakeMap creates a new built-in function that creates an empty map.
+func NewBuiltInMakeMap() *BuiltInFuncValue {
+	return &BuiltInFuncValue{
+		Name: "make-map",
+		Fx: func(ctx context.Context, args ...visitor.Value) (visitor.Value, error) {
+			if len(args) != 0 {
+				return nil, fmt.Errorf("make-map: %w", ErrWrongNumberOfArguments)
+			}
+			return NewHashMap(), nil
+		},
+	}
+}
+
+// unwrapMapAndKey unwraps the map and the key passed to a map built-in.
+func unwrapMapAndKey(name string, args []visitor.Value) (*HashMap, Hashable, error) {
+	hm, ok := args[0].(*HashMap)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s: %w", name, ErrWrongArgumentType)
+	}
+	key, ok := args[1].(Hashable)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s: %w", name, ErrWrongArgumentType)
+	}
+	return hm, key, nil
+}
+
+// NewBuiltInMapGet creates a new built-in function that returns
+// the value associated with the given key.
+func NewBuiltInMapGet() *BuiltInFuncValue {
+	return &BuiltInFuncValue{
+		Name: "map-get",
+		Fx: func(ctx context.Context, args ...visitor.Value) (visitor.Value, error) {
+			if len(args) != 2 {
+				return nil, fmt.Errorf("map-get: %w", ErrWrongNumberOfArguments)
+			}
+			hm, key, err := unwrapMapAndKey("map-get", args)
+			if err != nil {
+				return nil, err
+			}
+			value, err := hm.Get(key)
+			if err != nil {
+				return nil, fmt.Errorf("map-get: %w", err)
+			}
+			return value, nil
+		},
+	}
+}
+
+// NewBuiltInMapSet creates a new built-in function that associates
+// a value with the given key.
+func NewBuiltInMapSet() *BuiltInFuncValue {
+	return &BuiltInFuncValue{
+		Name: "map-set!",
+		Fx: func(ctx context.Context, args ...visitor.Value) (visitor.Value, error) {
+			if len(args) != 3 {
+				return nil, fmt.Errorf("map-set!: %w", ErrWrongNumberOfArguments)
+			}
+			hm, key, err := unwrapMapAndKey("map-set!", args)
+			if err != nil {
+				return nil, err
+			}
+			hm.Set(key, args[2])
+			return args[2], nil
+		},
+	}
+}
+
+// NewBuiltInMapHas creates a new built-in function that returns
+// whether the map contains the given key.
+func NewBuiltInMapHas() *BuiltInFuncValue {
+	return &BuiltInFuncValue{
+		Name: "map-has?",
+		Fx: func(ctx context.Context, args ...visitor.Value) (visitor.Value, error) {
+			if len(args) != 2 {
+				return nil, fmt.Errorf("map-has?: %w", ErrWrongNumberOfArguments)
+			}
+			hm, key, err := unwrapMapAndKey("map-has?", args)
+			if err != nil {
+				return nil, err
+			}
+			return &Bool{hm.Has(key)}, nil
+		},
+	}
+}
+
+// NewBuiltInMapDelete creates a new built-in function that removes
+// the given key from the map.
+func NewBuiltInMapDelete() *BuiltInFuncValue {
+	return &BuiltInFuncValue{
+		Name: "map-delete!",
+		Fx: func(ctx context.Context, args ...visitor.Value) (visitor.Value, error) {
+			if len(args) != 2 {
+				return nil, fmt.Errorf("map-delete!: %w", ErrWrongNumberOfArguments)
+			}
+			hm, key, err := unwrapMapAndKey("map-delete!", args)
+			if err != nil {
+				return nil, err
+			}
+			hm.Delete(key)
+			return &Unit{}, nil
+		},
+	}
+}
+
+// NewBuiltInMapKeys creates a new built-in function that returns
+// a vector containing the map keys in insertion order.
+func NewBuiltInMapKeys() *BuiltInFuncValue {
+	return &BuiltInFuncValue{
+		Name: "map-keys",
+		Fx: func(ctx context.Context, args ...visitor.Value) (visitor.Value, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("map-keys: %w", ErrWrongNumberOfArguments)
+			}
+			hm, ok := args[0].(*HashMap)
+			if !ok {
+				return nil, fmt.Errorf("map-keys: %w", ErrWrongArgumentType)
+			}
+			return &Vector{hm.Keys()}, nil
+		},
+	}
+}
@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package simple
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bassosimone/buresu/pkg/evaluator/visitor"
+)
+
+// MaxVectorSize is the maximum size of a vector created using make-vector,
+// which prevents a program from exhausting the memory using a single call.
+const MaxVectorSize = 1 << 24
+
+// NewBuiltInMakeVector creates a new built-in function that creates
+// a vector with the given size where each element is the given value.
+func NewBuiltInMakeVector() *BuiltInFuncValue {
+	return &BuiltInFuncValue{
+		Name: "make-vector",
+		Fx: func(ctx context.Context, args ...visitor.Value) (visitor.Value, error) {
+			if len(args) != 2 {
+				return nil, fmt.Errorf("make-vector: %w", ErrWrongNumberOfArguments)
+			}
+			size, ok := args[0].(*Int)
+			if !ok {
+				return nil, fmt.Errorf("make-vector: %w", ErrWrongArgumentType)
+			}
+			if size.Value < 0 {
+				return nil, fmt.Errorf("make-vector: negative size: %d", size.Value)
+			}
+			if size.Value > MaxVectorSize {
+				return nil, fmt.Errorf("make-vector: size too large: %d (maximum: %d)", size.Value, MaxVectorSize)
+			}
+			values := make([]visitor.Value, size.Value)
+			for idx := range values {
+				values[idx] = args[1]
+			}
+			return &Vector{values}, nil
+		},
+	}
+}
+
+// NewBuiltInVectorRef creates a new built-in function that returns
+// the element of a vector at the given index.
+func NewBuiltInVectorRef() *BuiltInFuncValue {
+	return &BuiltInFuncValue{
+		Name: "vector-ref",
+		Fx: func(ctx context.Context, args ...visitor.Value) (visitor.Value, error) {
+			if len(args) != 2 {
+				return nil, fmt.Errorf("vector-ref: %w", ErrWrongNumberOfArguments)
+			}
+			vec, ok := args[0].(*Vector)
+			if !ok {
+				return nil, fmt.Errorf("vector-ref: %w", ErrWrongArgumentType)
+			}
+			index, ok := args[1].(*Int)
+			if !ok {
+				return nil, fmt.Errorf("vector-ref: %w", ErrWrongArgumentType)
+			}
+			value, err := vec.Ref(index.Value)
+			if err != nil {
+				return nil, fmt.Errorf("vector-ref: %w", err)
+			}
+			return value, nil
+		},
+	}
+}
+
+// NewBuiltInVectorSet creates a new built-in function that sets
+// the element of a vector at the given index.
+func NewBuiltInVectorSet() *BuiltInFuncValue {
+	return &BuiltInFuncValue{
+		Name: "vector-set!",
+		Fx: func(ctx context.Context, args ...visitor.Value) (visitor.Value, error) {
+			if len(args) != 3 {
+				return nil, fmt.Errorf("vector-set!: %w", ErrWrongNumberOfArguments)
+			}
+			vec, ok := args[0].(*Vector)
+			if !ok {
+				return nil, fmt.Errorf("vector-set!: %w", ErrWrongArgumentType)
+			}
+			index, ok := args[1].(*Int)
+			if !ok {
+				return nil, fmt.Errorf("vector-set!: %w", ErrWrongArgumentType)
+			}
+			if err := vec.Set(index.Value, args[2]); err != nil {
+				return nil, fmt.Errorf("vector-set!: %w", err)
+			}
+			return args[2], nil
+		},
+	}
+}
+
+// NewBuiltInVectorLength creates a new built-in function that
+// returns the number of elements of a vector.
+func NewBuiltInVectorLength() *BuiltInFuncValue {
+	return &BuiltInFuncValue{
+		Name: "vector-length",
+		Fx: func(ctx context.Context, args ...visitor.Value) (visitor.Value, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("vector-length: %w", ErrWrongNumberOfArguments)
+			}
+			vec, ok := args[0].(*Vector)
+			if !ok {
+				return nil, fmt.Errorf("vector-length: %w", ErrWrongArgumentType)
+			}
+			return vec.Length()
+		},
+	}
+}
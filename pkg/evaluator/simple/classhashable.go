@@ -0,0 +1,15 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package simple
+
+import "github.com/bassosimone/buresu/pkg/evaluator/visitor"
+
+// Hashable is the hashable type class, whose values can be map keys.
+type Hashable interface {
+	// HashKey returns a comparable value identifying the receiver,
+	// which is such that values of distinct types never collide.
+	HashKey() any
+
+	// We also implement the visitor.Value interface.
+	visitor.Value
+}
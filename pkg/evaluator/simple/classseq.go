@@ -2,10 +2,42 @@
 
 package simple
 
-import "github.com/bassosimone/buresu/pkg/evaluator/visitor"
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	"github.com/bassosimone/buresu/pkg/evaluator/visitor"
+)
 
 // Seq is the sequence type class.
 type Seq interface {
 	// Length returns the length of the sequence.
 	Length() (visitor.Value, error)
+
+	// Elements returns an iterator over the elements of the sequence.
+	Elements() iter.Seq[visitor.Value]
+}
+
+// IterateValue implements [visitor.Environment].
+//
+// Besides the [Seq] values, we can also iterate over a [*Generator],
+// whose length we cannot know in advance.
+func (env *Environment) IterateValue(ctx context.Context, value visitor.Value) (iter.Seq2[visitor.Value, error], error) {
+	switch value := value.(type) {
+	case *Generator:
+		return value.All(ctx), nil
+
+	case Seq:
+		return func(yield func(visitor.Value, error) bool) {
+			for element := range value.Elements() {
+				if !yield(element, nil) {
+					return
+				}
+			}
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("expected a sequence value, got %s", typeName(value))
+	}
 }
@@ -15,6 +15,12 @@ import (
 const (
 	// environmentFlagScopeFunc indicates that the scope is a function scope.
 	environmentFlagScopeFunc = 1 << iota
+
+	// environmentFlagScopeGlobal indicates that the scope is the global scope
+	// of a program or of a module, whose parent is the library scope, which
+	// contains the built-in functions and the prelude, and whose symbols we
+	// cannot redefine (see [*Environment.lookupDefined]).
+	environmentFlagScopeGlobal
 )
 
 // Environment is the environment used by the simple evaluator.
@@ -31,6 +37,20 @@ type Environment struct {
 
 	// symbols contains the symbols defined in the current environment.
 	symbols map[string]visitor.Value
+
+	// definitions contains the spans of the nodes that defined the
+	// symbols in the current environment, when known.
+	definitions map[string]token.Span
+
+	// modules contains the imported modules indexed by file path.
+	//
+	// Only the root environment uses this field.
+	modules map[string]visitor.Environment
+
+	// generators contains the running coroutines of the generators.
+	//
+	// Only the root environment uses this field.
+	generators *generatorSet
 }
 
 // Environment implements [visitor.Environment].
@@ -39,9 +59,12 @@ var _ visitor.Environment = (*Environment)(nil)
 // NewEnvironment creates a new [*Environment] instance.
 func NewEnvironment() *Environment {
 	return &Environment{
-		flags:   0,
-		parent:  nil,
-		symbols: make(map[string]visitor.Value),
+		flags:       0,
+		parent:      nil,
+		symbols:     make(map[string]visitor.Value),
+		definitions: make(map[string]token.Span),
+		modules:     make(map[string]visitor.Environment),
+		generators:  newGeneratorSet(),
 	}
 }
 
@@ -55,12 +78,26 @@ func (env *Environment) PushBlockScope() visitor.Environment {
 	return env.pushScope(0)
 }
 
+// PushModuleScope implements [visitor.Environment].
+func (env *Environment) PushModuleScope() visitor.Environment {
+	return env.root().pushScope(environmentFlagScopeGlobal)
+}
+
+// root returns the root environment.
+func (env *Environment) root() *Environment {
+	for env.parent != nil {
+		env = env.parent
+	}
+	return env
+}
+
 // pushScope creates a new child environment with the given flags and returns it.
 func (env *Environment) pushScope(flags int) *Environment {
 	return &Environment{
-		flags:   flags,
-		parent:  env,
-		symbols: make(map[string]visitor.Value),
+		flags:       flags,
+		parent:      env,
+		symbols:     make(map[string]visitor.Value),
+		definitions: make(map[string]token.Span),
 	}
 }
 
@@ -81,15 +118,83 @@ func (env *Environment) GetValue(symbol string) (visitor.Value, error) {
 	return env.NewUnitValue(), fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
 }
 
+// GetModule implements [visitor.Environment].
+func (env *Environment) GetModule(filePath string) (visitor.Environment, bool) {
+	module, found := env.root().modules[filePath]
+	return module, found
+}
+
+// ErrModuleAlreadyDefined is the error returned when a module is already defined.
+var ErrModuleAlreadyDefined = errors.New("module already defined")
+
+// DefineModule implements [visitor.Environment].
+func (env *Environment) DefineModule(filePath string, module visitor.Environment) error {
+	root := env.root()
+	if _, found := root.modules[filePath]; found {
+		return fmt.Errorf("%w: %s", ErrModuleAlreadyDefined, filePath)
+	}
+	root.modules[filePath] = module
+	return nil
+}
+
 // ErrSymbolAlreadyDefined is the error returned when a symbol is already defined.
 var ErrSymbolAlreadyDefined = errors.New("symbol already defined")
 
+// SymbolAlreadyDefinedError is the [ErrSymbolAlreadyDefined] error
+// containing the span of the previous definition of the symbol.
+type SymbolAlreadyDefinedError struct {
+	// Symbol is the symbol that is already defined.
+	Symbol string
+
+	// Previous is the span of the previous definition, which
+	// is the zero value for built-in symbols.
+	Previous token.Span
+}
+
+// Error returns the error message.
+func (e *SymbolAlreadyDefinedError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrSymbolAlreadyDefined.Error(), e.Symbol)
+}
+
+// Unwrap returns [ErrSymbolAlreadyDefined].
+func (e *SymbolAlreadyDefinedError) Unwrap() error {
+	return ErrSymbolAlreadyDefined
+}
+
+// errSymbolAlreadyDefined returns the [*SymbolAlreadyDefinedError] for the given symbol.
+func (env *Environment) errSymbolAlreadyDefined(symbol string) error {
+	return &SymbolAlreadyDefinedError{Symbol: symbol, Previous: env.definitions[symbol]}
+}
+
+// lookupDefined returns the value of the given symbol if it is defined in the
+// current scope or, for global scopes, in the library scope, along with the
+// scope defining it, such that we cannot redefine built-ins and prelude symbols.
+func (env *Environment) lookupDefined(symbol string) (visitor.Value, *Environment, bool) {
+	if value, found := env.symbols[symbol]; found {
+		return value, env, true
+	}
+	if env.flags&environmentFlagScopeGlobal != 0 {
+		if value, found := env.parent.symbols[symbol]; found {
+			return value, env.parent, true
+		}
+	}
+	return nil, nil, false
+}
+
+// setDefinition records the span of the node defining the given symbol.
+func (env *Environment) setDefinition(node ast.Node, symbol string) {
+	if node != nil {
+		env.definitions[symbol] = ast.NodeSpan(node)
+	}
+}
+
 // DefineValue implements [visitor.Environment].
-func (env *Environment) DefineValue(symbol string, value visitor.Value) error {
-	if _, found := env.symbols[symbol]; found {
-		return fmt.Errorf("%w: %s", ErrSymbolAlreadyDefined, symbol)
+func (env *Environment) DefineValue(node ast.Node, symbol string, value visitor.Value) error {
+	if _, owner, found := env.lookupDefined(symbol); found {
+		return owner.errSymbolAlreadyDefined(symbol)
 	}
 	env.symbols[symbol] = value
+	env.setDefinition(node, symbol)
 	return nil
 }
 
@@ -117,12 +222,37 @@ func (env *Environment) EvalCallable(ctx context.Context, node ast.Node) (visito
 		return nil, err
 	}
 	if _, ok := callable.(visitor.Callable); !ok {
-		return nil, fmt.Errorf("expected a callable, got %T", callable)
+		return nil, fmt.Errorf("expected a callable, got %s", typeName(callable))
 	}
 	return callable.(visitor.Callable), nil
 }
 
+// Error is the error returned by [*Environment.WrapError], which
+// contains the token of the node that caused the error.
+type Error struct {
+	Tok token.Token
+	Err error
+
+	// End is the position right after the node that caused the error.
+	End token.Position
+}
+
+// Error returns the error message with file position details.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: interpreter: %s", e.Tok.TokenPos, e.Err.Error())
+}
+
+// Unwrap returns the wrapped error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Span returns the source code span of the node that caused the error.
+func (e *Error) Span() token.Span {
+	return token.Span{Start: e.Tok.TokenPos, End: e.End}
+}
+
 // WrapError implements [visitor.Environment].
-func (env *Environment) WrapError(tok token.Token, err error) error {
-	return fmt.Errorf("%s: interpreter: %w", tok.TokenPos, err)
+func (env *Environment) WrapError(node ast.Node, err error) error {
+	return &Error{Tok: ast.NodeToken(node), Err: err, End: ast.NodeSpan(node).End}
 }
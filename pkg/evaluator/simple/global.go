@@ -9,20 +9,39 @@ import (
 )
 
 // NewGlobalEnvironment creates a new global environment.
+//
+// The returned environment is the global scope of the program, whose parent
+// is the library environment, which contains the built-in functions and the
+// prelude. We use the library environment as the parent of modules, so they
+// cannot see the program's symbols, and programs and modules cannot redefine
+// its symbols.
 func NewGlobalEnvironment(writer io.Writer) *Environment {
 	env := NewEnvironment()
 
 	builtins := []*BuiltInFuncValue{
 		NewBuiltInAdd(),
 		NewBuiltInDisplay(writer),
+		NewBuiltInDone(),
+		NewBuiltInGenerator(env),
 		NewBuiltInGt(),
 		NewBuiltInLength(),
 		NewBuiltInLt(),
+		NewBuiltInMakeMap(),
+		NewBuiltInMakeVector(),
+		NewBuiltInMapDelete(),
+		NewBuiltInMapGet(),
+		NewBuiltInMapHas(),
+		NewBuiltInMapKeys(),
+		NewBuiltInMapSet(),
 		NewBuiltInMul(),
+		NewBuiltInNext(),
+		NewBuiltInVectorLength(),
+		NewBuiltInVectorRef(),
+		NewBuiltInVectorSet(),
 	}
 	for _, builtin := range builtins {
-		rtx.Must(env.DefineValue(builtin.Name, builtin))
+		rtx.Must(env.DefineValue(nil, builtin.Name, builtin))
 	}
 
-	return env
+	return env.pushScope(environmentFlagScopeGlobal)
 }
@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package simple
+
+import (
+	"context"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/includer"
+	"github.com/bassosimone/buresu/pkg/token"
+)
+
+// LoadPrelude evaluates the standard library prelude, which we read from
+// the given standard library location using the given optional cache,
+// inside the library environment of the given environment (see
+// [NewGlobalEnvironment]). We do not use the search path, so that
+// user directories cannot replace the prelude.
+func LoadPrelude(ctx context.Context, env *Environment, stdlib includer.Location, cache *includer.Cache) error {
+	// manually create the AST node for including the prelude
+	prelude := &ast.IncludeStmt{
+		Token: token.Token{
+			TokenPos: token.Position{
+				FileName:   "<prelude>",
+				LineNumber: 1,
+				LineColumn: 1,
+			},
+			TokenType: token.ATOM,
+			Value:     "include",
+		},
+		FilePath: "prelude.brs",
+	}
+	nodes := []ast.Node{prelude}
+
+	// use the includer to pull the nodes from the prelude file
+	nodes, err := includer.IncludeWithCache(cache, []includer.Location{stdlib}, nodes)
+	if err != nil {
+		return err
+	}
+
+	// evaluate the prelude nodes
+	for _, node := range nodes {
+		if _, err := Eval(ctx, env.root(), node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
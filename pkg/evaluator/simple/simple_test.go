@@ -5,15 +5,19 @@ package simple_test
 import (
 	"bytes"
 	"context"
+	"errors"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/bassosimone/buresu/internal/txtartesting"
 	"github.com/bassosimone/buresu/pkg/evaluator/simple"
 	"github.com/bassosimone/buresu/pkg/evaluator/visitor"
+	"github.com/bassosimone/buresu/pkg/includer"
 	"github.com/bassosimone/buresu/pkg/parser"
 	"github.com/bassosimone/buresu/pkg/scanner"
+	"github.com/bassosimone/buresu/stdlib"
 )
 
 func TestEval(t *testing.T) {
@@ -33,10 +37,17 @@ func TestEval(t *testing.T) {
 			if err != nil {
 				t.Fatalf("failed to parse input code: %v", err)
 			}
+			nodes, err = includer.Include([]includer.Location{includer.DirLocation("testdata")}, nodes)
+			if err != nil {
+				t.Fatalf("failed to include files: %v", err)
+			}
 
 			// Evaluate the parsed nodes
 			ctx := context.Background()
 			env := simple.NewGlobalEnvironment(os.Stdout)
+			if err := simple.LoadPrelude(ctx, env, stdlib.Location(), nil); err != nil {
+				t.Fatalf("failed to load the prelude: %v", err)
+			}
 			var (
 				results []string
 				result  visitor.Value
@@ -70,3 +81,100 @@ func TestEval(t *testing.T) {
 		})
 	}
 }
+
+func TestGeneratorContextCancellation(t *testing.T) {
+	const input = `(define gen (generator (lambda () (block
+    (yield! 1)
+    (while true ())
+))))
+(next gen)
+(next gen)`
+	tokens, err := scanner.Scan("input.code", strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes, err := parser.Parse(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+	defer cancel()
+	env := simple.NewGlobalEnvironment(os.Stdout)
+	for _, node := range nodes {
+		_, err = simple.Eval(ctx, env, node)
+		if err != nil {
+			break
+		}
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestGeneratorOutlivesTheContextOfTheFirstCall(t *testing.T) {
+	const input = `(define gen (generator (lambda () (block (yield! 1) (yield! 2)))))
+(next gen)
+(next gen)`
+	tokens, err := scanner.Scan("input.code", strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes, err := parser.Parse(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// like the REPL, use a distinct context for each form and cancel it
+	// once we have evaluated the form, which must not stop the generator
+	env := simple.NewGlobalEnvironment(os.Stdout)
+	var results []string
+	for _, node := range nodes {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		result, err := simple.Eval(ctx, env, node)
+		cancel()
+		if err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, result.String())
+	}
+	if got := strings.Join(results, " "); got != "<generator> 1 2" {
+		t.Fatalf("unexpected results: %s", got)
+	}
+}
+
+func TestStopGenerators(t *testing.T) {
+	const input = `(define gen (generator (lambda () (block (yield! 1) (yield! 2)))))
+(next gen)`
+	tokens, err := scanner.Scan("input.code", strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes, err := parser.Parse(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	env := simple.NewGlobalEnvironment(os.Stdout)
+	var result visitor.Value
+	for _, node := range nodes {
+		if result, err = simple.Eval(ctx, env, node); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if result.String() != "1" {
+		t.Fatalf("unexpected result: %s", result.String())
+	}
+
+	// stopping the generators stops the suspended coroutine, such that
+	// resuming the generator fails rather than yielding the next value
+	env.StopGenerators()
+	value, err := env.GetValue("gen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := value.(*simple.Generator).Next(ctx); !errors.Is(err, simple.ErrGeneratorStopped) {
+		t.Fatalf("expected %v, got %v", simple.ErrGeneratorStopped, err)
+	}
+}
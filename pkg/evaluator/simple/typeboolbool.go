@@ -12,7 +12,7 @@ import (
 func (env *Environment) UnwrapBoolValue(value visitor.Value) (bool, error) {
 	bv, ok := value.(*Bool)
 	if !ok {
-		return false, fmt.Errorf("expected a boolean value, got %T", value)
+		return false, fmt.Errorf("expected a boolean value, got %s", typeName(value))
 	}
 	return bv.Value, nil
 }
@@ -34,3 +34,11 @@ var _ visitor.Value = (*Bool)(nil)
 func (v *Bool) String() string {
 	return fmt.Sprintf("%t", v.Value)
 }
+
+// Ensure Bool implements [Hashable].
+var _ Hashable = (*Bool)(nil)
+
+// HashKey implements [Hashable].
+func (v *Bool) HashKey() any {
+	return *v
+}
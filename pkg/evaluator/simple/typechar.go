@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package simple
+
+import "github.com/bassosimone/buresu/pkg/evaluator/visitor"
+
+// NewCharValue implements [visitor.Environment].
+func (env *Environment) NewCharValue(value rune) visitor.Value {
+	return &Char{value}
+}
+
+// Char represents a character value.
+type Char struct {
+	Value rune
+}
+
+// Ensure Char implements [visitor.Value].
+var _ visitor.Value = (*Char)(nil)
+
+// String returns the character itself, like [*String.String] does.
+func (v *Char) String() string {
+	return string(v.Value)
+}
+
+// Ensure Char implements [Hashable].
+var _ Hashable = (*Char)(nil)
+
+// HashKey implements [Hashable].
+func (v *Char) HashKey() any {
+	return *v
+}
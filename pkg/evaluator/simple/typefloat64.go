@@ -67,3 +67,11 @@ func (v *Float64) Lt(other visitor.Value) (visitor.Value, error) {
 	}
 	return &Bool{v.Value < num.Value}, nil
 }
+
+// Ensure Float64 implements [Hashable].
+var _ Hashable = (*Float64)(nil)
+
+// HashKey implements [Hashable].
+func (v *Float64) HashKey() any {
+	return *v
+}
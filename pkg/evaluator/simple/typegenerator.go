@@ -0,0 +1,319 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package simple
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"sync"
+
+	"github.com/bassosimone/buresu/pkg/evaluator/visitor"
+)
+
+// ErrGeneratorExhausted is the error returned when asking for the
+// next value of a generator whose lambda has already returned.
+var ErrGeneratorExhausted = errors.New("generator is exhausted")
+
+// ErrGeneratorRunning is the error returned when the lambda of a
+// generator asks for the next value of the same generator.
+var ErrGeneratorRunning = errors.New("generator is already running")
+
+// ErrGeneratorStopped is the error returned when the goroutine
+// running the lambda of a generator terminated unexpectedly.
+var ErrGeneratorStopped = errors.New("generator stopped")
+
+// ErrYieldOutsideGenerator is the error returned when evaluating
+// a yield! statement inside a lambda not run by a generator.
+var ErrYieldOutsideGenerator = errors.New("yield! outside of generator")
+
+// Generator is a generator value, which runs a lambda taking no arguments
+// as a coroutine that suspends each time it evaluates a yield! statement.
+//
+// We run the lambda in a goroutine, which we start when the consumer asks
+// for the first value, and which takes turns with the consumer using
+// unbuffered channels, such that only one of them runs at any time.
+//
+// The goroutine runs until the lambda returns, so we register it with the
+// [*Environment] that created the generator, which stops the goroutines
+// of the generators the program did not exhaust when the program ends
+// (see [*Environment.StopGenerators]).
+//
+// Use [NewGenerator] to construct.
+type Generator struct {
+	// fn is the callable the generator runs.
+	fn visitor.Callable
+
+	// owner tracks the running coroutines of the environment.
+	owner *generatorSet
+
+	// co is the coroutine running fn, which is nil until we start it.
+	co *coroutine
+
+	// cancel stops the coroutine, which is nil until we start it.
+	cancel context.CancelFunc
+
+	// pending is the step we computed but the consumer did not consume.
+	pending *generatorStep
+
+	// running indicates whether the coroutine is running.
+	running bool
+
+	// done indicates whether the coroutine terminated.
+	done bool
+}
+
+// coroutine contains the channels used by the consumer of a generator and
+// by the goroutine running the lambda to hand over control to each other.
+type coroutine struct {
+	// ctx is the context of the goroutine, which we cancel to stop it.
+	ctx context.Context
+
+	// resume tells the goroutine to compute the next step.
+	resume chan struct{}
+
+	// steps carries the steps computed by the goroutine.
+	steps chan generatorStep
+
+	// dead is closed when the goroutine terminates.
+	dead chan struct{}
+}
+
+// generatorStep is the outcome of resuming a generator.
+type generatorStep struct {
+	// value is the yielded value.
+	value visitor.Value
+
+	// err is the error returned by the lambda.
+	err error
+
+	// done indicates that the lambda returned.
+	done bool
+}
+
+// coroutineKey is the context key of the [*coroutine] running a lambda.
+type coroutineKey struct{}
+
+// generatorSet contains the running coroutines of an environment, along
+// with the functions to stop them.
+type generatorSet struct {
+	// mu protects running.
+	mu sync.Mutex
+
+	// running maps each running coroutine to the function to stop it.
+	running map[*coroutine]context.CancelFunc
+}
+
+// newGeneratorSet creates a new empty [*generatorSet].
+func newGeneratorSet() *generatorSet {
+	return &generatorSet{running: make(map[*coroutine]context.CancelFunc)}
+}
+
+// add registers a running coroutine.
+func (gs *generatorSet) add(co *coroutine, cancel context.CancelFunc) {
+	gs.mu.Lock()
+	gs.running[co] = cancel
+	gs.mu.Unlock()
+}
+
+// remove unregisters a coroutine, which stopped.
+func (gs *generatorSet) remove(co *coroutine) {
+	gs.mu.Lock()
+	delete(gs.running, co)
+	gs.mu.Unlock()
+}
+
+// stopAll stops all the running coroutines.
+func (gs *generatorSet) stopAll() {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	for co, cancel := range gs.running {
+		cancel()
+		delete(gs.running, co)
+	}
+}
+
+// NewGenerator creates a new [*Generator] running the given callable, whose
+// coroutine is stopped by the given environment's [*Environment.StopGenerators].
+func NewGenerator(env *Environment, fn visitor.Callable) *Generator {
+	return &Generator{fn: fn, owner: env.root().generators}
+}
+
+// StopGenerators stops the goroutines running the lambdas of the generators
+// created using this environment or its scopes, which otherwise would block
+// forever when the program does not exhaust the generators. Call this method
+// when you do not need the environment anymore (e.g., at the end of a REPL
+// session). Resuming a stopped generator fails with [ErrGeneratorStopped].
+func (env *Environment) StopGenerators() {
+	env.root().generators.stopAll()
+}
+
+// Ensure Generator implements [visitor.Value].
+var _ visitor.Value = (*Generator)(nil)
+
+// String implements [visitor.Value].
+func (g *Generator) String() string {
+	return "<generator>"
+}
+
+// Next returns the next value yielded by the generator, blocking until the
+// lambda yields a value or returns, in which case the boolean is false.
+func (g *Generator) Next(ctx context.Context) (visitor.Value, bool, error) {
+	step, err := g.peek(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if step.done {
+		return nil, false, nil
+	}
+	g.pending = nil
+	return step.value, true, nil
+}
+
+// Done returns whether the generator will not yield any other value, which
+// requires running the lambda until it yields the value [*Generator.Next]
+// will return, or until the lambda returns.
+func (g *Generator) Done(ctx context.Context) (bool, error) {
+	step, err := g.peek(ctx)
+	if err != nil {
+		return false, err
+	}
+	return step.done, nil
+}
+
+// All returns an iterator over the values yielded by the generator.
+func (g *Generator) All(ctx context.Context) iter.Seq2[visitor.Value, error] {
+	return func(yield func(visitor.Value, error) bool) {
+		for {
+			value, ok, err := g.Next(ctx)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !ok || !yield(value, nil) {
+				return
+			}
+		}
+	}
+}
+
+// peek returns the pending step, resuming the coroutine if needed.
+func (g *Generator) peek(ctx context.Context) (*generatorStep, error) {
+	if g.pending == nil {
+		step, err := g.resume(ctx)
+		if err != nil {
+			return nil, err
+		}
+		g.pending = step
+	}
+	return g.pending, nil
+}
+
+// resume runs the coroutine until the lambda yields a value or returns.
+//
+// The coroutine does not depend on the context of the consumer, which
+// may change at each call (e.g., the REPL uses a context for each input),
+// hence we only honour the given context while the coroutine is running
+// on behalf of this call, in which case cancelling the context stops the
+// generator. When the lambda fails or we stop the generator, we return
+// the error and consider the generator done, so that we only report the
+// error once and later calls do not block.
+func (g *Generator) resume(ctx context.Context) (*generatorStep, error) {
+	switch {
+	case g.done:
+		return &generatorStep{done: true}, nil
+	case g.running:
+		return nil, ErrGeneratorRunning
+	case ctx.Err() != nil:
+		return nil, ctx.Err()
+	}
+
+	g.running = true
+	defer func() { g.running = false }()
+
+	// 1. start or resume the coroutine, which is suspended unless it died
+	if g.co == nil {
+		g.start(ctx)
+	} else {
+		if g.co.ctx.Err() != nil { // see [*Environment.StopGenerators]
+			return nil, g.stop(ErrGeneratorStopped)
+		}
+		select {
+		case g.co.resume <- struct{}{}:
+		case <-g.co.dead:
+			return nil, g.stop(ErrGeneratorStopped)
+		}
+	}
+
+	// 2. wait for the coroutine to yield a value or return
+	select {
+	case step := <-g.co.steps:
+		if step.err != nil {
+			return nil, g.stop(step.err)
+		}
+		if step.done {
+			g.stop(nil)
+		}
+		return &step, nil
+	case <-g.co.dead:
+		return nil, g.stop(ErrGeneratorStopped)
+	case <-ctx.Done():
+		return nil, g.stop(ctx.Err())
+	}
+}
+
+// start starts the goroutine running the lambda, using a context that
+// retains the values of the given one but not its cancellation.
+func (g *Generator) start(ctx context.Context) {
+	co := &coroutine{
+		resume: make(chan struct{}),
+		steps:  make(chan generatorStep),
+		dead:   make(chan struct{}),
+	}
+	ctx = context.WithValue(context.WithoutCancel(ctx), coroutineKey{}, co)
+	ctx, cancel := context.WithCancel(ctx)
+	co.ctx = ctx
+	g.co, g.cancel = co, cancel
+	g.owner.add(co, cancel)
+
+	go func() {
+		defer close(co.dead)
+		_, err := visitor.Call(ctx, g.fn)
+		select {
+		case co.steps <- generatorStep{err: err, done: true}:
+		case <-ctx.Done():
+		}
+	}()
+}
+
+// stop marks the generator as done, stops the coroutine and returns the err.
+func (g *Generator) stop(err error) error {
+	g.done = true
+	g.cancel()
+	g.owner.remove(g.co)
+	return err
+}
+
+// yield hands over the value to the consumer and waits to be resumed.
+func (co *coroutine) yield(ctx context.Context, value visitor.Value) error {
+	select {
+	case co.steps <- generatorStep{value: value}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-co.resume:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Yield implements [visitor.Environment].
+func (env *Environment) Yield(ctx context.Context, value visitor.Value) error {
+	co, ok := ctx.Value(coroutineKey{}).(*coroutine)
+	if !ok {
+		return ErrYieldOutsideGenerator
+	}
+	return co.yield(ctx, value)
+}
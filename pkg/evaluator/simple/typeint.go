@@ -8,6 +8,15 @@ import (
 	"github.com/bassosimone/buresu/pkg/evaluator/visitor"
 )
 
+// UnwrapIntValue unwraps an int value.
+func (env *Environment) UnwrapIntValue(value visitor.Value) (int, error) {
+	iv, ok := value.(*Int)
+	if !ok {
+		return 0, fmt.Errorf("expected an int value, got %s", typeName(value))
+	}
+	return iv.Value, nil
+}
+
 // NewIntValue implements [visitor.Environment].
 func (env *Environment) NewIntValue(value int) visitor.Value {
 	return &Int{value}
@@ -67,3 +76,11 @@ func (v *Int) Lt(other visitor.Value) (visitor.Value, error) {
 	}
 	return &Bool{v.Value < num.Value}, nil
 }
+
+// Ensure Int implements [Hashable].
+var _ Hashable = (*Int)(nil)
+
+// HashKey implements [Hashable].
+func (v *Int) HashKey() any {
+	return *v
+}
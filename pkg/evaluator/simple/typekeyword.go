@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package simple
+
+import "github.com/bassosimone/buresu/pkg/evaluator/visitor"
+
+// NewKeywordValue implements [visitor.Environment].
+func (env *Environment) NewKeywordValue(value string) visitor.Value {
+	return &Keyword{value}
+}
+
+// Keyword represents a self-evaluating keyword value (e.g., `:name`).
+type Keyword struct {
+	Value string
+}
+
+// Ensure Keyword implements [visitor.Value].
+var _ visitor.Value = (*Keyword)(nil)
+
+// String implements [visitor.Value].
+func (v *Keyword) String() string {
+	return v.Value
+}
+
+// Ensure Keyword implements [Hashable].
+var _ Hashable = (*Keyword)(nil)
+
+// HashKey implements [Hashable].
+func (v *Keyword) HashKey() any {
+	return *v
+}
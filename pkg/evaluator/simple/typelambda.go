@@ -42,7 +42,7 @@ func (lv *Lambda) Call(ctx context.Context, args ...visitor.Value) (visitor.Valu
 	for idx, arg := range args {
 		// the parser guarantees that params names are not duplicated
 		// so I do not see how this define could fail
-		rtx.Must(closure.DefineValue(lv.Node.Params[idx], arg))
+		rtx.Must(closure.DefineValue(lv.Node, lv.Node.Params[idx], arg))
 	}
 
 	// 3. evaluate the body of the lambda function in the new environment
@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package simple
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+
+	"github.com/bassosimone/buresu/pkg/evaluator/visitor"
+)
+
+// ErrKeyNotFound is the error returned when a map key does not exist.
+var ErrKeyNotFound = errors.New("key not found")
+
+// HashMap represents a mutable hash map from [Hashable] keys to values.
+//
+// Use [NewHashMap] to construct.
+type HashMap struct {
+	// entries maps the hash key to the corresponding entry.
+	entries map[any]hashMapEntry
+
+	// order contains the hash keys in insertion order.
+	order []any
+}
+
+// hashMapEntry is an entry inside a [HashMap].
+type hashMapEntry struct {
+	key   Hashable
+	value visitor.Value
+}
+
+// NewHashMap creates a new empty [*HashMap].
+func NewHashMap() *HashMap {
+	return &HashMap{
+		entries: make(map[any]hashMapEntry),
+		order:   []any{},
+	}
+}
+
+// Ensure HashMap implements [visitor.Value].
+var _ visitor.Value = (*HashMap)(nil)
+
+// String implements [visitor.Value].
+func (m *HashMap) String() string {
+	entries := make([]string, 0, len(m.order))
+	for _, hk := range m.order {
+		entry := m.entries[hk]
+		entries = append(entries, fmt.Sprintf("(%s %s)", entry.key.String(), entry.value.String()))
+	}
+	if len(entries) <= 0 {
+		return "(map)"
+	}
+	return fmt.Sprintf("(map %s)", strings.Join(entries, " "))
+}
+
+// Ensure HashMap implements [Seq].
+var _ Seq = (*HashMap)(nil)
+
+// Length implements [Seq].
+func (m *HashMap) Length() (visitor.Value, error) {
+	return &Int{len(m.entries)}, nil
+}
+
+// Elements implements [Seq] and yields the keys in insertion order, skipping
+// the keys deleted while iterating and ignoring the ones added meanwhile.
+func (m *HashMap) Elements() iter.Seq[visitor.Value] {
+	return func(yield func(visitor.Value) bool) {
+		for _, hk := range slices.Clone(m.order) {
+			entry, found := m.entries[hk]
+			if !found {
+				continue
+			}
+			if !yield(entry.key) {
+				return
+			}
+		}
+	}
+}
+
+// Get returns the value associated with the given key.
+func (m *HashMap) Get(key Hashable) (visitor.Value, error) {
+	entry, found := m.entries[key.HashKey()]
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, key.String())
+	}
+	return entry.value, nil
+}
+
+// Set associates the given value with the given key.
+func (m *HashMap) Set(key Hashable, value visitor.Value) {
+	hk := key.HashKey()
+	if _, found := m.entries[hk]; !found {
+		m.order = append(m.order, hk)
+	}
+	m.entries[hk] = hashMapEntry{key: key, value: value}
+}
+
+// Has returns whether the map contains the given key.
+func (m *HashMap) Has(key Hashable) bool {
+	_, found := m.entries[key.HashKey()]
+	return found
+}
+
+// Delete removes the given key from the map, if present.
+func (m *HashMap) Delete(key Hashable) {
+	hk := key.HashKey()
+	if _, found := m.entries[hk]; !found {
+		return
+	}
+	delete(m.entries, hk)
+	m.order = slices.DeleteFunc(m.order, func(entry any) bool {
+		return entry == hk
+	})
+}
+
+// Keys returns the map keys in insertion order.
+func (m *HashMap) Keys() []visitor.Value {
+	keys := make([]visitor.Value, 0, len(m.order))
+	for _, hk := range m.order {
+		keys = append(keys, m.entries[hk].key)
+	}
+	return keys
+}
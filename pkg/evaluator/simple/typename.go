@@ -0,0 +1,40 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package simple
+
+import "github.com/bassosimone/buresu/pkg/evaluator/visitor"
+
+// typeName returns the name of the type of the given value, using
+// the same names used by the typechecker and by type annotations.
+func typeName(value visitor.Value) string {
+	switch value.(type) {
+	case *Bool:
+		return "Bool"
+	case *BuiltInFuncValue, *Lambda:
+		return "Callable"
+	case *Char:
+		return "Char"
+	case *Float64:
+		return "Float64"
+	case *Generator:
+		return "Generator"
+	case *Int:
+		return "Int"
+	case *Keyword:
+		return "Keyword"
+	case *HashMap:
+		return "Map"
+	case *Quoted:
+		return "Quoted"
+	case *Rational:
+		return "Rational"
+	case *String:
+		return "String"
+	case *Unit:
+		return "Unit"
+	case *Vector:
+		return "Vector"
+	default:
+		return "Any"
+	}
+}
@@ -3,10 +3,15 @@
 package simple
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
 
 	"github.com/bassosimone/buresu/pkg/ast"
 	"github.com/bassosimone/buresu/pkg/evaluator/visitor"
+	"github.com/bassosimone/buresu/pkg/token"
 )
 
 // NewQuotedValue implements [visitor.Environment].
@@ -23,6 +28,93 @@ type Quoted struct {
 var _ visitor.Value = (*Quoted)(nil)
 
 // String implements Value.
+//
+// We always use the `'x` shorthand, such that `'x`, `(quote x)` and the
+// result of a quasiquote print in the same way.
 func (q *Quoted) String() string {
-	return fmt.Sprintf("%s", q.Value.String())
+	return "'" + q.Value.Expr.String()
+}
+
+// ErrCannotQuote is the error returned when we cannot insert
+// a value into a quasiquote (e.g., because it's a lambda).
+var ErrCannotQuote = errors.New("cannot quote value")
+
+// ErrCannotSplice is the error returned when we cannot splice a
+// value into a quasiquote because it's not a sequence.
+var ErrCannotSplice = errors.New("cannot splice value")
+
+// QuoteValue implements [visitor.Environment].
+func (env *Environment) QuoteValue(value visitor.Value) (ast.Node, error) {
+	switch value := value.(type) {
+	case *Bool:
+		tok := token.Token{TokenType: token.ATOM, Value: value.String()}
+		if value.Value {
+			return &ast.TrueLiteral{Token: tok}, nil
+		}
+		return &ast.FalseLiteral{Token: tok}, nil
+
+	case *Char:
+		return &ast.CharLiteral{Token: token.Token{TokenType: token.CHAR}, Value: value.Value}, nil
+
+	case *Float64:
+		if math.IsInf(value.Value, 0) || math.IsNaN(value.Value) {
+			return nil, fmt.Errorf("%w: %f", ErrCannotQuote, value.Value)
+		}
+		text := strconv.FormatFloat(value.Value, 'g', -1, 64)
+		if !strings.ContainsAny(text, ".e") {
+			text += ".0" // make sure we parse it as a float
+		}
+		return &ast.FloatLiteral{Token: token.Token{TokenType: token.NUMBER, Value: text}, Value: text}, nil
+
+	case *Int:
+		text := value.String()
+		return &ast.IntLiteral{Token: token.Token{TokenType: token.NUMBER, Value: text}, Value: text}, nil
+
+	case *Keyword:
+		return &ast.KeywordLiteral{Token: token.Token{TokenType: token.KEYWORD, Value: value.Value}, Value: value.Value}, nil
+
+	case *Quoted:
+		return value.Value.Expr, nil
+
+	case *Rational:
+		text := value.Value.Num().String() + "/" + value.Value.Denom().String()
+		return &ast.RationalLiteral{Token: token.Token{TokenType: token.NUMBER, Value: text}, Value: text}, nil
+
+	case *String:
+		return &ast.StringLiteral{Token: token.Token{TokenType: token.STRING}, Value: value.Value}, nil
+
+	case *Unit:
+		return &ast.UnitExpr{Token: token.Token{TokenType: token.OPEN, Value: "("}}, nil
+
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrCannotQuote, value.String())
+	}
+}
+
+// QuoteValues implements [visitor.Environment].
+func (env *Environment) QuoteValues(value visitor.Value) ([]ast.Node, error) {
+	switch value := value.(type) {
+	case *Quoted:
+		switch expr := value.Value.Expr.(type) {
+		case *ast.CallExpr:
+			return append([]ast.Node{expr.Callable}, expr.Args...), nil
+		case *ast.UnitExpr:
+			return nil, nil
+		}
+
+	case *Unit:
+		return nil, nil
+
+	case *Vector:
+		nodes := make([]ast.Node, 0, len(value.Values))
+		for _, element := range value.Values {
+			node, err := env.QuoteValue(element)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, node)
+		}
+		return nodes, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrCannotSplice, value.String())
 }
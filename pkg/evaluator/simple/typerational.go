@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package simple
+
+import (
+	"math/big"
+
+	"github.com/bassosimone/buresu/pkg/evaluator/visitor"
+)
+
+// NewRationalValue implements [visitor.Environment].
+func (env *Environment) NewRationalValue(value *big.Rat) visitor.Value {
+	return &Rational{value}
+}
+
+// Rational represents a rational value, which is always normalized
+// such that the numerator and the denominator are coprime.
+type Rational struct {
+	Value *big.Rat
+}
+
+// Ensure Rational implements [visitor.Value].
+var _ visitor.Value = (*Rational)(nil)
+
+// String implements [visitor.Value].
+func (v *Rational) String() string {
+	return v.Value.RatString()
+}
+
+// Ensure Rational implements [Num].
+var _ Num = (*Rational)(nil)
+
+// Add implements Num.
+func (v *Rational) Add(other visitor.Value) (visitor.Value, error) {
+	num, ok := other.(*Rational)
+	if !ok {
+		return nil, ErrWrongArgumentType
+	}
+	return &Rational{new(big.Rat).Add(v.Value, num.Value)}, nil
+}
+
+// Mul implements Num.
+func (v *Rational) Mul(other visitor.Value) (visitor.Value, error) {
+	num, ok := other.(*Rational)
+	if !ok {
+		return nil, ErrWrongArgumentType
+	}
+	return &Rational{new(big.Rat).Mul(v.Value, num.Value)}, nil
+}
+
+// Ensure Rational implements [Ord].
+var _ Ord = (*Rational)(nil)
+
+// Gt implements Ord.
+func (v *Rational) Gt(other visitor.Value) (visitor.Value, error) {
+	num, ok := other.(*Rational)
+	if !ok {
+		return nil, ErrWrongArgumentType
+	}
+	return &Bool{v.Value.Cmp(num.Value) > 0}, nil
+}
+
+// Lt implements Ord.
+func (v *Rational) Lt(other visitor.Value) (visitor.Value, error) {
+	num, ok := other.(*Rational)
+	if !ok {
+		return nil, ErrWrongArgumentType
+	}
+	return &Bool{v.Value.Cmp(num.Value) < 0}, nil
+}
+
+// Ensure Rational implements [Hashable].
+var _ Hashable = (*Rational)(nil)
+
+// rationalKey is the [Hashable] key of a [*Rational].
+type rationalKey struct {
+	value string
+}
+
+// HashKey implements [Hashable].
+func (v *Rational) HashKey() any {
+	return rationalKey{v.Value.RatString()}
+}
@@ -2,7 +2,11 @@
 
 package simple
 
-import "github.com/bassosimone/buresu/pkg/evaluator/visitor"
+import (
+	"iter"
+
+	"github.com/bassosimone/buresu/pkg/evaluator/visitor"
+)
 
 // NewStringValue implements [visitor.Environment].
 func (env *Environment) NewStringValue(value string) visitor.Value {
@@ -29,3 +33,22 @@ var _ Seq = (*String)(nil)
 func (v *String) Length() (visitor.Value, error) {
 	return &Int{len(v.Value)}, nil
 }
+
+// Elements implements [Seq] and yields the characters of the string.
+func (v *String) Elements() iter.Seq[visitor.Value] {
+	return func(yield func(visitor.Value) bool) {
+		for _, r := range v.Value {
+			if !yield(&Char{r}) {
+				return
+			}
+		}
+	}
+}
+
+// Ensure String implements [Hashable].
+var _ Hashable = (*String)(nil)
+
+// HashKey implements [Hashable].
+func (v *String) HashKey() any {
+	return *v
+}
@@ -2,7 +2,11 @@
 
 package simple
 
-import "github.com/bassosimone/buresu/pkg/evaluator/visitor"
+import (
+	"iter"
+
+	"github.com/bassosimone/buresu/pkg/evaluator/visitor"
+)
 
 // NewUnitValue implements [visitor.Environment].
 func (env *Environment) NewUnitValue() visitor.Value {
@@ -27,3 +31,16 @@ var _ Seq = (*Unit)(nil)
 func (*Unit) Length() (visitor.Value, error) {
 	return &Int{0}, nil
 }
+
+// Elements implements [Seq].
+func (*Unit) Elements() iter.Seq[visitor.Value] {
+	return func(yield func(visitor.Value) bool) {}
+}
+
+// Ensure Unit implements [Hashable].
+var _ Hashable = (*Unit)(nil)
+
+// HashKey implements [Hashable].
+func (v *Unit) HashKey() any {
+	return *v
+}
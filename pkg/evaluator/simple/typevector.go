@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package simple
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"strings"
+
+	"github.com/bassosimone/buresu/pkg/evaluator/visitor"
+)
+
+// ErrIndexOutOfRange is the error returned when a vector index is out of range.
+var ErrIndexOutOfRange = errors.New("index out of range")
+
+// Vector represents a mutable, fixed-size vector of values.
+type Vector struct {
+	Values []visitor.Value
+}
+
+// Ensure Vector implements [visitor.Value].
+var _ visitor.Value = (*Vector)(nil)
+
+// String implements [visitor.Value].
+func (v *Vector) String() string {
+	values := make([]string, 0, len(v.Values))
+	for _, value := range v.Values {
+		values = append(values, value.String())
+	}
+	if len(values) <= 0 {
+		return "(vector)"
+	}
+	return fmt.Sprintf("(vector %s)", strings.Join(values, " "))
+}
+
+// Ensure Vector implements [Seq].
+var _ Seq = (*Vector)(nil)
+
+// Length implements [Seq].
+func (v *Vector) Length() (visitor.Value, error) {
+	return &Int{len(v.Values)}, nil
+}
+
+// Elements implements [Seq].
+func (v *Vector) Elements() iter.Seq[visitor.Value] {
+	return func(yield func(visitor.Value) bool) {
+		for _, value := range v.Values {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// Ref returns the value at the given index.
+func (v *Vector) Ref(index int) (visitor.Value, error) {
+	if index < 0 || index >= len(v.Values) {
+		return nil, fmt.Errorf("%w: %d", ErrIndexOutOfRange, index)
+	}
+	return v.Values[index], nil
+}
+
+// Set sets the value at the given index.
+func (v *Vector) Set(index int, value visitor.Value) error {
+	if index < 0 || index >= len(v.Values) {
+		return fmt.Errorf("%w: %d", ErrIndexOutOfRange, index)
+	}
+	v.Values[index] = value
+	return nil
+}
@@ -0,0 +1,20 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+)
+
+type errBreak struct{}
+
+func (errBreak) Error() string {
+	return "break statement"
+}
+
+func evalBreakStmt(ctx context.Context, env Environment, node *ast.BreakStmt) (Value, error) {
+	// the parser guarantees that a break! statement only happens inside a while loop
+	return nil, &errBreak{}
+}
@@ -0,0 +1,30 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/token"
+)
+
+func TestEvalBreakStmt(t *testing.T) {
+	ctx := context.Background()
+	env := NewMockEnvironment()
+
+	t.Run("break inside while", func(t *testing.T) {
+		brk := &ast.BreakStmt{
+			Token: token.Token{TokenType: token.ATOM, Value: "break!"},
+		}
+
+		// Evaluate the break statement
+		_, err := evalBreakStmt(ctx, env, brk)
+
+		// Check if the error is of type errBreak
+		if _, ok := err.(*errBreak); !ok {
+			t.Errorf("expected errBreak, got %T", err)
+		}
+	})
+}
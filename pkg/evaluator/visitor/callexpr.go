@@ -7,6 +7,7 @@ import (
 	"errors"
 
 	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/token"
 )
 
 // Callable drefines the traits shared by all callables.
@@ -32,11 +33,30 @@ func evalCallExpr(ctx context.Context, env Environment, node *ast.CallExpr) (Val
 	// 2. fetch and invoke the callable
 	callable, err := env.EvalCallable(ctx, node.Callable)
 	if err != nil {
-		return nil, err
+		return nil, wrapCallError(env, node, err)
 	}
-	result, err := callable.Call(ctx, args...)
+	result, err := Call(ctx, callable, args...)
+	if err != nil {
+		return nil, wrapCallError(env, node, err)
+	}
+	return result, nil
+}
 
-	// 3. handle early return
+// wrapCallError wraps the given error using the position of the call unless
+// the error already contains a position (e.g., because it occurred inside the
+// body of the callee), such that we report the innermost failing expression.
+func wrapCallError(env Environment, node *ast.CallExpr, err error) error {
+	var spanner interface{ Span() token.Span }
+	if errors.As(err, &spanner) {
+		return err
+	}
+	return env.WrapError(node, err)
+}
+
+// Call invokes the given callable with the given arguments, handling
+// early returns, which is what built-ins taking callables should use.
+func Call(ctx context.Context, callable Callable, args ...Value) (Value, error) {
+	result, err := callable.Call(ctx, args...)
 	var retErr *errReturn
 	if errors.As(err, &retErr) {
 		return retErr.value, nil
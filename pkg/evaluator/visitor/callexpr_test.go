@@ -15,7 +15,7 @@ func TestEvalCallExpr(t *testing.T) {
 	env := NewMockEnvironment()
 
 	// Define the callable function
-	env.DefineValue("myFunction", NewMockCallable(func(ctx context.Context, args ...Value) (Value, error) {
+	env.DefineValue(nil, "myFunction", NewMockCallable(func(ctx context.Context, args ...Value) (Value, error) {
 		return env.NewIntValue(42), nil
 	}))
 
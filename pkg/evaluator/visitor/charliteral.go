@@ -0,0 +1,13 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+)
+
+func evalCharLiteral(_ context.Context, env Environment, node *ast.CharLiteral) (Value, error) {
+	return env.NewCharValue(node.Value), nil
+}
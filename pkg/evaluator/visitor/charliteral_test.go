@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/token"
+)
+
+func TestEvalCharLiteral(t *testing.T) {
+	ctx := context.Background()
+	env := NewMockEnvironment()
+	chrLiteral := &ast.CharLiteral{
+		Token: token.Token{TokenType: token.CHAR, Value: "a", Source: `#\a`},
+		Value: 'a',
+	}
+	result, err := evalCharLiteral(ctx, env, chrLiteral)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	expected := env.NewCharValue('a')
+	if result.String() != expected.String() {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
@@ -16,7 +16,7 @@ func evalCondExpr(ctx context.Context, env Environment, node *ast.CondExpr) (Val
 		}
 		condition, err := env.UnwrapBoolValue(expr)
 		if err != nil {
-			return nil, env.WrapError(node.Token, err)
+			return nil, env.WrapError(node, err)
 		}
 		if condition {
 			return Eval(ctx, env, condCase.Expr)
@@ -0,0 +1,20 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+)
+
+type errContinue struct{}
+
+func (errContinue) Error() string {
+	return "continue statement"
+}
+
+func evalContinueStmt(ctx context.Context, env Environment, node *ast.ContinueStmt) (Value, error) {
+	// the parser guarantees that a continue! statement only happens inside a while loop
+	return nil, &errContinue{}
+}
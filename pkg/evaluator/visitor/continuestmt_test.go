@@ -0,0 +1,30 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/token"
+)
+
+func TestEvalContinueStmt(t *testing.T) {
+	ctx := context.Background()
+	env := NewMockEnvironment()
+
+	t.Run("continue inside while", func(t *testing.T) {
+		cont := &ast.ContinueStmt{
+			Token: token.Token{TokenType: token.ATOM, Value: "continue!"},
+		}
+
+		// Evaluate the continue statement
+		_, err := evalContinueStmt(ctx, env, cont)
+
+		// Check if the error is of type errContinue
+		if _, ok := err.(*errContinue); !ok {
+			t.Errorf("expected errContinue, got %T", err)
+		}
+	})
+}
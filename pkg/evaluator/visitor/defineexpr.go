@@ -14,8 +14,8 @@ func evalDefineExpr(ctx context.Context, env Environment, node *ast.DefineExpr)
 	if err != nil {
 		return nil, err
 	}
-	if err := env.DefineValue(node.Symbol, value); err != nil {
-		return nil, env.WrapError(node.Token, err)
+	if err := env.DefineValue(node, node.Symbol, value); err != nil {
+		return nil, env.WrapError(node, err)
 	}
 	return value, nil
 }
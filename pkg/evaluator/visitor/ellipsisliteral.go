@@ -10,5 +10,5 @@ import (
 )
 
 func evalEllipsisLiteral(_ context.Context, env Environment, node *ast.EllipsisLiteral) (Value, error) {
-	return nil, env.WrapError(node.Token, errors.New("ellipsis cannot be used as a value"))
+	return nil, env.WrapError(node, errors.New("ellipsis cannot be used as a value"))
 }
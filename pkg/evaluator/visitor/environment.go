@@ -4,30 +4,52 @@ package visitor
 
 import (
 	"context"
+	"iter"
+	"math/big"
 
 	"github.com/bassosimone/buresu/pkg/ast"
-	"github.com/bassosimone/buresu/pkg/token"
 )
 
 // Environment is the generic interface for the environment.
 type Environment interface {
-	// DefineValue defines a new symbol in the current environment.
-	DefineValue(symbol string, value Value) error
+	// DefineModule saves the environment of the module imported from
+	// the given file path, so that we evaluate each module only once.
+	DefineModule(filePath string, module Environment) error
+
+	// DefineValue defines a new symbol in the current environment. The node
+	// is the one defining the symbol, which is nil for built-in symbols.
+	DefineValue(node ast.Node, symbol string, value Value) error
 
 	// EvalCallable attempts to evaluate a node as a callable. Generally, this
 	// takes one of two forms: a lambda expression invoked inline or a symbol
 	// that was previously defined as a lambda expression.
 	EvalCallable(ctx context.Context, node ast.Node) (Callable, error)
 
+	// GetModule returns the environment of the module imported from the
+	// given file path, if we have already evaluated such a module.
+	GetModule(filePath string) (Environment, bool)
+
 	// GetValue returns the value associated with the given symbol.
 	//
 	// If the symbol is not found in the current environment, the parent
 	// environments are searched recursively.
 	GetValue(symbol string) (Value, error)
 
+	// IterateValue returns an iterator over the elements of the given
+	// sequence value, which we use to evaluate foreach loops. The iterator
+	// yields an error when computing the next element fails (e.g., when
+	// the value is a generator whose lambda fails).
+	IterateValue(ctx context.Context, value Value) (iter.Seq2[Value, error], error)
+
 	// NewBoolValue returns a new bool value instance.
 	NewBoolValue(value bool) Value
 
+	// NewCharValue returns a new char value instance.
+	NewCharValue(value rune) Value
+
+	// NewKeywordValue returns a new keyword value instance.
+	NewKeywordValue(value string) Value
+
 	// NewLambdaValue returns a new lambda instance.
 	NewLambdaValue(node *ast.LambdaExpr) Value
 
@@ -40,6 +62,9 @@ type Environment interface {
 	// NewQuotedValue returns a new quoted value instance.
 	NewQuotedValue(node *ast.QuoteExpr) Value
 
+	// NewRationalValue returns a new rational value instance.
+	NewRationalValue(value *big.Rat) Value
+
 	// NewStringValue returns a new string value instance.
 	NewStringValue(value string) Value
 
@@ -56,6 +81,20 @@ type Environment interface {
 	// use the current environment as its parent.
 	PushFunctionScope() Environment
 
+	// PushModuleScope creates a new environment for evaluating a module
+	// and returns it. The returned environment will use the root environment
+	// as its parent, so that modules cannot see the importer's symbols.
+	PushModuleScope() Environment
+
+	// QuoteValue returns the AST node representing the given value, which
+	// we use to insert the value of an unquote into a quasiquote.
+	QuoteValue(value Value) (ast.Node, error)
+
+	// QuoteValues returns the AST nodes representing the elements of the
+	// given sequence value, which we use to splice the value of an
+	// unquote-splicing into the enclosing list of a quasiquote.
+	QuoteValues(value Value) ([]ast.Node, error)
+
 	// SetValue sets the value of an existing symbol in the current environment.
 	SetValue(symbol string, value Value) error
 
@@ -63,6 +102,15 @@ type Environment interface {
 	// returns either the unwrapped value of an error.
 	UnwrapBoolValue(value Value) (bool, error)
 
-	// WrapError wraps an error adding contextual token information.
-	WrapError(tok token.Token, err error) error
+	// UnwrapIntValue attempts to unwrap an Int from a Value and
+	// returns either the unwrapped value or an error.
+	UnwrapIntValue(value Value) (int, error)
+
+	// WrapError wraps an error adding the source code span of the given node.
+	WrapError(node ast.Node, err error) error
+
+	// Yield hands over the given value to the consumer of the generator
+	// running the current lambda and waits until the consumer asks for
+	// the next value, or returns an error if there is no such generator.
+	Yield(ctx context.Context, value Value) error
 }
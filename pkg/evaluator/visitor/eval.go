@@ -21,12 +21,21 @@ func Eval(ctx context.Context, env Environment, node ast.Node) (Value, error) {
 	case *ast.BlockExpr:
 		return evalBlockExpr(ctx, env, node)
 
+	case *ast.BreakStmt:
+		return evalBreakStmt(ctx, env, node)
+
 	case *ast.CallExpr:
 		return evalCallExpr(ctx, env, node)
 
+	case *ast.CharLiteral:
+		return evalCharLiteral(ctx, env, node)
+
 	case *ast.CondExpr:
 		return evalCondExpr(ctx, env, node)
 
+	case *ast.ContinueStmt:
+		return evalContinueStmt(ctx, env, node)
+
 	case *ast.DeclareExpr:
 		return evalDeclareExpr(ctx, env, node)
 
@@ -42,15 +51,45 @@ func Eval(ctx context.Context, env Environment, node ast.Node) (Value, error) {
 	case *ast.FloatLiteral:
 		return evalFloatLiteral(ctx, env, node)
 
+	case *ast.ForExpr:
+		return evalForExpr(ctx, env, node)
+
+	case *ast.ForeachExpr:
+		return evalForeachExpr(ctx, env, node)
+
+	case *ast.ImportStmt:
+		return evalImportStmt(ctx, env, node)
+
 	case *ast.IntLiteral:
 		return evalIntLiteral(ctx, env, node)
 
+	case *ast.KeywordLiteral:
+		return evalKeywordLiteral(ctx, env, node)
+
 	case *ast.LambdaExpr:
 		return evalLambdaExpr(ctx, env, node)
 
+	case *ast.LetExpr:
+		return evalLetExpr(ctx, env, node)
+
+	case *ast.LetStarExpr:
+		return evalLetStarExpr(ctx, env, node)
+
+	case *ast.LetrecExpr:
+		return evalLetrecExpr(ctx, env, node)
+
+	case *ast.ModuleStmt:
+		return evalModuleStmt(ctx, env, node)
+
+	case *ast.QuasiquoteExpr:
+		return evalQuasiquoteExpr(ctx, env, node)
+
 	case *ast.QuoteExpr:
 		return evalQuoteExpr(ctx, env, node)
 
+	case *ast.RationalLiteral:
+		return evalRationalLiteral(ctx, env, node)
+
 	case *ast.ReturnStmt:
 		return evalReturnStmt(ctx, env, node)
 
@@ -71,6 +110,9 @@ func Eval(ctx context.Context, env Environment, node ast.Node) (Value, error) {
 
 	case *ast.WhileExpr:
 		return evalWhileExpr(ctx, env, node)
+
+	case *ast.YieldStmt:
+		return evalYieldStmt(ctx, env, node)
 	default:
 		return nil, fmt.Errorf("unsupported node type: %T", node)
 	}
@@ -108,7 +108,7 @@ func TestEval(t *testing.T) {
 	})
 
 	t.Run("symbol name", func(t *testing.T) {
-		env.DefineValue("x", env.NewIntValue(42))
+		env.DefineValue(nil, "x", env.NewIntValue(42))
 		symbolName := &ast.SymbolName{
 			Token: token.Token{TokenType: token.ATOM, Value: "x"},
 			Value: "x",
@@ -192,7 +192,7 @@ func TestEval(t *testing.T) {
 	})
 
 	t.Run("set expression", func(t *testing.T) {
-		env.DefineValue("x", env.NewIntValue(0))
+		env.DefineValue(nil, "x", env.NewIntValue(0))
 		setExpr := &ast.SetExpr{
 			Token:  token.Token{TokenType: token.ATOM, Value: "set!"},
 			Symbol: "x",
@@ -246,7 +246,7 @@ func TestEval(t *testing.T) {
 	})
 
 	t.Run("call expression", func(t *testing.T) {
-		env.DefineValue("myFunction", NewMockCallable(func(ctx context.Context, args ...Value) (Value, error) {
+		env.DefineValue(nil, "myFunction", NewMockCallable(func(ctx context.Context, args ...Value) (Value, error) {
 			return env.NewIntValue(42), nil
 		}))
 
@@ -362,7 +362,7 @@ func TestEval(t *testing.T) {
 
 	t.Run("while expression", func(t *testing.T) {
 		counter := 0
-		env.DefineValue("counter", env.NewIntValue(counter))
+		env.DefineValue(nil, "counter", env.NewIntValue(counter))
 
 		whileExpr := &ast.WhileExpr{
 			Token: token.Token{TokenType: token.ATOM, Value: "while"},
@@ -382,13 +382,13 @@ func TestEval(t *testing.T) {
 			},
 		}
 
-		env.DefineValue("lessThanTen", NewMockCallable(func(ctx context.Context, args ...Value) (Value, error) {
+		env.DefineValue(nil, "lessThanTen", NewMockCallable(func(ctx context.Context, args ...Value) (Value, error) {
 			val, _ := env.GetValue("counter")
 			intVal := val.(MockValue).value.(int)
 			return env.NewBoolValue(intVal < 10), nil
 		}))
 
-		env.DefineValue("incrementCounter", NewMockCallable(func(ctx context.Context, args ...Value) (Value, error) {
+		env.DefineValue(nil, "incrementCounter", NewMockCallable(func(ctx context.Context, args ...Value) (Value, error) {
 			val, _ := env.GetValue("counter")
 			intVal := val.(MockValue).value.(int)
 			env.SetValue("counter", env.NewIntValue(intVal+1))
@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+)
+
+func evalForeachExpr(ctx context.Context, env Environment, node *ast.ForeachExpr) (Value, error) {
+	// 1. evaluate the sequence once, before starting the loop
+	value, err := Eval(ctx, env, node.Seq)
+	if err != nil {
+		return nil, err
+	}
+	elements, err := env.IterateValue(ctx, value)
+	if err != nil {
+		return nil, env.WrapError(node.Seq, err)
+	}
+
+	// 2. bind the symbol in a new block scope for each iteration, such
+	// that closures created by the body capture the current element
+	for element, err := range elements {
+		if err != nil {
+			return nil, err
+		}
+		scope := env.PushBlockScope()
+		if err := scope.DefineValue(node, node.Symbol, element); err != nil {
+			return nil, env.WrapError(node, err)
+		}
+		stop, err := evalLoopBody(ctx, scope, node.Expr)
+		if err != nil {
+			return nil, err
+		}
+		if stop {
+			break
+		}
+	}
+	return env.NewUnitValue(), nil
+}
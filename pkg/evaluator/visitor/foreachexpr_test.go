@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/token"
+)
+
+func TestEvalForeachExpr(t *testing.T) {
+	ctx := context.Background()
+	env := NewMockEnvironment()
+
+	var seen []int
+	env.DefineValue(nil, "collect", NewMockCallable(func(ctx context.Context, args ...Value) (Value, error) {
+		val, _ := env.GetValue("x")
+		intVal := val.(MockValue).value.(int)
+		if intVal == 2 {
+			return nil, &errContinue{}
+		}
+		seen = append(seen, intVal)
+		return env.NewUnitValue(), nil
+	}))
+
+	foreach := &ast.ForeachExpr{
+		Token:  token.Token{TokenType: token.ATOM, Value: "foreach"},
+		Symbol: "x",
+		Seq: &ast.SymbolName{
+			Token: token.Token{TokenType: token.ATOM, Value: "seq"},
+			Value: "seq",
+		},
+		Expr: &ast.CallExpr{
+			Token: token.Token{TokenType: token.ATOM, Value: "call"},
+			Callable: &ast.SymbolName{
+				Token: token.Token{TokenType: token.ATOM, Value: "collect"},
+				Value: "collect",
+			},
+		},
+	}
+
+	t.Run("we bind the elements of the sequence", func(t *testing.T) {
+		env.DefineValue(nil, "seq", MockValue{value: []Value{env.NewIntValue(1), env.NewIntValue(2), env.NewIntValue(3)}})
+		if _, err := evalForeachExpr(ctx, env, foreach); err != nil {
+			t.Fatal(err)
+		}
+		if len(seen) != 2 || seen[0] != 1 || seen[1] != 3 {
+			t.Fatalf("unexpected iterations: %v", seen)
+		}
+	})
+
+	t.Run("we fail when the value is not a sequence", func(t *testing.T) {
+		env.DefineValue(nil, "seq", env.NewIntValue(1))
+		if _, err := evalForeachExpr(ctx, env, foreach); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+)
+
+func evalForExpr(ctx context.Context, env Environment, node *ast.ForExpr) (Value, error) {
+	// 1. evaluate the bounds once, before starting the loop
+	from, err := evalIntExpr(ctx, env, node.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := evalIntExpr(ctx, env, node.To)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. bind the symbol in a new block scope for each iteration, such
+	// that closures created by the body capture the current value
+	for idx := from; idx < to; idx++ {
+		scope := env.PushBlockScope()
+		if err := scope.DefineValue(node, node.Symbol, env.NewIntValue(idx)); err != nil {
+			return nil, env.WrapError(node, err)
+		}
+		stop, err := evalLoopBody(ctx, scope, node.Expr)
+		if err != nil {
+			return nil, err
+		}
+		if stop {
+			break
+		}
+	}
+	return env.NewUnitValue(), nil
+}
+
+// evalIntExpr evaluates the given node and unwraps the resulting int.
+func evalIntExpr(ctx context.Context, env Environment, node ast.Node) (int, error) {
+	value, err := Eval(ctx, env, node)
+	if err != nil {
+		return 0, err
+	}
+	number, err := env.UnwrapIntValue(value)
+	if err != nil {
+		return 0, env.WrapError(node, err)
+	}
+	return number, nil
+}
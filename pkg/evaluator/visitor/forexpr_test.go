@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/token"
+)
+
+func TestEvalForExpr(t *testing.T) {
+	ctx := context.Background()
+	env := NewMockEnvironment()
+
+	var seen []int
+	env.DefineValue(nil, "collect", NewMockCallable(func(ctx context.Context, args ...Value) (Value, error) {
+		val, _ := env.GetValue("i")
+		intVal := val.(MockValue).value.(int)
+		seen = append(seen, intVal)
+		if intVal >= 3 {
+			return nil, &errBreak{}
+		}
+		return env.NewUnitValue(), nil
+	}))
+
+	newForExpr := func(from, to string) *ast.ForExpr {
+		return &ast.ForExpr{
+			Token:  token.Token{TokenType: token.ATOM, Value: "for"},
+			Symbol: "i",
+			From:   &ast.IntLiteral{Token: token.Token{TokenType: token.NUMBER, Value: from}, Value: from},
+			To:     &ast.IntLiteral{Token: token.Token{TokenType: token.NUMBER, Value: to}, Value: to},
+			Expr: &ast.CallExpr{
+				Token: token.Token{TokenType: token.ATOM, Value: "call"},
+				Callable: &ast.SymbolName{
+					Token: token.Token{TokenType: token.ATOM, Value: "collect"},
+					Value: "collect",
+				},
+			},
+		}
+	}
+
+	t.Run("we bind the integers in the range", func(t *testing.T) {
+		seen = nil
+		if _, err := evalForExpr(ctx, env, newForExpr("0", "3")); err != nil {
+			t.Fatal(err)
+		}
+		if len(seen) != 3 || seen[0] != 0 || seen[2] != 2 {
+			t.Fatalf("unexpected iterations: %v", seen)
+		}
+	})
+
+	t.Run("we handle break", func(t *testing.T) {
+		seen = nil
+		if _, err := evalForExpr(ctx, env, newForExpr("1", "10")); err != nil {
+			t.Fatal(err)
+		}
+		if len(seen) != 3 || seen[2] != 3 {
+			t.Fatalf("unexpected iterations: %v", seen)
+		}
+	})
+
+	t.Run("we do not iterate an empty range", func(t *testing.T) {
+		seen = nil
+		if _, err := evalForExpr(ctx, env, newForExpr("3", "0")); err != nil {
+			t.Fatal(err)
+		}
+		if len(seen) != 0 {
+			t.Fatalf("unexpected iterations: %v", seen)
+		}
+	})
+}
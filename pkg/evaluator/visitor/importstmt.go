@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+)
+
+// errNotAModule is returned when the import statement does not contain a module,
+// which happens if we did not run the includer before evaluating.
+var errNotAModule = errors.New("import: missing module declaration")
+
+// evalImportStmt evaluates an import statement.
+func evalImportStmt(ctx context.Context, env Environment, node *ast.ImportStmt) (Value, error) {
+	// 1. make sure the includer has loaded a module
+	if len(node.Nodes) <= 0 {
+		return nil, env.WrapError(node, errNotAModule)
+	}
+	decl, ok := node.Nodes[0].(*ast.ModuleStmt)
+	if !ok {
+		return nil, env.WrapError(node, errNotAModule)
+	}
+
+	// 2. evaluate the module unless we have already evaluated it
+	module, found := env.GetModule(node.FilePath)
+	if !found {
+		module = env.PushModuleScope()
+		for _, expr := range node.Nodes {
+			if _, err := Eval(ctx, module, expr); err != nil {
+				return nil, err
+			}
+		}
+		if err := env.DefineModule(node.FilePath, module); err != nil {
+			return nil, env.WrapError(node, err)
+		}
+	}
+
+	// 3. bind each exported symbol as `<alias>/<symbol>`
+	for _, symbol := range decl.Exports {
+		value, err := module.GetValue(symbol)
+		if err != nil {
+			return nil, env.WrapError(node, err)
+		}
+		if err := env.DefineValue(node, node.Alias+"/"+symbol, value); err != nil {
+			return nil, env.WrapError(node, err)
+		}
+	}
+	return env.NewUnitValue(), nil
+}
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/token"
+)
+
+func TestEvalImportStmt(t *testing.T) {
+	ctx := context.Background()
+
+	newImport := func(alias string) *ast.ImportStmt {
+		return &ast.ImportStmt{
+			Token:    token.Token{TokenType: token.ATOM, Value: "import"},
+			FilePath: "math.brs",
+			Alias:    alias,
+			Nodes: []ast.Node{
+				&ast.ModuleStmt{
+					Token:   token.Token{TokenType: token.ATOM, Value: "module"},
+					Name:    "math",
+					Exports: []string{"x"},
+				},
+				&ast.DefineExpr{
+					Token:  token.Token{TokenType: token.ATOM, Value: "define"},
+					Symbol: "x",
+					Expr: &ast.IntLiteral{
+						Token: token.Token{TokenType: token.NUMBER, Value: "42"},
+						Value: "42",
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("evaluates the module and binds the exports", func(t *testing.T) {
+		env := NewMockEnvironment()
+		if _, err := evalImportStmt(ctx, env, newImport("math")); err != nil {
+			t.Fatal(err)
+		}
+		value, err := env.GetValue("math/x")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value.String() != "42" {
+			t.Errorf("expected 42, got %s", value.String())
+		}
+		if _, found := env.GetModule("math.brs"); !found {
+			t.Error("expected the module to be saved")
+		}
+	})
+
+	t.Run("evaluates the module only once", func(t *testing.T) {
+		env := NewMockEnvironment()
+		if _, err := evalImportStmt(ctx, env, newImport("math")); err != nil {
+			t.Fatal(err)
+		}
+
+		// modify the module environment such that we can tell
+		// whether the second import evaluates the module again
+		module, _ := env.GetModule("math.brs")
+		module.SetValue("x", env.NewIntValue(11))
+
+		if _, err := evalImportStmt(ctx, env, newImport("m")); err != nil {
+			t.Fatal(err)
+		}
+		value, err := env.GetValue("m/x")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value.String() != "11" {
+			t.Errorf("expected 11, got %s", value.String())
+		}
+	})
+
+	t.Run("missing module declaration", func(t *testing.T) {
+		env := NewMockEnvironment()
+		node := newImport("math")
+		node.Nodes = node.Nodes[1:]
+		_, err := evalImportStmt(ctx, env, node)
+		if !errors.Is(err, errNotAModule) {
+			t.Errorf("expected errNotAModule, got %v", err)
+		}
+	})
+
+	t.Run("exported symbol not defined", func(t *testing.T) {
+		env := NewMockEnvironment()
+		node := newImport("math")
+		node.Nodes = node.Nodes[:1]
+		if _, err := evalImportStmt(ctx, env, node); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+}
@@ -4,15 +4,14 @@ package visitor
 
 import (
 	"context"
-	"strconv"
 
 	"github.com/bassosimone/buresu/pkg/ast"
 )
 
 func evalIntLiteral(_ context.Context, env Environment, node *ast.IntLiteral) (Value, error) {
-	value, err := strconv.Atoi(node.Value)
+	value, err := node.Int()
 	if err != nil {
-		return nil, env.WrapError(node.Token, err)
+		return nil, env.WrapError(node, err)
 	}
 	return env.NewIntValue(value), nil
 }
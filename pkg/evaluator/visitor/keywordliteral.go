@@ -0,0 +1,13 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+)
+
+func evalKeywordLiteral(_ context.Context, env Environment, node *ast.KeywordLiteral) (Value, error) {
+	return env.NewKeywordValue(node.Value), nil
+}
@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/token"
+)
+
+func TestEvalKeywordLiteral(t *testing.T) {
+	ctx := context.Background()
+	env := NewMockEnvironment()
+	kwLiteral := &ast.KeywordLiteral{
+		Token: token.Token{TokenType: token.KEYWORD, Value: ":name"},
+		Value: ":name",
+	}
+	result, err := evalKeywordLiteral(ctx, env, kwLiteral)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	expected := env.NewKeywordValue(":name")
+	if result.String() != expected.String() {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
@@ -0,0 +1,30 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+)
+
+func evalLetExpr(ctx context.Context, env Environment, node *ast.LetExpr) (Value, error) {
+	// 1. evaluate all the bindings in the enclosing environment
+	values := make([]Value, 0, len(node.Bindings))
+	for _, binding := range node.Bindings {
+		value, err := Eval(ctx, env, binding.Expr)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	// 2. bind the values in a new block scope and evaluate the body
+	env = env.PushBlockScope()
+	for idx, binding := range node.Bindings {
+		if err := env.DefineValue(binding.Expr, binding.Symbol, values[idx]); err != nil {
+			return nil, env.WrapError(node, err)
+		}
+	}
+	return Eval(ctx, env, node.Expr)
+}
@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/token"
+)
+
+func TestEvalLetExpr(t *testing.T) {
+	ctx := context.Background()
+	env := NewMockEnvironment()
+
+	t.Run("bindings are visible in the body", func(t *testing.T) {
+		let := &ast.LetExpr{
+			Token: token.Token{TokenType: token.ATOM, Value: "let"},
+			Bindings: []ast.LetBinding{
+				{
+					Symbol: "x",
+					Expr: &ast.IntLiteral{
+						Token: token.Token{TokenType: token.NUMBER, Value: "42"},
+						Value: "42",
+					},
+				},
+			},
+			Expr: &ast.SymbolName{
+				Token: token.Token{TokenType: token.ATOM, Value: "x"},
+				Value: "x",
+			},
+		}
+
+		result, err := evalLetExpr(ctx, env, let)
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if result.String() != env.NewIntValue(42).String() {
+			t.Errorf("expected %v, got %v", env.NewIntValue(42), result)
+		}
+	})
+
+	t.Run("error evaluating a binding", func(t *testing.T) {
+		let := &ast.LetExpr{
+			Token: token.Token{TokenType: token.ATOM, Value: "let"},
+			Bindings: []ast.LetBinding{
+				{
+					Symbol: "x",
+					Expr: &ast.SymbolName{
+						Token: token.Token{TokenType: token.ATOM, Value: "undefined"},
+						Value: "undefined",
+					},
+				},
+			},
+			Expr: &ast.UnitExpr{
+				Token: token.Token{TokenType: token.ATOM, Value: "()"},
+			},
+		}
+
+		_, err := evalLetExpr(ctx, env, let)
+		if err == nil {
+			t.Errorf("expected an error, got nil")
+		}
+	})
+}
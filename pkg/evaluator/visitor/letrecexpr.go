@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+)
+
+func evalLetrecExpr(ctx context.Context, env Environment, node *ast.LetrecExpr) (Value, error) {
+	// 1. create the scope and define all the symbols as unit such that
+	// lambdas evaluated below close over each other's symbols
+	env = env.PushBlockScope()
+	for _, binding := range node.Bindings {
+		if err := env.DefineValue(binding.Expr, binding.Symbol, env.NewUnitValue()); err != nil {
+			return nil, env.WrapError(node, err)
+		}
+	}
+
+	// 2. evaluate the bindings inside the new scope and assign them
+	for _, binding := range node.Bindings {
+		value, err := Eval(ctx, env, binding.Expr)
+		if err != nil {
+			return nil, err
+		}
+		if err := env.SetValue(binding.Symbol, value); err != nil {
+			return nil, env.WrapError(node, err)
+		}
+	}
+
+	// 3. evaluate the body
+	return Eval(ctx, env, node.Expr)
+}
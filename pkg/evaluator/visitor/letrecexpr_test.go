@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/token"
+)
+
+func TestEvalLetrecExpr(t *testing.T) {
+	ctx := context.Background()
+	env := NewMockEnvironment()
+
+	t.Run("bindings are evaluated in the new scope", func(t *testing.T) {
+		let := &ast.LetrecExpr{
+			Token: token.Token{TokenType: token.ATOM, Value: "letrec"},
+			Bindings: []ast.LetBinding{
+				{
+					Symbol: "x",
+					Expr: &ast.IntLiteral{
+						Token: token.Token{TokenType: token.NUMBER, Value: "7"},
+						Value: "7",
+					},
+				},
+				{
+					Symbol: "y",
+					Expr: &ast.SymbolName{
+						Token: token.Token{TokenType: token.ATOM, Value: "x"},
+						Value: "x",
+					},
+				},
+			},
+			Expr: &ast.SymbolName{
+				Token: token.Token{TokenType: token.ATOM, Value: "y"},
+				Value: "y",
+			},
+		}
+
+		result, err := evalLetrecExpr(ctx, env, let)
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if result.String() != env.NewIntValue(7).String() {
+			t.Errorf("expected %v, got %v", env.NewIntValue(7), result)
+		}
+	})
+}
@@ -0,0 +1,29 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+)
+
+func evalLetStarExpr(ctx context.Context, env Environment, node *ast.LetStarExpr) (Value, error) {
+	// each binding lives in its own block scope nested inside the scope
+	// of the previous binding, which is what allows rebinding a symbol,
+	// and the body is evaluated inside the innermost scope
+	env = env.PushBlockScope()
+	for idx, binding := range node.Bindings {
+		value, err := Eval(ctx, env, binding.Expr)
+		if err != nil {
+			return nil, err
+		}
+		if idx > 0 {
+			env = env.PushBlockScope()
+		}
+		if err := env.DefineValue(binding.Expr, binding.Symbol, value); err != nil {
+			return nil, env.WrapError(node, err)
+		}
+	}
+	return Eval(ctx, env, node.Expr)
+}
@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/token"
+)
+
+func TestEvalLetStarExpr(t *testing.T) {
+	ctx := context.Background()
+	env := NewMockEnvironment()
+
+	t.Run("bindings see the previous bindings", func(t *testing.T) {
+		let := &ast.LetStarExpr{
+			Token: token.Token{TokenType: token.ATOM, Value: "let*"},
+			Bindings: []ast.LetBinding{
+				{
+					Symbol: "x",
+					Expr: &ast.StringLiteral{
+						Token: token.Token{TokenType: token.STRING, Value: "\"hello\""},
+						Value: "hello",
+					},
+				},
+				{
+					Symbol: "y",
+					Expr: &ast.SymbolName{
+						Token: token.Token{TokenType: token.ATOM, Value: "x"},
+						Value: "x",
+					},
+				},
+			},
+			Expr: &ast.SymbolName{
+				Token: token.Token{TokenType: token.ATOM, Value: "y"},
+				Value: "y",
+			},
+		}
+
+		result, err := evalLetStarExpr(ctx, env, let)
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if result.String() != env.NewStringValue("hello").String() {
+			t.Errorf("expected %v, got %v", env.NewStringValue("hello"), result)
+		}
+	})
+
+	t.Run("no bindings", func(t *testing.T) {
+		let := &ast.LetStarExpr{
+			Token: token.Token{TokenType: token.ATOM, Value: "let*"},
+			Expr: &ast.TrueLiteral{
+				Token: token.Token{TokenType: token.ATOM, Value: "true"},
+			},
+		}
+
+		result, err := evalLetStarExpr(ctx, env, let)
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if result.String() != env.NewBoolValue(true).String() {
+			t.Errorf("expected %v, got %v", env.NewBoolValue(true), result)
+		}
+	})
+}
@@ -4,26 +4,39 @@ package visitor
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"iter"
+	"math/big"
 
 	"github.com/bassosimone/buresu/pkg/ast"
-	"github.com/bassosimone/buresu/pkg/token"
 )
 
 // MockEnvironment is a mock implementation of the Environment interface
 // used for testing purposes.
 type MockEnvironment struct {
 	insideFunc bool
+	modules    map[string]Environment
 	values     map[string]Value
+	yielded    []Value
 }
 
 // NewMockEnvironment creates a new instance of MockEnvironment.
 func NewMockEnvironment() *MockEnvironment {
-	return &MockEnvironment{values: make(map[string]Value)}
+	return &MockEnvironment{
+		modules: make(map[string]Environment),
+		values:  make(map[string]Value),
+	}
+}
+
+// DefineModule saves the environment of a module in the mock environment.
+func (env *MockEnvironment) DefineModule(filePath string, module Environment) error {
+	env.modules[filePath] = module
+	return nil
 }
 
 // DefineValue defines a new symbol in the mock environment.
-func (env *MockEnvironment) DefineValue(symbol string, value Value) error {
+func (env *MockEnvironment) DefineValue(node ast.Node, symbol string, value Value) error {
 	env.values[symbol] = value
 	return nil
 }
@@ -45,6 +58,12 @@ func (env *MockEnvironment) EvalCallable(ctx context.Context, node ast.Node) (Ca
 	return callable, nil
 }
 
+// GetModule returns the environment of a module in the mock environment.
+func (env *MockEnvironment) GetModule(filePath string) (Environment, bool) {
+	module, found := env.modules[filePath]
+	return module, found
+}
+
 // GetValue returns the value associated with the given symbol in the mock environment.
 func (env *MockEnvironment) GetValue(symbol string) (Value, error) {
 	value, exists := env.values[symbol]
@@ -59,6 +78,21 @@ func (env *MockEnvironment) IsInsideFunc() bool {
 	return env.insideFunc
 }
 
+// IterateValue returns an iterator over the elements of a sequence value in the mock environment.
+func (env *MockEnvironment) IterateValue(ctx context.Context, value Value) (iter.Seq2[Value, error], error) {
+	values, ok := value.(MockValue).value.([]Value)
+	if !ok {
+		return nil, errors.New("not a sequence")
+	}
+	return func(yield func(Value, error) bool) {
+		for _, value := range values {
+			if !yield(value, nil) {
+				return
+			}
+		}
+	}, nil
+}
+
 // NewBoolValue returns a new boolean value instance in the mock environment.
 func (env *MockEnvironment) NewBoolValue(value bool) Value {
 	return MockValue{value: value}
@@ -84,6 +118,43 @@ func (env *MockEnvironment) NewQuotedValue(node *ast.QuoteExpr) Value {
 	return MockValue{value: node}
 }
 
+// QuoteValue returns the AST node representing a value in the mock environment.
+func (env *MockEnvironment) QuoteValue(value Value) (ast.Node, error) {
+	if node, ok := value.(MockValue).value.(ast.Node); ok {
+		return node, nil
+	}
+	return &ast.SymbolName{Value: value.String()}, nil
+}
+
+// QuoteValues returns the AST nodes representing the elements of a sequence value in the mock environment.
+func (env *MockEnvironment) QuoteValues(value Value) ([]ast.Node, error) {
+	values, ok := value.(MockValue).value.([]Value)
+	if !ok {
+		return nil, errors.New("not a sequence")
+	}
+	var nodes []ast.Node
+	for _, value := range values {
+		node, _ := env.QuoteValue(value)
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// NewCharValue returns a new char value instance in the mock environment.
+func (env *MockEnvironment) NewCharValue(value rune) Value {
+	return MockValue{value: string(value)}
+}
+
+// NewKeywordValue returns a new keyword value instance in the mock environment.
+func (env *MockEnvironment) NewKeywordValue(value string) Value {
+	return MockValue{value: value}
+}
+
+// NewRationalValue returns a new rational value instance in the mock environment.
+func (env *MockEnvironment) NewRationalValue(value *big.Rat) Value {
+	return MockValue{value: value}
+}
+
 // NewStringValue returns a new string value instance in the mock environment.
 func (env *MockEnvironment) NewStringValue(value string) Value {
 	return MockValue{value: value}
@@ -104,6 +175,11 @@ func (env *MockEnvironment) PushFunctionScope() Environment {
 	return env
 }
 
+// PushModuleScope creates a new environment for a module in the mock environment.
+func (env *MockEnvironment) PushModuleScope() Environment {
+	return NewMockEnvironment()
+}
+
 // SetValue sets the value of an existing symbol in the mock environment.
 func (env *MockEnvironment) SetValue(symbol string, value Value) error {
 	env.values[symbol] = value
@@ -119,9 +195,24 @@ func (env *MockEnvironment) UnwrapBoolValue(value Value) (bool, error) {
 	return boolVal.value.(bool), nil
 }
 
+// UnwrapIntValue attempts to unwrap an int from a value in the mock environment.
+func (env *MockEnvironment) UnwrapIntValue(value Value) (int, error) {
+	intVal, ok := value.(MockValue).value.(int)
+	if !ok {
+		return 0, fmt.Errorf("value is not an int")
+	}
+	return intVal, nil
+}
+
 // WrapError wraps an error with contextual token information in the mock environment.
-func (env *MockEnvironment) WrapError(tok token.Token, err error) error {
-	return fmt.Errorf("%s: %w", tok.Value, err)
+func (env *MockEnvironment) WrapError(node ast.Node, err error) error {
+	return fmt.Errorf("%s: %w", ast.NodeToken(node).Value, err)
+}
+
+// Yield records the yielded value in the mock environment.
+func (env *MockEnvironment) Yield(ctx context.Context, value Value) error {
+	env.yielded = append(env.yielded, value)
+	return nil
 }
 
 // MockValue is a mock implementation of the Value interface used for testing purposes.
@@ -0,0 +1,17 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+)
+
+// evalModuleStmt evaluates a module statement.
+func evalModuleStmt(_ context.Context, env Environment, _ *ast.ModuleStmt) (Value, error) {
+	// The module statement only matters when importing, where evalImportStmt
+	// uses it to know which symbols to export. When evaluating the module
+	// itself, the module statement is just a way to get the unit value.
+	return env.NewUnitValue(), nil
+}
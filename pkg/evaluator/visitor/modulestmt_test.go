@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/token"
+)
+
+func TestEvalModuleStmt(t *testing.T) {
+	ctx := context.Background()
+	env := NewMockEnvironment()
+	node := &ast.ModuleStmt{
+		Token:   token.Token{TokenType: token.ATOM, Value: "module"},
+		Name:    "math",
+		Exports: []string{"x"},
+	}
+	value, err := evalModuleStmt(ctx, env, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value.String() != env.NewUnitValue().String() {
+		t.Errorf("expected unit value, got %s", value.String())
+	}
+}
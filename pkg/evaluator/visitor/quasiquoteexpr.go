@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/token"
+)
+
+func evalQuasiquoteExpr(ctx context.Context, env Environment, node *ast.QuasiquoteExpr) (Value, error) {
+	// 1. evaluate the unquoted expressions and convert their values to nodes
+	values := make(map[*ast.UnquoteExpr][]ast.Node)
+	for _, unquote := range node.Unquotes() {
+		value, err := Eval(ctx, env, unquote.Expr)
+		if err != nil {
+			return nil, err
+		}
+		if unquote.Splicing {
+			nodes, err := env.QuoteValues(value)
+			if err != nil {
+				return nil, env.WrapError(unquote, err)
+			}
+			values[unquote] = nodes
+			continue
+		}
+		quoted, err := env.QuoteValue(value)
+		if err != nil {
+			return nil, env.WrapError(unquote, err)
+		}
+		values[unquote] = []ast.Node{quoted}
+	}
+
+	// 2. replace the unquoted expressions with their values
+	expr, err := node.Expand(values)
+	if err != nil {
+		return nil, env.WrapError(node, err)
+	}
+
+	// 3. return the expanded expression as a quoted value
+	tok := token.Token{
+		TokenPos:  node.Token.TokenPos,
+		TokenType: token.QUOTE,
+		Value:     "'",
+		TokenEnd:  node.Token.TokenEnd,
+	}
+	return env.NewQuotedValue(&ast.QuoteExpr{Token: tok, End: node.End, Expr: expr}), nil
+}
@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/token"
+)
+
+func TestEvalQuasiquoteExpr(t *testing.T) {
+	ctx := context.Background()
+	env := NewMockEnvironment()
+	env.values["x"] = MockValue{value: 42}
+	env.values["xs"] = MockValue{value: []Value{MockValue{value: 1}, MockValue{value: 2}}}
+
+	// newQuasiquote returns "`(a <elements>...)"
+	newQuasiquote := func(elements ...ast.Node) *ast.QuasiquoteExpr {
+		return &ast.QuasiquoteExpr{
+			Token: token.Token{TokenType: token.QUASIQUOTE, Value: "`"},
+			Expr:  &ast.CallExpr{Callable: &ast.SymbolName{Value: "a"}, Args: elements},
+		}
+	}
+
+	// newUnquote returns ",<symbol>" or ",@<symbol>"
+	newUnquote := func(symbol string, splicing bool) *ast.UnquoteExpr {
+		tok := token.Token{TokenType: token.UNQUOTE, Value: ","}
+		if splicing {
+			tok = token.Token{TokenType: token.UNQUOTE_SPLICING, Value: ",@"}
+		}
+		return &ast.UnquoteExpr{Token: tok, Splicing: splicing, Expr: &ast.SymbolName{Value: symbol}}
+	}
+
+	t.Run("unquote", func(t *testing.T) {
+		result, err := evalQuasiquoteExpr(ctx, env, newQuasiquote(newUnquote("x", false)))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if result.String() != "'(a 42)" {
+			t.Errorf("expected %v, got %v", "'(a 42)", result.String())
+		}
+	})
+
+	t.Run("unquote-splicing", func(t *testing.T) {
+		result, err := evalQuasiquoteExpr(ctx, env, newQuasiquote(newUnquote("xs", true), newUnquote("x", false)))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if result.String() != "'(a 1 2 42)" {
+			t.Errorf("expected %v, got %v", "'(a 1 2 42)", result.String())
+		}
+	})
+
+	t.Run("unquote-splicing of a non-sequence", func(t *testing.T) {
+		_, err := evalQuasiquoteExpr(ctx, env, newQuasiquote(newUnquote("x", true)))
+		if err == nil || err.Error() != ",@: not a sequence" {
+			t.Errorf("expected %v, got %v", ",@: not a sequence", err)
+		}
+	})
+
+	t.Run("unquote of an undefined symbol", func(t *testing.T) {
+		_, err := evalQuasiquoteExpr(ctx, env, newQuasiquote(newUnquote("y", false)))
+		if err == nil {
+			t.Errorf("expected an error")
+		}
+	})
+}
@@ -0,0 +1,17 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+)
+
+func evalRationalLiteral(_ context.Context, env Environment, node *ast.RationalLiteral) (Value, error) {
+	value, err := node.Rat()
+	if err != nil {
+		return nil, env.WrapError(node, err)
+	}
+	return env.NewRationalValue(value), nil
+}
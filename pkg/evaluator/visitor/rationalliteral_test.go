@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/token"
+)
+
+func TestEvalRationalLiteral(t *testing.T) {
+	ctx := context.Background()
+	env := NewMockEnvironment()
+
+	t.Run("Valid rational literal", func(t *testing.T) {
+		rationalLiteral := &ast.RationalLiteral{
+			Token: token.Token{TokenType: token.NUMBER, Value: "2/4"},
+			Value: "2/4",
+		}
+		result, err := evalRationalLiteral(ctx, env, rationalLiteral)
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		expected := env.NewRationalValue(big.NewRat(1, 2))
+		if result.String() != expected.String() {
+			t.Errorf("expected %v, got %v", expected, result)
+		}
+	})
+
+	t.Run("Invalid rational literal", func(t *testing.T) {
+		invalidRationalLiteral := &ast.RationalLiteral{
+			Token: token.Token{TokenType: token.NUMBER, Value: "1/0"},
+			Value: "1/0",
+		}
+		_, err := evalRationalLiteral(ctx, env, invalidRationalLiteral)
+		if err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
@@ -14,7 +14,7 @@ func evalSetExpr(ctx context.Context, env Environment, node *ast.SetExpr) (Value
 		return nil, err
 	}
 	if err := env.SetValue(node.Symbol, value); err != nil {
-		return nil, env.WrapError(node.Token, err)
+		return nil, env.WrapError(node, err)
 	}
 	return value, nil
 }
@@ -13,7 +13,7 @@ import (
 func TestEvalSetExpr(t *testing.T) {
 	ctx := context.Background()
 	env := NewMockEnvironment()
-	env.DefineValue("x", env.NewIntValue(0))
+	env.DefineValue(nil, "x", env.NewIntValue(0))
 
 	t.Run("set integer value", func(t *testing.T) {
 		// Create a set expression
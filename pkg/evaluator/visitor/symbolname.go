@@ -9,5 +9,9 @@ import (
 )
 
 func evalSymbolName(_ context.Context, env Environment, node *ast.SymbolName) (Value, error) {
-	return env.GetValue(node.Value)
+	value, err := env.GetValue(node.Value)
+	if err != nil {
+		return nil, env.WrapError(node, err)
+	}
+	return value, nil
 }
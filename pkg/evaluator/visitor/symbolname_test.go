@@ -14,7 +14,7 @@ import (
 func TestEvalSymbolName(t *testing.T) {
 	ctx := context.Background()
 	env := NewMockEnvironment()
-	env.DefineValue("x", env.NewIntValue(42))
+	env.DefineValue(nil, "x", env.NewIntValue(42))
 
 	t.Run("symbol exists in the environment", func(t *testing.T) {
 		symbol := &ast.SymbolName{
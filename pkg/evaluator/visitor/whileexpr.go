@@ -4,6 +4,7 @@ package visitor
 
 import (
 	"context"
+	"errors"
 
 	"github.com/bassosimone/buresu/pkg/ast"
 )
@@ -21,9 +22,34 @@ func evalWhileExpr(ctx context.Context, env Environment, node *ast.WhileExpr) (V
 		if !boolVal {
 			break
 		}
-		if _, err = Eval(ctx, env, node.Expr); err != nil {
+		stop, err := evalLoopBody(ctx, env, node.Expr)
+		if err != nil {
 			return nil, err
 		}
+		if stop {
+			break
+		}
 	}
 	return env.NewUnitValue(), nil
 }
+
+// evalLoopBody evaluates the body of a loop handling break! and continue!,
+// and returns whether the loop should stop because of a break!.
+//
+// Note that we only handle these statements for the body, since the
+// other expressions of a loop may only contain those of an enclosing loop.
+func evalLoopBody(ctx context.Context, env Environment, body ast.Node) (bool, error) {
+	_, err := Eval(ctx, env, body)
+	var (
+		brkErr  *errBreak
+		contErr *errContinue
+	)
+	switch {
+	case errors.As(err, &brkErr):
+		return true, nil
+	case errors.As(err, &contErr):
+		return false, nil
+	default:
+		return false, err
+	}
+}
@@ -14,7 +14,7 @@ func TestEvalWhileExpr(t *testing.T) {
 	ctx := context.Background()
 	env := NewMockEnvironment()
 	counter := 0
-	env.DefineValue("counter", env.NewIntValue(counter))
+	env.DefineValue(nil, "counter", env.NewIntValue(counter))
 
 	while := &ast.WhileExpr{
 		Token: token.Token{TokenType: token.ATOM, Value: "while"},
@@ -34,13 +34,13 @@ func TestEvalWhileExpr(t *testing.T) {
 		},
 	}
 
-	env.DefineValue("lessThanTen", NewMockCallable(func(ctx context.Context, args ...Value) (Value, error) {
+	env.DefineValue(nil, "lessThanTen", NewMockCallable(func(ctx context.Context, args ...Value) (Value, error) {
 		val, _ := env.GetValue("counter")
 		intVal := val.(MockValue).value.(int)
 		return env.NewBoolValue(intVal < 10), nil
 	}))
 
-	env.DefineValue("incrementCounter", NewMockCallable(func(ctx context.Context, args ...Value) (Value, error) {
+	env.DefineValue(nil, "incrementCounter", NewMockCallable(func(ctx context.Context, args ...Value) (Value, error) {
 		val, _ := env.GetValue("counter")
 		intVal := val.(MockValue).value.(int)
 		env.SetValue("counter", env.NewIntValue(intVal+1))
@@ -92,6 +92,54 @@ func TestEvalWhileExpr(t *testing.T) {
 			t.Errorf("expected no error, got %v", err)
 		}
 
+		if val.String() != env.NewIntValue(10).String() {
+			t.Errorf("expected %v, got %v", env.NewIntValue(10), val)
+		}
+	})
+	t.Run("while loop with break", func(t *testing.T) {
+		env.SetValue("counter", env.NewIntValue(0))
+		env.DefineValue(nil, "incrementCounter", NewMockCallable(func(ctx context.Context, args ...Value) (Value, error) {
+			val, _ := env.GetValue("counter")
+			intVal := val.(MockValue).value.(int)
+			env.SetValue("counter", env.NewIntValue(intVal+1))
+			if intVal+1 >= 3 {
+				return nil, &errBreak{}
+			}
+			return env.NewUnitValue(), nil
+		}))
+		_, err := evalWhileExpr(ctx, env, while)
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+
+		val, err := env.GetValue("counter")
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+
+		if val.String() != env.NewIntValue(3).String() {
+			t.Errorf("expected %v, got %v", env.NewIntValue(3), val)
+		}
+	})
+
+	t.Run("while loop with continue", func(t *testing.T) {
+		env.SetValue("counter", env.NewIntValue(0))
+		env.DefineValue(nil, "incrementCounter", NewMockCallable(func(ctx context.Context, args ...Value) (Value, error) {
+			val, _ := env.GetValue("counter")
+			intVal := val.(MockValue).value.(int)
+			env.SetValue("counter", env.NewIntValue(intVal+1))
+			return nil, &errContinue{}
+		}))
+		_, err := evalWhileExpr(ctx, env, while)
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+
+		val, err := env.GetValue("counter")
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+
 		if val.String() != env.NewIntValue(10).String() {
 			t.Errorf("expected %v, got %v", env.NewIntValue(10), val)
 		}
@@ -0,0 +1,22 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+)
+
+func evalYieldStmt(ctx context.Context, env Environment, node *ast.YieldStmt) (Value, error) {
+	// the parser guarantees that a yield! statement only happens inside a lambda,
+	// while the environment checks whether a generator is running the lambda
+	value, err := Eval(ctx, env, node.Expr)
+	if err != nil {
+		return nil, err
+	}
+	if err := env.Yield(ctx, value); err != nil {
+		return nil, env.WrapError(node, err)
+	}
+	return env.NewUnitValue(), nil
+}
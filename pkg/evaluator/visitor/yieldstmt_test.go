@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/token"
+)
+
+func TestEvalYieldStmt(t *testing.T) {
+	ctx := context.Background()
+	env := NewMockEnvironment()
+
+	t.Run("we hand over the value of the expression", func(t *testing.T) {
+		yld := &ast.YieldStmt{
+			Token: token.Token{TokenType: token.ATOM, Value: "yield!"},
+			Expr:  &ast.IntLiteral{Token: token.Token{TokenType: token.NUMBER, Value: "42"}, Value: "42"},
+		}
+
+		value, err := evalYieldStmt(ctx, env, yld)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value.String() != env.NewUnitValue().String() {
+			t.Errorf("expected unit, got %s", value.String())
+		}
+		if len(env.yielded) != 1 || env.yielded[0].String() != "42" {
+			t.Errorf("unexpected yielded values: %v", env.yielded)
+		}
+	})
+}
@@ -0,0 +1,473 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package formatter implements the canonical source code formatter.
+//
+// The formatter scans the source code retaining comments and blank lines,
+// builds a tree of s-expressions from the tokens, and pretty-prints it,
+// breaking lines that do not fit into [Width] columns.
+package formatter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bassosimone/buresu/pkg/parser"
+	"github.com/bassosimone/buresu/pkg/scanner"
+	"github.com/bassosimone/buresu/pkg/token"
+)
+
+// Width is the maximum width of a line, unless some atom is longer.
+const Width = 80
+
+// indentWidth is the number of spaces we use for indenting bodies.
+const indentWidth = 2
+
+// bodyForms maps the special forms we indent as bodies to the number of
+// arguments we keep on the same line of the form name, if possible.
+var bodyForms = map[string]int{
+	"block":    0,
+	"cond":     0,
+	"declare":  1,
+	"define":   1,
+	"for":      1,
+	"foreach":  1,
+	"if":       1,
+	"import":   1,
+	"include!": 1,
+	"lambda":   1,
+	"let":      1,
+	"let*":     1,
+	"letrec":   1,
+	"module":   1,
+	"quote":    1,
+	"return!":  1,
+	"set!":     1,
+	"while":    1,
+	"yield!":   1,
+}
+
+// Format formats the given source code, which must contain a valid program.
+func Format(filename string, source []byte) ([]byte, error) {
+	// scan retaining comments and blank lines
+	tokens, err := scanner.ScanWithTrivia(filename, bytes.NewReader(source))
+	if err != nil {
+		return nil, err
+	}
+
+	// make sure the program is valid before formatting it
+	if _, err := parser.Parse(tokens); err != nil {
+		return nil, err
+	}
+
+	// build the s-expressions tree and pretty print it
+	tree, eof := newTreeBuilder(tokens).build()
+	p := &printer{}
+	for _, n := range tree {
+		p.startLine(n.first().Leading, 0, true)
+		p.print(n)
+	}
+	p.endComments(eof.Leading, 0)
+	if p.out.Len() > 0 {
+		p.write("\n")
+	}
+	formatted := []byte(p.out.String())
+
+	// as a safety net, make sure we did not change the program
+	if !sameProgram(filename, tokens, formatted) {
+		return nil, &Error{FileName: filename}
+	}
+	return formatted, nil
+}
+
+// sameProgram returns whether the formatted source contains the given tokens,
+// which we compare by value, given that we reindent multiline strings.
+func sameProgram(filename string, tokens []token.Token, formatted []byte) bool {
+	formattedTokens, err := scanner.Scan(filename, bytes.NewReader(formatted))
+	if err != nil || len(formattedTokens) != len(tokens) {
+		return false
+	}
+	for idx := range tokens {
+		if tokens[idx].TokenType != formattedTokens[idx].TokenType ||
+			tokens[idx].Value != formattedTokens[idx].Value {
+			return false
+		}
+	}
+	return true
+}
+
+// node is a node of the s-expressions tree.
+type node struct {
+	// prefix contains the quote, quasiquote and unquote tokens preceding
+	// the node, which we print attached to the node (e.g., `'(1 2)`).
+	prefix []token.Token
+
+	// open is the OPEN token of lists and the token of atoms.
+	open token.Token
+
+	// items contains the items of lists.
+	items []*node
+
+	// close is the CLOSE token of lists.
+	close token.Token
+
+	// isList indicates whether this node is a list.
+	isList bool
+}
+
+// first returns the first token of the node.
+func (n *node) first() token.Token {
+	if len(n.prefix) > 0 {
+		return n.prefix[0]
+	}
+	return n.open
+}
+
+// prefixText returns the source code of the prefix of the node.
+func (n *node) prefixText() string {
+	var text strings.Builder
+	for _, tok := range n.prefix {
+		text.WriteString(tok.Value)
+	}
+	return text.String()
+}
+
+// last returns the last token of the node.
+func (n *node) last() token.Token {
+	if n.isList {
+		return n.close
+	}
+	return n.open
+}
+
+// treeBuilder builds the s-expressions tree.
+type treeBuilder struct {
+	tokens []token.Token
+	idx    int
+}
+
+// newTreeBuilder creates a new [*treeBuilder].
+func newTreeBuilder(tokens []token.Token) *treeBuilder {
+	return &treeBuilder{tokens: tokens}
+}
+
+// build returns the top-level nodes and the EOF token. Because we have
+// already parsed the tokens, we know the parentheses are balanced.
+func (b *treeBuilder) build() ([]*node, token.Token) {
+	var nodes []*node
+	for b.tokens[b.idx].TokenType != token.EOF {
+		nodes = append(nodes, b.node())
+	}
+	return nodes, b.tokens[b.idx]
+}
+
+// node builds the node starting at the current token.
+func (b *treeBuilder) node() *node {
+	var prefix []token.Token
+	for b.isPrefix() {
+		prefix = append(prefix, b.tokens[b.idx])
+		b.idx++
+	}
+	tok := b.tokens[b.idx]
+	b.idx++
+	if tok.TokenType != token.OPEN {
+		return &node{prefix: prefix, open: tok}
+	}
+	n := &node{prefix: prefix, open: tok, isList: true}
+	for b.tokens[b.idx].TokenType != token.CLOSE {
+		n.items = append(n.items, b.node())
+	}
+	n.close = b.tokens[b.idx]
+	b.idx++
+	return n
+}
+
+// isPrefix returns whether the current token is a prefix of the next node,
+// which is the case for the quote, quasiquote and unquote tokens, unless
+// there are comments between them and the next token, in which case we
+// print the current token like an atom to preserve the comments.
+func (b *treeBuilder) isPrefix() bool {
+	switch tok := b.tokens[b.idx]; tok.TokenType {
+	case token.QUOTE, token.QUASIQUOTE, token.UNQUOTE, token.UNQUOTE_SPLICING:
+		return len(tok.Trailing) <= 0 && !hasComments(b.tokens[b.idx+1].Leading)
+	default:
+		return false
+	}
+}
+
+// printer pretty prints the s-expressions tree.
+type printer struct {
+	// out contains the output.
+	out strings.Builder
+
+	// col is the current output column.
+	col int
+
+	// mustBreak indicates that the current line ends with a comment.
+	mustBreak bool
+}
+
+// write writes the given text and updates the current column.
+func (p *printer) write(text string) {
+	p.out.WriteString(text)
+	if idx := strings.LastIndexByte(text, '\n'); idx >= 0 {
+		p.col = utf8.RuneCountInString(text[idx+1:])
+		return
+	}
+	p.col += utf8.RuneCountInString(text)
+}
+
+// newline starts a new line with the given indentation.
+func (p *printer) newline(indent int) {
+	if p.out.Len() > 0 {
+		p.write("\n")
+	}
+	p.write(strings.Repeat(" ", indent))
+	p.mustBreak = false
+}
+
+// blankLine emits an empty line, except at the beginning of the output.
+func (p *printer) blankLine() {
+	if p.out.Len() > 0 {
+		p.write("\n")
+	}
+}
+
+// startLine emits the given leading trivia and starts a new line with the
+// given indentation. When allowBlank is true, we preserve blank lines, though
+// we collapse multiple consecutive blank lines into a single one.
+func (p *printer) startLine(leading []token.Trivia, indent int, allowBlank bool) {
+	if p.emitLeading(leading, indent, allowBlank) {
+		p.blankLine()
+	}
+	p.newline(indent)
+}
+
+// endComments emits the comments in the given trivia, which precede a CLOSE
+// or the EOF token, each on its own line with the given indentation.
+func (p *printer) endComments(leading []token.Trivia, indent int) {
+	p.emitLeading(leading, indent, true)
+}
+
+// emitLeading emits the comments in the leading trivia and returns whether
+// we should emit a blank line before the next line.
+func (p *printer) emitLeading(leading []token.Trivia, indent int, allowBlank bool) bool {
+	pendingBlank := false
+	for _, trivia := range leading {
+		switch trivia.Kind {
+		case token.BLANK:
+			pendingBlank = allowBlank
+
+		case token.COMMENT:
+			if pendingBlank {
+				p.blankLine()
+			}
+			p.newline(indent)
+			p.write(trivia.Value)
+			pendingBlank, allowBlank = false, true
+		}
+	}
+	return pendingBlank
+}
+
+// emitTrailing emits the trailing trivia of the given token, if any.
+func (p *printer) emitTrailing(tok token.Token) {
+	for _, trivia := range tok.Trailing {
+		p.write(" " + trivia.Value)
+		p.mustBreak = true
+	}
+}
+
+// print prints the given node starting at the current column.
+func (p *printer) print(n *node) {
+	p.write(n.prefixText())
+	if !n.isList {
+		text := p.text(n.open)
+		if strings.HasPrefix(text, `"""`) {
+			text = reindent(text, strings.Repeat(" ", p.col))
+		}
+		p.write(text)
+		p.emitTrailing(n.open)
+		return
+	}
+	if text, ok := p.flat(n); ok && p.col+utf8.RuneCountInString(text) <= Width {
+		p.write(text)
+		p.emitTrailing(n.close)
+		return
+	}
+	p.printBroken(n)
+}
+
+// printBroken prints the given list node using multiple lines.
+func (p *printer) printBroken(n *node) {
+	base := p.col
+	p.write("(")
+	p.emitTrailing(n.open)
+
+	// determine how many items we keep on the first line
+	// and the indentation of the items on the next lines
+	sameLine, indent := 1, base+1
+	if len(n.items) > 0 && !n.items[0].isList && n.items[0].open.TokenType == token.ATOM {
+		head := n.items[0].open.Value
+		if count, found := bodyForms[head]; found {
+			sameLine, indent = 1+count, base+indentWidth
+		} else {
+			sameLine, indent = 2, base+1+utf8.RuneCountInString(head)+1
+		}
+	}
+	if len(n.open.Trailing) > 0 {
+		// the first item is on the next line, so we align all the items
+		// to it and, if possible, we print all of them on that line
+		indent = base + 1
+		if text, ok := p.flatItems(n); ok && !mustBreak(n) && indent+utf8.RuneCountInString(text)+1 <= Width {
+			p.newline(indent)
+			p.write(text + ")")
+			p.emitTrailing(n.close)
+			return
+		}
+	}
+
+	// print the items, moving them to the next line when they are preceded
+	// by comments or when they follow a trailing comment, including the one
+	// following the opening parenthesis
+	for idx, item := range n.items {
+		leading := item.first().Leading
+		if idx < sameLine && !p.mustBreak && !hasComments(leading) {
+			if idx > 0 {
+				p.write(" ")
+			}
+			p.print(item)
+			continue
+		}
+		p.startLine(leading, indent, idx > sameLine)
+		p.print(item)
+	}
+
+	// print the comments before the closing parenthesis, if any
+	if hasComments(n.close.Leading) {
+		p.endComments(n.close.Leading, indent)
+		p.mustBreak = true
+	}
+	if p.mustBreak {
+		p.newline(indent)
+	}
+	p.write(")")
+	p.emitTrailing(n.close)
+}
+
+// flat returns the given node formatted on a single line, if possible.
+func (p *printer) flat(n *node) (string, bool) {
+	if !n.isList {
+		text := p.text(n.open)
+		return text, !strings.Contains(text, "\n")
+	}
+	if mustBreak(n) || len(n.open.Trailing) > 0 {
+		return "", false
+	}
+	text, ok := p.flatItems(n)
+	if !ok {
+		return "", false
+	}
+	return "(" + text + ")", true
+}
+
+// flatItems returns the items of the given list node formatted on a single
+// line without the enclosing parentheses, if possible.
+func (p *printer) flatItems(n *node) (string, bool) {
+	parts := make([]string, 0, len(n.items))
+	for _, item := range n.items {
+		if hasComments(item.first().Leading) || len(item.last().Trailing) > 0 {
+			return "", false
+		}
+		text, ok := p.flat(item)
+		if !ok {
+			return "", false
+		}
+		parts = append(parts, item.prefixText()+text)
+	}
+	if hasComments(n.close.Leading) {
+		return "", false
+	}
+	return strings.Join(parts, " "), true
+}
+
+// text returns the source code of the given atom token.
+func (p *printer) text(tok token.Token) string {
+	switch tok.TokenType {
+	case token.STRING, token.CHAR:
+		return tok.Source // exactly as written, e.g., raw strings
+	default:
+		return tok.Value
+	}
+}
+
+// reindent indents the lines of the given triple-quoted string using the given
+// indentation, which does not change its value, because the scanner removes
+// the whitespace prefix common to its lines (see [scanner.Scan]).
+func reindent(text, indent string) string {
+	lines := strings.Split(text, "\n")
+	last := len(lines) - 1
+
+	// 1. compute the common prefix like the scanner does
+	prefix, found := "", false
+	for idx, line := range lines[1:] {
+		if strings.TrimLeft(line, " \t") == "" && idx+1 != last {
+			continue
+		}
+		current := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if !found {
+			prefix, found = current, true
+			continue
+		}
+		for !strings.HasPrefix(current, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	// 2. replace the common prefix with the indentation
+	for idx := 1; idx < len(lines); idx++ {
+		if strings.TrimLeft(lines[idx], " \t") == "" && idx != last {
+			lines[idx] = ""
+			continue
+		}
+		lines[idx] = indent + strings.TrimPrefix(lines[idx], prefix)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// mustBreak returns whether we always print the given list node using
+// multiple lines, which is the case for blocks and conds with more than
+// one expression or case, to make the control flow more readable.
+func mustBreak(n *node) bool {
+	if len(n.items) <= 2 || n.items[0].isList || n.items[0].open.TokenType != token.ATOM {
+		return false
+	}
+	switch n.items[0].open.Value {
+	case "block", "cond":
+		return true
+	default:
+		return false
+	}
+}
+
+// hasComments returns whether the given trivia contains comments.
+func hasComments(trivia []token.Trivia) bool {
+	for _, t := range trivia {
+		if t.Kind == token.COMMENT {
+			return true
+		}
+	}
+	return false
+}
+
+// Error is the error returned when a formatted program is not equivalent
+// to the original program, which indicates a bug in the formatter.
+type Error struct {
+	FileName string
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: formatter: the formatted program differs from the original", e.FileName)
+}
@@ -0,0 +1,132 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package formatter_test
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bassosimone/buresu/internal/txtartesting"
+	"github.com/bassosimone/buresu/pkg/formatter"
+	"github.com/bassosimone/buresu/pkg/scanner"
+	"github.com/bassosimone/buresu/pkg/token"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFormat(t *testing.T) {
+	testCases, err := txtartesting.LoadTestCases("testdata")
+	if err != nil {
+		t.Fatalf("failed to load test cases: %v", err)
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			output, err := formatter.Format("input.brs", []byte(tc.Input))
+			if tc.Error != "" {
+				if err := tc.CompareError(err); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := tc.CompareTextOutput(string(output)); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestFormatIsIdempotent(t *testing.T) {
+	for _, dir := range []string{filepath.Join("..", "..", "example"), filepath.Join("..", "..", "stdlib")} {
+		err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() || filepath.Ext(path) != ".brs" {
+				return err
+			}
+			t.Run(path, func(t *testing.T) {
+				source, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// Format already checks that the program does not change
+				first, err := formatter.Format(path, source)
+				if err != nil {
+					t.Fatal(err)
+				}
+				second, err := formatter.Format(path, first)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if diff := cmp.Diff(string(first), string(second)); diff != "" {
+					t.Fatal(diff)
+				}
+			})
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// countComments returns the number of comments in the given source code.
+func countComments(t *testing.T, source []byte) int {
+	tokens, err := scanner.ScanWithTrivia("input.brs", bytes.NewReader(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	for _, tok := range tokens {
+		for _, trivia := range append(tok.Leading, tok.Trailing...) {
+			if trivia.Kind == token.COMMENT {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func TestFormatPreservesComments(t *testing.T) {
+	// comments after each kind of opening parenthesis, which we
+	// combine with comments in all the other positions
+	corpus := []string{
+		"(lambda ( ; c\n x y) x)",
+		"( ; c\n display 1)",
+		"(let ( ; c\n (a 1)) a)",
+		"(let* ( ; c\n (a 1) (b a)) b)",
+		"(let (( ; c\n a 1)) a)",
+		"(for ( ; c\n i 0 3) i)",
+		"(foreach ( ; c\n x '(1 2)) x)",
+		"'( ; c\n 1 2)",
+		"`( ; c\n 1 ,( ; c\n + 1 2))",
+		"(cond ( ; c\n true 1) (else 2))",
+		"( ; c\n block (display 1) (display 2))",
+		"(if ( ; c\n > 1 2) 1 2) ; c",
+		"( ; c\n ; c\n display ; c\n 1 ; c\n) ; c",
+		"(display ( ; c\n + 1 ( ; c\n * 2 3)))",
+		"( ; c\n lambda (x) ; c\n x)",
+	}
+	for _, source := range corpus {
+		t.Run(source, func(t *testing.T) {
+			formatted, err := formatter.Format("input.brs", []byte(source))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if before, after := countComments(t, []byte(source)), countComments(t, formatted); before != after {
+				t.Fatalf("expected %d comments, got %d in:\n%s", before, after, formatted)
+			}
+			again, err := formatter.Format("input.brs", formatted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(string(formatted), string(again)); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package includer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime/debug"
+	"sync"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/langspec"
+)
+
+// Cache caches the nodes of the files we include, which allows to avoid
+// scanning and parsing the same file content more than once.
+//
+// We only cache the parsed nodes and not the typechecked modules, because
+// the types of the built-in functions contain Go functions, which we cannot
+// store on disk. Each typechecker environment already checks each module
+// once, which is enough for the REPL, which uses a single environment.
+//
+// We key each entry on the canonical path of the file and we store the
+// hash of the file content and language version along with the nodes,
+// which we use when neither has changed. Because we cache the nodes of each
+// file before including other files, and we include files again every time,
+// changing a file does not affect the entries of the files including it.
+//
+// The zero value is invalid; use [NewMemoryCache] or [NewDiskCache].
+type Cache struct {
+	mu    sync.Mutex
+	store cacheStore
+}
+
+// NewMemoryCache returns a [*Cache] keeping entries in memory.
+func NewMemoryCache() *Cache {
+	return &Cache{store: memoryCacheStore{}}
+}
+
+// NewDiskCache returns a [*Cache] keeping entries inside the given
+// directory, which we create when we first need to write an entry.
+//
+// Failing to read or write entries is not fatal and causes cache misses.
+func NewDiskCache(dir string) *Cache {
+	return &Cache{store: &diskCacheStore{dir: dir}}
+}
+
+// cacheEntry is an entry of the [*Cache].
+type cacheEntry struct {
+	// Hash is the hash of the file name and content.
+	Hash string
+
+	// Nodes contains the nodes of the file, before including other files.
+	Nodes []ast.Node
+}
+
+// cacheStore stores the [*Cache] entries.
+type cacheStore interface {
+	load(path string) (*cacheEntry, bool)
+	save(path string, entry *cacheEntry)
+}
+
+// contentHash returns the hash of the given file name and content, parsed using
+// the given language version. We also hash the name because it is part of the
+// position of the tokens inside the nodes, and the version because the nodes
+// depend on it.
+func contentHash(version langspec.Version, filename string, content []byte) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%d\x00", version)
+	hash.Write([]byte(filename))
+	hash.Write([]byte{0})
+	hash.Write(content)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// get returns the nodes of the file with the given canonical path and content
+// hash, or a cache miss when the content hash has changed.
+//
+// This method returns a cache miss when the cache is nil.
+func (c *Cache) get(path, hash string) ([]ast.Node, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, found := c.store.load(path)
+	if !found || entry.Hash != hash {
+		return nil, false
+	}
+	return entry.Nodes, true
+}
+
+// put stores the nodes of the file with the given canonical path and
+// content hash, replacing the entry for the previous content, if any.
+//
+// This method does nothing when the cache is nil.
+func (c *Cache) put(path, hash string, nodes []ast.Node) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.store.save(path, &cacheEntry{Hash: hash, Nodes: nodes})
+}
+
+// memoryCacheStore is a [cacheStore] keeping entries in memory.
+type memoryCacheStore map[string]*cacheEntry
+
+var _ cacheStore = memoryCacheStore{}
+
+// load implements cacheStore.
+func (s memoryCacheStore) load(path string) (*cacheEntry, bool) {
+	entry, found := s[path]
+	return entry, found
+}
+
+// save implements cacheStore.
+func (s memoryCacheStore) save(path string, entry *cacheEntry) {
+	s[path] = entry
+}
+
+// diskCacheStore is a [cacheStore] keeping entries inside a directory.
+//
+// We write the entries inside a subdirectory named after the [diskCacheFormat],
+// and we remove the subdirectories of the other formats when we create it,
+// such that the entries written by older builds do not accumulate.
+type diskCacheStore struct {
+	// dir is the directory containing the subdirectories of each format.
+	dir string
+
+	// pruned indicates that we have removed the entries of the other formats.
+	pruned bool
+}
+
+var _ cacheStore = &diskCacheStore{}
+
+// nodeTypes contains the nodes types, which we register such that we can
+// gob-encode the []ast.Node slices.
+var nodeTypes = []ast.Node{
+	&ast.BlockExpr{},
+	&ast.BreakStmt{},
+	&ast.CallExpr{},
+	&ast.CharLiteral{},
+	&ast.CondExpr{},
+	&ast.ContinueStmt{},
+	&ast.DeclareExpr{},
+	&ast.DefineExpr{},
+	&ast.EllipsisLiteral{},
+	&ast.FalseLiteral{},
+	&ast.FloatLiteral{},
+	&ast.ForExpr{},
+	&ast.ForeachExpr{},
+	&ast.ImportStmt{},
+	&ast.IncludeStmt{},
+	&ast.IntLiteral{},
+	&ast.KeywordLiteral{},
+	&ast.LambdaExpr{},
+	&ast.LetExpr{},
+	&ast.LetStarExpr{},
+	&ast.LetrecExpr{},
+	&ast.ModuleStmt{},
+	&ast.QuasiquoteExpr{},
+	&ast.QuoteExpr{},
+	&ast.RationalLiteral{},
+	&ast.ReturnStmt{},
+	&ast.SetExpr{},
+	&ast.StringLiteral{},
+	&ast.SymbolName{},
+	&ast.TrueLiteral{},
+	&ast.UnitExpr{},
+	&ast.UnquoteExpr{},
+	&ast.WhileExpr{},
+	&ast.YieldStmt{},
+}
+
+func init() {
+	for _, node := range nodeTypes {
+		gob.Register(node)
+	}
+}
+
+// diskCacheFormat identifies the format of the entries, such that we ignore
+// the stale entries written by a build using different nodes or, when the build
+// contains the version control revision, by a different build.
+var diskCacheFormat = newDiskCacheFormat()
+
+// newDiskCacheFormat returns the hash of the layout of the nodes types and
+// of the version control revision of the build, if available.
+func newDiskCacheFormat() string {
+	hash := sha256.New()
+	seen := map[reflect.Type]struct{}{}
+	for _, node := range nodeTypes {
+		writeTypeLayout(hash, reflect.TypeOf(node), seen)
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" || setting.Key == "vcs.modified" {
+				fmt.Fprintf(hash, "%s=%s\n", setting.Key, setting.Value)
+			}
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// writeTypeLayout writes the name of the given type and, recursively, the
+// names and types of its fields, which change when we change the nodes.
+func writeTypeLayout(w io.Writer, t reflect.Type, seen map[reflect.Type]struct{}) {
+	fmt.Fprintf(w, "%s\n", t.String())
+	if _, found := seen[t]; found {
+		return
+	}
+	seen[t] = struct{}{}
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		writeTypeLayout(w, t.Elem(), seen)
+	case reflect.Map:
+		writeTypeLayout(w, t.Key(), seen)
+		writeTypeLayout(w, t.Elem(), seen)
+	case reflect.Struct:
+		for idx := 0; idx < t.NumField(); idx++ {
+			field := t.Field(idx)
+			fmt.Fprintf(w, "%s ", field.Name)
+			writeTypeLayout(w, field.Type, seen)
+		}
+	}
+}
+
+// formatDir returns the directory containing the entries of the current format.
+func (s *diskCacheStore) formatDir() string {
+	return filepath.Join(s.dir, diskCacheFormat)
+}
+
+// entryPath returns the path of the file containing the entry for the given path.
+func (s *diskCacheStore) entryPath(path string) string {
+	hash := sha256.Sum256([]byte(path))
+	return filepath.Join(s.formatDir(), hex.EncodeToString(hash[:])+".gob")
+}
+
+// load implements cacheStore.
+func (s *diskCacheStore) load(path string) (*cacheEntry, bool) {
+	data, err := os.ReadFile(s.entryPath(path))
+	if err != nil {
+		return nil, false
+	}
+	entry := &cacheEntry{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(entry); err != nil {
+		return nil, false
+	}
+	return entry, true
+}
+
+// save implements cacheStore.
+func (s *diskCacheStore) save(path string, entry *cacheEntry) {
+	var buff bytes.Buffer
+	if err := gob.NewEncoder(&buff).Encode(entry); err != nil {
+		return
+	}
+	if err := os.MkdirAll(s.formatDir(), 0700); err != nil {
+		return
+	}
+	s.prune()
+
+	// write a temporary file and rename it such that concurrent
+	// runs never observe partially written entries
+	filep, err := os.CreateTemp(s.formatDir(), "entry-*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = filep.Write(buff.Bytes())
+	if err2 := filep.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		os.Remove(filep.Name())
+		return
+	}
+	if err := os.Rename(filep.Name(), s.entryPath(path)); err != nil {
+		os.Remove(filep.Name())
+	}
+}
+
+// prune removes the subdirectories containing the entries of the other
+// formats, once, where we only consider the subdirectories whose name
+// looks like a format, to avoid removing unrelated files.
+func (s *diskCacheStore) prune() {
+	if s.pruned {
+		return
+	}
+	s.pruned = true
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && entry.Name() != diskCacheFormat && isDiskCacheFormat(entry.Name()) {
+			os.RemoveAll(filepath.Join(s.dir, entry.Name()))
+		}
+	}
+}
+
+// isDiskCacheFormat returns whether the given name looks like a [diskCacheFormat].
+func isDiskCacheFormat(name string) bool {
+	decoded, err := hex.DecodeString(name)
+	return err == nil && len(decoded) == sha256.Size
+}
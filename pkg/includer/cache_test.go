@@ -0,0 +1,168 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package includer
+
+import (
+	"crypto/sha256"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/langspec"
+	"github.com/bassosimone/buresu/pkg/token"
+)
+
+// newIncludeNodes returns the nodes for including the given files.
+func newIncludeNodes(filePaths ...string) []ast.Node {
+	var nodes []ast.Node
+	for _, filePath := range filePaths {
+		nodes = append(nodes, &ast.IncludeStmt{
+			Token:    token.Token{TokenPos: token.Position{FileName: "<stdin>"}, TokenType: token.ATOM, Value: "include!"},
+			FilePath: filePath,
+		})
+	}
+	return nodes
+}
+
+// nodesString returns the lisp source code of the given nodes.
+func nodesString(nodes []ast.Node) string {
+	var parts []string
+	for _, node := range nodes {
+		parts = append(parts, node.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.lisp":   {Data: []byte(`(include! "lib/a.lisp") (define main 1)`)},
+		"lib/a.lisp":  {Data: []byte(`(include! "b.lisp") (define a 1)`)},
+		"lib/b.lisp":  {Data: []byte(`(define b 1)`)},
+		"module.lisp": {Data: []byte(`(module m (export a)) (include! "lib/a.lisp")`)},
+	}
+	searchPath := []Location{{Name: "<mock>", FS: fsys}}
+	cache := NewMemoryCache()
+	store := cache.store.(memoryCacheStore)
+
+	input := append(newIncludeNodes("main.lisp"), &ast.ImportStmt{
+		Token:    token.Token{TokenPos: token.Position{FileName: "<stdin>"}, TokenType: token.ATOM, Value: "import"},
+		FilePath: "module.lisp",
+		Alias:    "m",
+	})
+	first, err := IncludeWithCache(cache, searchPath, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("we cache all the included and imported files", func(t *testing.T) {
+		for _, name := range []string{"main.lisp", "lib/a.lisp", "lib/b.lisp", "module.lisp"} {
+			if _, found := store["<mock>/"+name]; !found {
+				t.Errorf("expected %s to be cached", name)
+			}
+		}
+	})
+
+	t.Run("we get the same nodes using the cache", func(t *testing.T) {
+		second, err := IncludeWithCache(cache, searchPath, input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if nodesString(first) != nodesString(second) {
+			t.Errorf("expected %s, got %s", nodesString(first), nodesString(second))
+		}
+	})
+
+	t.Run("changing a file is visible through the files including it", func(t *testing.T) {
+		fsys["lib/b.lisp"] = &fstest.MapFile{Data: []byte(`(define b 2)`)}
+		hash := contentHash(langspec.Latest, "<mock>/lib/b.lisp", fsys["lib/b.lisp"].Data)
+		if _, found := cache.get("<mock>/lib/b.lisp", hash); found {
+			t.Fatal("expected a cache miss")
+		}
+
+		// the entries of the files including b.lisp are still valid, because we
+		// cache the nodes of each file before including other files
+		result, err := IncludeWithCache(cache, searchPath, newIncludeNodes("main.lisp"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "(define b 2) (define a 1) (define main 1)"
+		if nodesString(result) != expected {
+			t.Errorf("expected %s, got %s", expected, nodesString(result))
+		}
+		if _, found := cache.get("<mock>/lib/b.lisp", hash); !found {
+			t.Error("expected the new content of b.lisp to be cached")
+		}
+	})
+}
+
+func TestDiskCache(t *testing.T) {
+	searchPath := []Location{DirLocation(filepath.Join("..", "..", "stdlib"))}
+	input := newIncludeNodes("runtime/runtime.brs", "prelude.brs")
+
+	// make sure we can serialize all the nodes used by the standard library
+	dir := t.TempDir()
+	first, err := IncludeWithCache(NewDiskCache(dir), searchPath, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := NewDiskCache(dir)
+	second, err := IncludeWithCache(cache, searchPath, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nodesString(first) != nodesString(second) {
+		t.Fatalf("expected %s, got %s", nodesString(first), nodesString(second))
+	}
+
+	// make sure the nodes came from the disk cache
+	key := searchPath[0].canonicalPath("prelude.brs")
+	if _, found := cache.store.load(key); !found {
+		t.Fatal("expected prelude.brs to be cached on disk")
+	}
+}
+
+func TestDiskCacheFormat(t *testing.T) {
+	// the format must depend on the fields of the nodes, including
+	// the fields of the tokens, such that changing them changes it
+	var layout strings.Builder
+	writeTypeLayout(&layout, reflect.TypeOf(&ast.IntLiteral{}), map[reflect.Type]struct{}{})
+	for _, field := range []string{"Token ", "TokenPos ", "LineNumber ", "Value "} {
+		if !strings.Contains(layout.String(), field) {
+			t.Fatalf("expected %q in %s", field, layout.String())
+		}
+	}
+	if diskCacheFormat != newDiskCacheFormat() {
+		t.Fatal("expected the format to be deterministic")
+	}
+}
+
+func TestDiskCachePrune(t *testing.T) {
+	dir := t.TempDir()
+	stale := filepath.Join(dir, strings.Repeat("ab", sha256.Size))
+	unrelated := filepath.Join(dir, "unrelated")
+	for _, path := range []string{stale, unrelated} {
+		if err := os.MkdirAll(path, 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cache := NewDiskCache(dir)
+	cache.put("<mock>/main.lisp", "hash", nil)
+
+	// we remove the entries of the other formats but not unrelated files
+	if _, err := os.Stat(stale); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected %s to be removed, got %v", stale, err)
+	}
+	if _, err := os.Stat(unrelated); err != nil {
+		t.Errorf("expected %s to exist, got %v", unrelated, err)
+	}
+	if _, found := cache.get("<mock>/main.lisp", "hash"); !found {
+		t.Error("expected main.lisp to be cached")
+	}
+}
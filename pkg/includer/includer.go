@@ -3,66 +3,183 @@
 package includer
 
 import (
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io/fs"
 	"strings"
 
 	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/langspec"
 	"github.com/bassosimone/buresu/pkg/parser"
 	"github.com/bassosimone/buresu/pkg/scanner"
 	"github.com/bassosimone/buresu/pkg/token"
 )
 
-// Mock the os.ReadFile function
-var readFile = os.ReadFile
+// Include processes the given AST and handles include and import statements.
+//
+// We search each included file in the directory of the including file
+// and then in each directory of the given search path, in order.
+func Include(searchPath []Location, nodes []ast.Node) ([]ast.Node, error) {
+	return IncludeWithCache(nil, searchPath, nodes)
+}
 
-// Include processes the given AST and handles include statements.
-func Include(basePath string, nodes []ast.Node) ([]ast.Node, error) {
-	inc := newIncluder(basePath)
+// IncludeWithCache is like [Include] but uses the given [*Cache], which may
+// be nil, to avoid scanning and parsing again files whose content did not change.
+func IncludeWithCache(cache *Cache, searchPath []Location, nodes []ast.Node) ([]ast.Node, error) {
+	inc := newIncluder(cache, searchPath)
 	return inc.includeNodes(nodes)
 }
 
+// Includer is like [IncludeWithCache] but processes a program one
+// top-level form at a time, remembering the files we have already included
+// and the modules we have already imported across calls to [*Includer.Include].
+//
+// Use [New] to construct.
+type Includer struct {
+	inc *includer
+}
+
+// New creates a new [*Includer] using the given [*Cache], which may be
+// nil, and searching the included files in the given search path.
+//
+// The includer parses the [langspec.Latest] version of the language.
+func New(cache *Cache, searchPath []Location) *Includer {
+	return NewWithVersion(cache, searchPath, langspec.Latest)
+}
+
+// NewWithVersion is like [New] but parses the included and imported
+// files, including the entry point of bundles, using the given version.
+func NewWithVersion(cache *Cache, searchPath []Location, version langspec.Version) *Includer {
+	inc := newIncluder(cache, searchPath)
+	inc.version = version
+	return &Includer{inc}
+}
+
+// Include processes the given AST and handles include and import statements.
+func (i *Includer) Include(nodes []ast.Node) ([]ast.Node, error) {
+	return i.inc.includeNodes(nodes)
+}
+
+// Resolve returns the canonical path of the file that an include or import
+// statement of the given file would load, searching it like [Include] does.
+//
+// The canonical path is the cleaned absolute path for files in the host
+// file system and otherwise the name of the file inside its [Location].
+func Resolve(searchPath []Location, tok token.Token, filename string) (string, error) {
+	_, key, _, err := newIncluder(nil, searchPath).findFile(tok, filename)
+	return key, err
+}
+
 // Error represents a parsing error with position and message.
 type Error struct {
 	Tok     token.Token
 	Message string
+
+	// Tried contains the locations we tried when we could not find a file.
+	Tried []string
+
+	// End is the position right after the statement causing the error.
+	End token.Position
+}
+
+// Span returns the source code span of the statement causing the error.
+func (e *Error) Span() token.Span {
+	return token.Span{Start: e.Tok.TokenPos, End: e.End}
 }
 
 // Error returns the error message with file position details.
 func (e *Error) Error() string {
+	message := e.Message
+	if len(e.Tried) > 0 {
+		message = fmt.Sprintf("%s (tried: %s)", message, strings.Join(e.Tried, ", "))
+	}
 	return fmt.Sprintf(
 		"%s:%d:%d: includer: %s",
 		e.Tok.TokenPos.FileName,
 		e.Tok.TokenPos.LineNumber,
 		e.Tok.TokenPos.LineColumn,
-		e.Message,
+		message,
 	)
 }
 
 // newError formats and returns a new parser error including the token context.
 func newError(tok token.Token, format string, args ...any) *Error {
-	return &Error{Tok: tok, Message: fmt.Sprintf(format, args...)}
+	return &Error{Tok: tok, Message: fmt.Sprintf(format, args...), End: tok.TokenEnd}
+}
+
+// extendError extends the span of the given error to the end of the given
+// statement, if the error refers to the token starting the statement.
+func extendError(err error, tok token.Token, end token.Position) error {
+	var ierr *Error
+	if errors.As(err, &ierr) && ierr.Tok.TokenPos == tok.TokenPos {
+		ierr.End = end
+	}
+	return err
 }
 
 // includer processes the AST and handles include statements.
 type includer struct {
-	// basePath is the base path for all included files.
-	basePath string
+	// cache is the optional cache of the files nodes.
+	cache *Cache
+
+	// searchPath contains the locations where to search for included files.
+	searchPath []Location
+
+	// version is the language version we use to parse the files.
+	version langspec.Version
+
+	// origins maps the names of the files we have found outside of the host
+	// file system to their origin (see [*includer.recordOrigin]).
+	origins map[string]origin
 
 	// cycle is a temporary map to detect whether we are in an inclusion cycle.
 	cycle map[string]struct{}
 
+	// chain contains the names of the files we are currently including.
+	chain []string
+
 	// visited is a persistent map to detect whether we have already visited a file.
 	visited map[string]struct{}
+
+	// importing is a temporary map to detect whether we are in an import cycle.
+	importing map[string]struct{}
+
+	// importChain contains the names of the modules we are currently importing.
+	importChain *[]string
+
+	// modules caches the nodes of each module we have already imported.
+	modules map[string][]ast.Node
 }
 
 // newIncluder creates a new includer instance.
-func newIncluder(basePath string) *includer {
+func newIncluder(cache *Cache, searchPath []Location) *includer {
 	return &includer{
-		basePath: basePath,
-		cycle:    map[string]struct{}{},
-		visited:  map[string]struct{}{},
+		cache:       cache,
+		searchPath:  searchPath,
+		version:     langspec.Latest,
+		origins:     map[string]origin{},
+		cycle:       map[string]struct{}{},
+		visited:     map[string]struct{}{},
+		importing:   map[string]struct{}{},
+		importChain: &[]string{},
+		modules:     map[string][]ast.Node{},
+	}
+}
+
+// newModuleIncluder creates a new includer for processing the includes of
+// a module, which has its own namespace and therefore needs to include files
+// independently of its importer, but shares the modules state.
+func (inc *includer) newModuleIncluder() *includer {
+	return &includer{
+		cache:       inc.cache,
+		searchPath:  inc.searchPath,
+		version:     inc.version,
+		origins:     inc.origins,
+		cycle:       map[string]struct{}{},
+		visited:     map[string]struct{}{},
+		importing:   inc.importing,
+		importChain: inc.importChain,
+		modules:     inc.modules,
 	}
 }
 
@@ -76,12 +193,29 @@ func (inc *includer) includeNodes(nodes []ast.Node) ([]ast.Node, error) {
 		if found {
 			processedNodes, err := inc.includeFileOnce(includenode.Token, includenode.FilePath)
 			if err != nil {
-				return nil, err
+				return nil, extendError(err, includenode.Token, includenode.End)
 			}
 			result = append(result, processedNodes...)
 			continue
 		}
 
+		// if the node is an import statement, load the module
+		importnode, found := node.(*ast.ImportStmt)
+		if found {
+			filename, moduleNodes, err := inc.importModuleOnce(importnode.Token, importnode.FilePath)
+			if err != nil {
+				return nil, extendError(err, importnode.Token, importnode.End)
+			}
+			result = append(result, &ast.ImportStmt{
+				Token:    importnode.Token,
+				End:      importnode.End,
+				FilePath: filename,
+				Alias:    importnode.Alias,
+				Nodes:    moduleNodes,
+			})
+			continue
+		}
+
 		// otherwise just append the node to the result
 		result = append(result, node)
 	}
@@ -90,46 +224,148 @@ func (inc *includer) includeNodes(nodes []ast.Node) ([]ast.Node, error) {
 
 // includeFileOnce includes a file unless it has already been included and
 // returns an error if we detect an inclusion cycle.
-func (inc *includer) includeFileOnce(tok token.Token, filename string) ([]ast.Node, error) {
+func (inc *includer) includeFileOnce(tok token.Token, filename string) (_ []ast.Node, err error) {
+	// Find the file using the search path
+	filename, key, content, err := inc.findFile(tok, filename)
+	if err != nil {
+		return nil, err
+	}
+
 	// Detect inclusion cycles
-	if _, ok := inc.cycle[filename]; ok {
-		return nil, newError(tok, "inclusion cycle detected for file %s", filename)
+	if _, ok := inc.cycle[key]; ok {
+		return nil, newError(tok, "inclusion cycle detected for file %s (include chain: %s)",
+			filename, formatChain(inc.chain, filename))
 	}
 
 	// Make sure we have not already visited this file
-	if _, ok := inc.visited[filename]; ok {
+	if _, ok := inc.visited[key]; ok {
 		return nil, nil
 	}
 
-	// Mark the file as being under processing right now, then
-	// uncover the file and mark it as visited
-	inc.cycle[filename] = struct{}{}
-	nodes, err := inc.includeFile(tok, filename)
+	// Mark the file as being under processing right now, then uncover the
+	// file and mark it as visited, unless we fail, such that, when we process
+	// a program one form at a time, including the file again works as usual
+	inc.cycle[key] = struct{}{}
+	inc.chain = append(inc.chain, filename)
+	defer func() {
+		delete(inc.cycle, key)
+		inc.chain = inc.chain[:len(inc.chain)-1]
+		if err != nil {
+			delete(inc.visited, key)
+		}
+	}()
+	nodes, err := inc.parseFileCached(tok, filename, key, content)
 	if err != nil {
 		return nil, err
 	}
-	inc.visited[filename] = struct{}{}
+	inc.visited[key] = struct{}{}
 
 	// Recursively include based on the current set of nodes
-	result, err := inc.includeNodes(nodes)
+	return inc.includeNodes(nodes)
+}
 
-	// Stop visiting the file once we have finished
-	// recursively including all its nodes
-	delete(inc.cycle, filename)
-	return result, err
+// importModuleOnce loads a module unless it has already been loaded and
+// returns an error if we detect an import cycle. On success, it returns
+// the canonical path of the module file along with the module nodes.
+func (inc *includer) importModuleOnce(tok token.Token, filename string) (string, []ast.Node, error) {
+	// Find the file using the search path
+	filename, key, content, err := inc.findFile(tok, filename)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Reuse the nodes of modules we have already loaded
+	if nodes, ok := inc.modules[key]; ok {
+		return key, nodes, nil
+	}
+
+	// Detect import cycles
+	if _, ok := inc.importing[key]; ok {
+		return "", nil, newError(tok, "import cycle detected for module %s (import chain: %s)",
+			filename, formatChain(*inc.importChain, filename))
+	}
+	inc.importing[key] = struct{}{}
+	*inc.importChain = append(*inc.importChain, filename)
+	defer func() {
+		delete(inc.importing, key)
+		*inc.importChain = (*inc.importChain)[:len(*inc.importChain)-1]
+	}()
+
+	// Parse the module and make sure it starts with a module declaration
+	nodes, err := inc.parseFileCached(tok, filename, key, content)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(nodes) <= 0 {
+		return "", nil, newError(tok, "file %s is not a module: missing module declaration", filename)
+	}
+	if _, ok := nodes[0].(*ast.ModuleStmt); !ok {
+		return "", nil, newError(tok, "file %s is not a module: missing module declaration", filename)
+	}
+
+	// Recursively include and import using the module namespace
+	moduleInc := inc.newModuleIncluder()
+	result, err := moduleInc.includeNodes(nodes)
+	if err != nil {
+		return "", nil, err
+	}
+	inc.modules[key] = result
+	return key, result, nil
+}
+
+// findFile searches the given file in the directory of the file containing
+// the given token and then in the search path, and returns the name of the
+// first file that exists, its canonical path, and its content.
+//
+// The canonical path identifies the file regardless of how we have reached
+// it (e.g., `lib/a.brs` and `./lib/../lib/a.brs`) and is the cleaned
+// absolute path for files in the host file system.
+func (inc *includer) findFile(tok token.Token, filename string) (string, string, []byte, error) {
+	var tried []string
+	for _, c := range inc.candidates(tok.TokenPos.FileName, filename) {
+		name := c.loc.fileName(c.name)
+		if !fs.ValidPath(c.name) {
+			continue // e.g., the path escapes the location using `..`
+		}
+		content, err := fs.ReadFile(c.loc.FS, c.name)
+		if err == nil {
+			inc.recordOrigin(name, c)
+			return name, c.loc.canonicalPath(c.name), content, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", "", nil, newError(tok, "failed to read file %s: %v", name, err)
+		}
+		tried = append(tried, name)
+	}
+	err := newError(tok, "cannot find file %s", filename)
+	err.Tried = tried
+	return "", "", nil, err
 }
 
-// includeFile includes a file and returns all its nodes.
-func (inc *includer) includeFile(tok token.Token, filename string) ([]ast.Node, error) {
-	// Obtain a file name within the basepath.
-	filename = filepath.Join(inc.basePath, filepath.FromSlash(filename))
+// formatChain formats the chain of files leading to the given file.
+func formatChain(chain []string, filename string) string {
+	return strings.Join(append(append([]string{}, chain...), filename), " -> ")
+}
 
-	// Load the file content.
-	content, err := readFile(filename)
+// parseFileCached is like parseFile but returns the cached nodes, if
+// possible, given the file canonical path and content, and otherwise
+// caches the nodes it parses.
+func (inc *includer) parseFileCached(
+	tok token.Token, filename, key string, content []byte) ([]ast.Node, error) {
+	hash := contentHash(inc.version, filename, content)
+	if nodes, found := inc.cache.get(key, hash); found {
+		return nodes, nil
+	}
+	nodes, err := inc.parseFile(tok, filename, content)
 	if err != nil {
-		return nil, newError(tok, "failed to read file %s: %v", filename, err)
+		return nil, err
 	}
+	inc.cache.put(key, hash, nodes)
+	return nodes, nil
+}
 
+// parseFile scans and parses the given file content and returns all its nodes.
+func (inc *includer) parseFile(tok token.Token, filename string, content []byte) ([]ast.Node, error) {
 	// Scan the file content.
 	tokens, err := scanner.Scan(filename, strings.NewReader(string(content)))
 	if err != nil {
@@ -137,7 +373,7 @@ func (inc *includer) includeFile(tok token.Token, filename string) ([]ast.Node,
 	}
 
 	// Parse the tokens.
-	includedNodes, err := parser.Parse(tokens)
+	includedNodes, err := parser.ParseWithVersion(tokens, inc.version)
 	if err != nil {
 		return nil, newError(tok, "failed to parse file %s: %v", filename, err)
 	}
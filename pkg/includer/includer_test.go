@@ -2,52 +2,76 @@ package includer
 
 import (
 	"errors"
-	"os"
+	"io/fs"
+	"path/filepath"
 	"strings"
 	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
 
 	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/langspec"
 	"github.com/bassosimone/buresu/pkg/token"
 )
 
-// mockReadFile is a mock implementation of the os.ReadFile function.
-func mockReadFile(filename string) ([]byte, error) {
-	switch filename {
-	case "file1.lisp":
-		return []byte(`(define x 42)`), nil
-
-	case "file2.lisp":
-		return []byte(`(define y 43)`), nil
-
-	case "cycle.lisp":
-		return []byte(`(include! "cycle.lisp")`), nil
+// mockFS is a mock file system containing the files to include.
+var mockFS = forbiddenFS{fstest.MapFS{
+	"file1.lisp":         {Data: []byte(`(define x 42)`)},
+	"file2.lisp":         {Data: []byte(`(define y 43)`)},
+	"cycle.lisp":         {Data: []byte(`(include! "cycle.lisp")`)},
+	"invalid_scan.lisp":  {Data: []byte(`@`)},
+	"invalid_parse.lisp": {Data: []byte(`(if`)},
+	"fileX.lisp":         {Data: []byte(`(include! "fileY.lisp") (include! "fileZ.lisp") (include! "fileZ.lisp")`)},
+	"fileY.lisp":         {Data: []byte(`(include! "fileZ.lisp")`)},
+	"fileZ.lisp":         {Data: []byte(`(define z 44)`)},
+	"module1.lisp":       {Data: []byte(`(module m (export x)) (include! "file1.lisp")`)},
+	"module2.lisp":       {Data: []byte(`(module n (export y)) (import "module1.lisp" as m) (define y m/x)`)},
+	"importcycle.lisp":   {Data: []byte(`(module c (export)) (import "importcycle.lisp" as c)`)},
+	"empty.lisp":         {Data: []byte(``)},
+	"lib/file1.lisp":     {Data: []byte(`(define x 1)`)},
+	"sys/file1.lisp":     {Data: []byte(`(define x 2)`)},
+	"sys/file3.lisp":     {Data: []byte(`(define x 3)`)},
+	"lib/includer.lisp":  {Data: []byte(`(include! "file3.lisp")`)},
+	"lib/file3.lisp":     {Data: []byte(`(define x 4)`)},
+	"lib/escape.lisp":    {Data: []byte(`(include! "../file2.lisp")`)},
+	"chainA.lisp":        {Data: []byte(`(include! "lib/chainB.lisp")`)},
+	"lib/chainB.lisp":    {Data: []byte(`(include! "./sub/../../chainA.lisp")`)},
+	"dedup.lisp":         {Data: []byte(`(include! "lib/file1.lisp") (include! "./lib/../lib/file1.lisp")`)},
+}}
 
-	case "invalid_scan.lisp":
-		return []byte(`@`), nil
-
-	case "invalid_parse.lisp":
-		return []byte(`(if`), nil
-
-	case "fileX.lisp":
-		return []byte(`(include! "fileY.lisp") (include! "fileZ.lisp") (include! "fileZ.lisp")`), nil
+// forbiddenFS is a file system failing to open forbidden.lisp.
+type forbiddenFS struct {
+	fs.FS
+}
 
-	case "fileY.lisp":
-		return []byte(`(include! "fileZ.lisp")`), nil
+// Open implements fs.FS.
+func (fsys forbiddenFS) Open(name string) (fs.File, error) {
+	if name == "forbidden.lisp" {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+	}
+	return fsys.FS.Open(name)
+}
 
-	case "fileZ.lisp":
-		return []byte(`(define z 44)`), nil
+// mockDirFS is a mock implementation of the os.DirFS function.
+func mockDirFS(dir string) fs.FS {
+	fsys, err := fs.Sub(mockFS, filepath.ToSlash(filepath.Clean(dir)))
+	if err != nil {
+		panic(err)
+	}
+	return fsys
+}
 
-	default:
-		return nil, errors.New("file not found")
+// mockSearchPath returns a search path using mockDirFS.
+func mockSearchPath(dirs ...string) []Location {
+	var searchPath []Location
+	for _, dir := range dirs {
+		searchPath = append(searchPath, Location{Name: dir, FS: mockDirFS(dir)})
 	}
+	return searchPath
 }
 
 func TestInclude(t *testing.T) {
-	// Override the os.ReadFile function with the mock and restore
-	// the original function after the test
-	readFile = mockReadFile
-	defer func() { readFile = os.ReadFile }()
-
 	// Allows to temporarily skip all tests such that we can
 	// only run the ones marked as overrideSkip
 	skipAll := false
@@ -91,6 +115,38 @@ func TestInclude(t *testing.T) {
 			overrideSkip: false,
 		},
 
+		{
+			name: "include with cycle through different paths",
+			input: []ast.Node{
+				&ast.IncludeStmt{
+					Token:    token.Token{TokenType: token.ATOM, Value: "include!"},
+					FilePath: "chainA.lisp",
+				},
+			},
+			expected:     nil,
+			err:          "inclusion cycle detected for file chainA.lisp (include chain: chainA.lisp -> lib/chainB.lisp -> chainA.lisp)",
+			overrideSkip: false,
+		},
+
+		{
+			name: "include once using different paths",
+			input: []ast.Node{
+				&ast.IncludeStmt{
+					Token:    token.Token{TokenType: token.ATOM, Value: "include!"},
+					FilePath: "dedup.lisp",
+				},
+			},
+			expected: []ast.Node{
+				&ast.DefineExpr{
+					Token:  token.Token{TokenType: token.ATOM, Value: "define"},
+					Symbol: "x",
+					Expr:   &ast.IntLiteral{Token: token.Token{TokenType: token.NUMBER, Value: "1"}, Value: "1"},
+				},
+			},
+			err:          "",
+			overrideSkip: false,
+		},
+
 		{
 			name: "read file error",
 			input: []ast.Node{
@@ -100,7 +156,7 @@ func TestInclude(t *testing.T) {
 				},
 			},
 			expected:     nil,
-			err:          "failed to read file nonexistent.lisp",
+			err:          "cannot find file nonexistent.lisp (tried: nonexistent.lisp)",
 			overrideSkip: false,
 		},
 
@@ -148,6 +204,68 @@ func TestInclude(t *testing.T) {
 			err:          "",
 			overrideSkip: false,
 		},
+
+		{
+			name: "simple import",
+			input: []ast.Node{
+				&ast.ImportStmt{
+					Token:    token.Token{TokenType: token.ATOM, Value: "import"},
+					FilePath: "module1.lisp",
+					Alias:    "m",
+				},
+			},
+			expected: []ast.Node{
+				&ast.ImportStmt{
+					Token:    token.Token{TokenType: token.ATOM, Value: "import"},
+					FilePath: "module1.lisp",
+					Alias:    "m",
+				},
+			},
+			err:          "",
+			overrideSkip: false,
+		},
+
+		{
+			name: "import of a file that is not a module",
+			input: []ast.Node{
+				&ast.ImportStmt{
+					Token:    token.Token{TokenType: token.ATOM, Value: "import"},
+					FilePath: "file1.lisp",
+					Alias:    "f",
+				},
+			},
+			expected:     nil,
+			err:          "file file1.lisp is not a module: missing module declaration",
+			overrideSkip: false,
+		},
+
+		{
+			name: "import of an empty file",
+			input: []ast.Node{
+				&ast.ImportStmt{
+					Token:    token.Token{TokenType: token.ATOM, Value: "import"},
+					FilePath: "empty.lisp",
+					Alias:    "e",
+				},
+			},
+			expected:     nil,
+			err:          "file empty.lisp is not a module: missing module declaration",
+			overrideSkip: false,
+		},
+
+		{
+			name: "import with cycle",
+			input: []ast.Node{
+				&ast.ImportStmt{
+					Token:    token.Token{TokenType: token.ATOM, Value: "import"},
+					FilePath: "importcycle.lisp",
+					Alias:    "c",
+				},
+			},
+			expected:     nil,
+			err:          "import cycle detected for module importcycle.lisp (import chain: importcycle.lisp -> importcycle.lisp)",
+			overrideSkip: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -156,7 +274,7 @@ func TestInclude(t *testing.T) {
 				t.Skip("skipAll is true and overrideSkip is false")
 			}
 
-			result, err := Include(".", tt.input)
+			result, err := Include(mockSearchPath("."), tt.input)
 
 			if tt.err != "" {
 				if err == nil || !strings.Contains(err.Error(), tt.err) {
@@ -183,3 +301,343 @@ func TestInclude(t *testing.T) {
 		})
 	}
 }
+
+func TestImport(t *testing.T) {
+	input := []ast.Node{
+		// the module includes file1.lisp, which must be included
+		// again inside the module, which has its own namespace
+		&ast.IncludeStmt{
+			Token:    token.Token{TokenType: token.ATOM, Value: "include!"},
+			FilePath: "file1.lisp",
+		},
+		&ast.ImportStmt{
+			Token:    token.Token{TokenType: token.ATOM, Value: "import"},
+			FilePath: "module2.lisp",
+			Alias:    "n",
+		},
+		&ast.ImportStmt{
+			Token:    token.Token{TokenType: token.ATOM, Value: "import"},
+			FilePath: "module1.lisp",
+			Alias:    "m",
+		},
+	}
+
+	result, err := Include(mockSearchPath("."), input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 3 {
+		t.Fatalf("expected 3 nodes, got %d", len(result))
+	}
+
+	t.Run("the module includes its own copy of the included files", func(t *testing.T) {
+		module1 := result[2].(*ast.ImportStmt)
+		var got []string
+		for _, node := range module1.Nodes {
+			got = append(got, node.String())
+		}
+		expected := "(module m (export x)) (define x 42)"
+		if strings.Join(got, " ") != expected {
+			t.Errorf("expected %s, got %s", expected, strings.Join(got, " "))
+		}
+	})
+
+	t.Run("imports of the same module share the nodes", func(t *testing.T) {
+		module2 := result[1].(*ast.ImportStmt)
+		nested := module2.Nodes[1].(*ast.ImportStmt)
+		module1 := result[2].(*ast.ImportStmt)
+		if len(nested.Nodes) <= 0 || &nested.Nodes[0] != &module1.Nodes[0] {
+			t.Error("expected imports of the same module to share the nodes")
+		}
+	})
+}
+
+func TestIncludeSearchPath(t *testing.T) {
+	tests := []struct {
+		name       string
+		fileName   string
+		filePath   string
+		searchPath []Location
+		expected   string
+		err        string
+	}{
+		{
+			name:       "first directory in the search path wins",
+			fileName:   "<stdin>",
+			filePath:   "file1.lisp",
+			searchPath: mockSearchPath("lib", "sys"),
+			expected:   "(define x 1)",
+		},
+		{
+			name:       "we fall back to the next directory",
+			fileName:   "<stdin>",
+			filePath:   "file3.lisp",
+			searchPath: mockSearchPath("lib", "sys"),
+			expected:   "(define x 4)",
+		},
+		{
+			name:       "nonexistent directories are skipped",
+			fileName:   "<stdin>",
+			filePath:   "file3.lisp",
+			searchPath: mockSearchPath("nonexistent", "sys"),
+			expected:   "(define x 3)",
+		},
+		{
+			name:       "the directory of the including file comes first",
+			fileName:   "testdata/lib/main.lisp",
+			filePath:   "file3.lisp",
+			searchPath: mockSearchPath("sys"),
+			expected:   "(define x 4)",
+		},
+		{
+			name:       "nested includes use the directory of the including file",
+			fileName:   "<stdin>",
+			filePath:   "includer.lisp",
+			searchPath: mockSearchPath("sys", "lib"),
+			expected:   "(define x 4)",
+		},
+		{
+			name:       "the directory of the including file may be a location",
+			fileName:   "<stdin>",
+			filePath:   "includer.lisp",
+			searchPath: []Location{{Name: "<mock>", FS: mockDirFS("lib")}},
+			expected:   "(define x 4)",
+		},
+		{
+			name:       "host files may include files of parent directories",
+			fileName:   "testdata/main.lisp",
+			filePath:   "lib/b.lisp",
+			searchPath: nil,
+			expected:   "(define c 5)",
+		},
+		{
+			name:       "host files in the search path may include files of parent directories",
+			fileName:   "<stdin>",
+			filePath:   "b.lisp",
+			searchPath: []Location{DirLocation(filepath.Join("testdata", "lib"))},
+			expected:   "(define c 5)",
+		},
+		{
+			name:       "paths escaping a location are not found",
+			fileName:   "<stdin>",
+			filePath:   "escape.lisp",
+			searchPath: []Location{{Name: "<mock>", FS: mockDirFS("lib")}},
+			err:        "<mock>/escape.lisp:1:1: includer: cannot find file ../file2.lisp",
+		},
+		{
+			name:       "we list all the locations we tried",
+			fileName:   "main.lisp",
+			filePath:   "nonexistent.lisp",
+			searchPath: mockSearchPath("lib", "sys"),
+			err:        "main.lisp:0:0: includer: cannot find file nonexistent.lisp (tried: nonexistent.lisp, lib/nonexistent.lisp, sys/nonexistent.lisp)",
+		},
+		{
+			name:       "we stop at errors other than nonexistent files",
+			fileName:   "<stdin>",
+			filePath:   "forbidden.lisp",
+			searchPath: mockSearchPath(".", "sys"),
+			err:        "<stdin>:0:0: includer: failed to read file forbidden.lisp: open forbidden.lisp: permission denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := []ast.Node{
+				&ast.IncludeStmt{
+					Token: token.Token{
+						TokenPos:  token.Position{FileName: tt.fileName},
+						TokenType: token.ATOM,
+						Value:     "include!",
+					},
+					FilePath: tt.filePath,
+				},
+			}
+
+			result, err := Include(tt.searchPath, input)
+
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected error %s, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result) != 1 || result[0].String() != tt.expected {
+				t.Fatalf("expected %s, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestIncludeCanonicalPath(t *testing.T) {
+	abspath, err := filepath.Abs(filepath.Join("testdata", "lib", "file3.lisp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	newInclude := func(filePath string) ast.Node {
+		return &ast.IncludeStmt{
+			Token: token.Token{
+				TokenPos:  token.Position{FileName: filepath.Join("testdata", "lib", "main.lisp")},
+				TokenType: token.ATOM,
+				Value:     "include!",
+			},
+			FilePath: filePath,
+		}
+	}
+
+	// the relative and the absolute path refer to the same file, which
+	// we should therefore include just once
+	input := []ast.Node{newInclude("file3.lisp"), newInclude(abspath), newInclude("../lib/file3.lisp")}
+	result, err := Include(nil, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 {
+		t.Fatalf("expected 1 node, got %d", len(result))
+	}
+}
+
+func TestIncluder(t *testing.T) {
+	newInclude := func(filePath string) []ast.Node {
+		return []ast.Node{&ast.IncludeStmt{
+			Token:    token.Token{TokenType: token.ATOM, Value: "include!"},
+			FilePath: filePath,
+		}}
+	}
+
+	// including the same file using distinct calls, as we do when we
+	// process a program one form at a time, should include it just once
+	inc := New(nil, mockSearchPath("."))
+	first, err := inc.Include(newInclude("file1.lisp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != 1 || first[0].String() != "(define x 42)" {
+		t.Fatalf("unexpected nodes: %v", first)
+	}
+	second, err := inc.Include(newInclude("file1.lisp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(second) != 0 {
+		t.Fatalf("expected 0 nodes, got %d", len(second))
+	}
+}
+
+func TestIncluderAfterError(t *testing.T) {
+	newInclude := func(filePath string) []ast.Node {
+		return []ast.Node{&ast.IncludeStmt{
+			Token:    token.Token{TokenType: token.ATOM, Value: "include!"},
+			FilePath: filePath,
+		}}
+	}
+
+	// a failed include must not leave behind the state we use to detect
+	// cycles, otherwise including the file again reports a false cycle
+	inc := New(nil, mockSearchPath("."))
+	for idx := 0; idx < 2; idx++ {
+		_, err := inc.Include(newInclude("invalid_parse.lisp"))
+		if err == nil || strings.Contains(err.Error(), "cycle") {
+			t.Fatalf("attempt %d: unexpected error: %v", idx+1, err)
+		}
+	}
+}
+
+func TestIncluderWithVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.lisp": {Data: []byte(`(include! "lib.lisp")`)},
+		"lib.lisp":  {Data: []byte(`(define x 1) (break!)`)},
+	}
+	searchPath := []Location{{Name: "<mock>", FS: fsys}}
+	input := []ast.Node{&ast.IncludeStmt{
+		Token:    token.Token{TokenType: token.ATOM, Value: "include!"},
+		FilePath: "main.lisp",
+	}}
+	cache := NewMemoryCache()
+
+	// version 1 does not reserve `break!`, hence the included file
+	// contains a call, while later versions reject the statement
+	nodes, err := NewWithVersion(cache, searchPath, langspec.Version1).Include(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 2 {
+		t.Fatalf("expected 2 nodes, got %d", len(nodes))
+	}
+	if _, ok := nodes[1].(*ast.CallExpr); !ok {
+		t.Fatalf("expected a call, got %T", nodes[1])
+	}
+
+	// the cache must not return the nodes parsed using another version
+	if _, err := New(cache, searchPath).Include(input); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	abspath, err := filepath.Abs(filepath.Join("testdata", "lib", "file3.lisp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tok := token.Token{TokenPos: token.Position{FileName: filepath.Join("testdata", "main.lisp")}}
+
+	t.Run("for files in the host file system", func(t *testing.T) {
+		got, err := Resolve(nil, tok, "lib/../lib/file3.lisp")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != abspath {
+			t.Fatalf("expected %s, got %s", abspath, got)
+		}
+	})
+
+	t.Run("for files inside a location", func(t *testing.T) {
+		got, err := Resolve([]Location{{Name: "<mock>", FS: mockDirFS("lib")}}, tok, "includer.lisp")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := filepath.Join("<mock>", "includer.lisp"); got != expected {
+			t.Fatalf("expected %s, got %s", expected, got)
+		}
+	})
+
+	t.Run("for nonexistent files", func(t *testing.T) {
+		_, err := Resolve(nil, tok, "nonexistent.lisp")
+		if err == nil || !strings.Contains(err.Error(), "cannot find file nonexistent.lisp") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestErrorSpan(t *testing.T) {
+	start := token.Position{FileName: "main.lisp", LineNumber: 1, LineColumn: 1}
+	end := token.Position{FileName: "main.lisp", LineNumber: 1, LineColumn: 26, Offset: 25}
+	nodes := []ast.Node{&ast.IncludeStmt{
+		Token:    token.Token{TokenPos: start, TokenType: token.OPEN, Value: "("},
+		End:      end,
+		FilePath: "nonexistent.lisp",
+	}}
+	_, err := Include(mockSearchPath("."), nodes)
+	var ierr *Error
+	if !errors.As(err, &ierr) {
+		t.Fatalf("expected *Error, got %v", err)
+	}
+	if diff := cmp.Diff(token.Span{Start: start, End: end}, ierr.Span()); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestNewSearchPath(t *testing.T) {
+	t.Setenv(EnvSearchPath, strings.Join([]string{"env1", "", "env2"}, string(filepath.ListSeparator)))
+	stdlib := Location{Name: "<stdlib>", FS: fstest.MapFS{}}
+	var got []string
+	for _, loc := range NewSearchPath([]string{"inc1", "inc2"}, stdlib) {
+		got = append(got, loc.Name)
+	}
+	expected := []string{"inc1", "inc2", "env1", "env2", "<stdlib>"}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Fatal(diff)
+	}
+}
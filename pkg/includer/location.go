@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package includer
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Location is a file system in which we search for included files.
+type Location struct {
+	// Name identifies the location and we use it as the prefix of the
+	// names of the files we find in FS (e.g., in error messages).
+	Name string
+
+	// FS is the file system containing the files.
+	FS fs.FS
+
+	// root is the absolute path of the directory containing the
+	// files, which is only set for the host file system.
+	root string
+}
+
+// DirLocation returns a [Location] for the given directory of the host file system.
+func DirLocation(dir string) Location {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		root = "" // fallback to using the location name
+	}
+	return Location{Name: dir, FS: os.DirFS(dir), root: root}
+}
+
+// fileName returns the name of the file at the given slash-separated path
+// inside the location, which we use for tokens and error messages.
+func (loc Location) fileName(name string) string {
+	return filepath.Join(loc.Name, filepath.FromSlash(name))
+}
+
+// canonicalPath returns the canonical path of the file at the given slash-separated
+// path inside the location, which is the cleaned absolute path for the host file
+// system and otherwise the name of the file inside the location.
+func (loc Location) canonicalPath(name string) string {
+	if loc.root != "" {
+		return filepath.Join(loc.root, filepath.FromSlash(name))
+	}
+	return loc.fileName(name)
+}
+
+// EnvSearchPath is the environment variable containing additional
+// directories in which to search for included files.
+const EnvSearchPath = "BURESU_PATH"
+
+// NewSearchPath returns the search path containing the given include
+// directories, followed by the directories listed in the [EnvSearchPath]
+// environment variable, followed by the standard library location.
+func NewSearchPath(includeDirs []string, stdlib Location) []Location {
+	var searchPath []Location
+	for _, dir := range includeDirs {
+		searchPath = append(searchPath, DirLocation(dir))
+	}
+	for _, dir := range filepath.SplitList(os.Getenv(EnvSearchPath)) {
+		if dir != "" {
+			searchPath = append(searchPath, DirLocation(dir))
+		}
+	}
+	return append(searchPath, stdlib)
+}
+
+// candidate is a file that may contain the file to include.
+type candidate struct {
+	// loc is the location containing the file.
+	loc Location
+
+	// name is the slash-separated path of the file inside loc.
+	name string
+}
+
+// hostCandidate returns the candidate for a file of the host file system,
+// which we represent using the location of the directory containing it, so
+// that the name inside the location is always a valid [fs.FS] path.
+func hostCandidate(filename string) candidate {
+	return candidate{
+		loc:  DirLocation(filepath.Dir(filename)),
+		name: filepath.Base(filename),
+	}
+}
+
+// origin tracks where we found an included file.
+type origin struct {
+	// loc is the location containing the file.
+	loc Location
+
+	// dir is the slash-separated directory of the file inside loc.
+	dir string
+}
+
+// recordOrigin records where we found the file with the given name, unless the
+// file is in the host file system, where we resolve the files it includes using
+// its host path, such that these files may be outside of the location (e.g.,
+// `lib/b.brs` including `../c.brs` resolves to `c.brs`).
+func (inc *includer) recordOrigin(name string, c candidate) {
+	if c.loc.root != "" {
+		return
+	}
+	inc.origins[name] = origin{loc: c.loc, dir: path.Dir(c.name)}
+}
+
+// candidates returns the files that may contain the given included file in
+// the order in which we should try them: first the directory of the including
+// file, as identified by the given file name, then the search path.
+func (inc *includer) candidates(including, filename string) []candidate {
+	// Absolute paths do not need searching
+	if filepath.IsAbs(filename) {
+		return []candidate{hostCandidate(filename)}
+	}
+
+	// The directory of the including file comes first, if any, which may
+	// be inside a location, if we have included the file, or otherwise
+	// must be in the host file system, unless the name does not refer
+	// to a file at all (e.g., `<stdin>`)
+	var candidates []candidate
+	if o, found := inc.origins[including]; found {
+		candidates = append(candidates, candidate{loc: o.loc, name: path.Join(o.dir, filename)})
+	} else if including != "" && including[0] != '<' {
+		candidates = append(candidates, hostCandidate(
+			filepath.Join(filepath.Dir(including), filepath.FromSlash(filename))))
+	}
+
+	// Then we try each location of the search path in order
+	for _, loc := range inc.searchPath {
+		candidates = append(candidates, candidate{loc: loc, name: path.Clean(filename)})
+	}
+	return candidates
+}
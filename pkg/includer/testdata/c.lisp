@@ -0,0 +1 @@
+(define c 5)
@@ -0,0 +1 @@
+(define x 4)
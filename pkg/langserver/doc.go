@@ -0,0 +1,21 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package langserver implements a Language Server Protocol (LSP) server
+// for the Buresu language, which editors talk to using JSON-RPC.
+//
+// The server supports the following features:
+//
+// - publishing diagnostics from the scanner, parser, includer and typechecker
+// when a document is opened or changed, parsing with error recovery such that
+// we publish all the parser errors;
+//
+// - showing the type of symbols on hover, using the typechecker environment;
+//
+// - going to the definition of define and declare symbols, of lambda and
+// let parameters, and of the files referenced by include! and import;
+//
+// - completing the symbols in scope.
+//
+// Use [NewServer] to create a [*Server] and [*Server.Serve] to
+// serve a client, typically using the standard input and output.
+package langserver
@@ -0,0 +1,246 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package langserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/includer"
+	"github.com/bassosimone/buresu/pkg/parser"
+	"github.com/bassosimone/buresu/pkg/scanner"
+	"github.com/bassosimone/buresu/pkg/token"
+	"github.com/bassosimone/buresu/pkg/typechecker"
+)
+
+// document is an analyzed document the client has opened.
+type document struct {
+	// uri is the document URI.
+	uri string
+
+	// filename is the name of the document file, which we use
+	// for the tokens and for resolving the included files.
+	filename string
+
+	// lines contains the lines of the document text.
+	lines []string
+
+	// tokens contains the document tokens, which is empty
+	// if we could not scan the document.
+	tokens []token.Token
+
+	// diagnostics contains the document diagnostics.
+	diagnostics []Diagnostic
+
+	// symbols is the index of the document symbols, which is
+	// nil if we could not parse the document.
+	symbols *symbolIndex
+
+	// env is the typechecker environment after checking the document,
+	// which is nil if we could not include all the files.
+	env *typechecker.Environment
+}
+
+// analyze scans, parses, includes and typechecks the given document text.
+func (s *Server) analyze(ctx context.Context, uri, text string) *document {
+	doc := &document{
+		uri:      uri,
+		filename: uriToFilename(uri),
+		lines:    strings.Split(text, "\n"),
+	}
+
+	// 1. scan the document
+	tokens, err := scanner.Scan(doc.filename, strings.NewReader(text))
+	if err != nil {
+		var serr *scanner.Error
+		if errors.As(err, &serr) {
+			doc.addDiagnostic(serr.Span(), "scanner: "+serr.Message)
+			return doc
+		}
+		doc.addDiagnostic(token.Span{}, err.Error())
+		return doc
+	}
+	doc.tokens = tokens
+
+	// 2. parse the tokens, reporting all the errors, in which case
+	// we only index the symbols of the forms we could parse
+	nodes, errs := parser.ParseWithRecovery(tokens)
+	if len(errs) > 0 {
+		for _, err := range errs {
+			var perr *parser.Error
+			if errors.As(err, &perr) {
+				doc.addDiagnostic(perr.Span(), "parser: "+perr.Message)
+				continue
+			}
+			doc.addDiagnostic(token.Span{}, err.Error())
+		}
+		doc.symbols = newSymbolIndex(doc.filename, tokens, nodes)
+		return doc
+	}
+
+	// 3. include the other files
+	included, err := includer.IncludeWithCache(s.cache, s.searchPath, nodes)
+	if err != nil {
+		doc.symbols = newSymbolIndex(doc.filename, tokens, nodes)
+		doc.addIncluderDiagnostic(s, nodes, err)
+		return doc
+	}
+	doc.symbols = newSymbolIndex(doc.filename, tokens, included)
+
+	// 4. typecheck the nodes
+	env, err := typechecker.NewGlobalEnvironment(ctx, s.stdlib, s.cache)
+	if err != nil {
+		doc.addDiagnostic(token.Span{}, fmt.Sprintf("failed to load the standard library: %s", err.Error()))
+		return doc
+	}
+	doc.env = env
+	for _, node := range included {
+		if _, err := typechecker.Check(ctx, env, node); err != nil {
+			doc.addTypecheckerDiagnostic(node, err)
+			break
+		}
+	}
+	return doc
+}
+
+// addIncluderDiagnostic adds the diagnostic for the given includer error, which
+// we attribute to the include or import statement of the document leading to it.
+func (doc *document) addIncluderDiagnostic(s *Server, nodes []ast.Node, err error) {
+	var ierr *includer.Error
+	if errors.As(err, &ierr) && ierr.Tok.TokenPos.FileName == doc.filename {
+		doc.addDiagnostic(ierr.Span(), strings.TrimPrefix(
+			err.Error(), ierr.Tok.TokenPos.String()+": "))
+		return
+	}
+
+	// the error occurred inside an included file, hence include each
+	// statement on its own to find the one leading to the error
+	for _, node := range nodes {
+		switch node.(type) {
+		case *ast.IncludeStmt, *ast.ImportStmt:
+			if _, err := includer.IncludeWithCache(s.cache, s.searchPath, []ast.Node{node}); err != nil {
+				doc.addDiagnostic(ast.NodeSpan(node), err.Error())
+				return
+			}
+		}
+	}
+	doc.addDiagnostic(token.Span{}, err.Error())
+}
+
+// addTypecheckerDiagnostic adds the diagnostic for the given typechecker
+// error, which occurred when checking the given toplevel node.
+func (doc *document) addTypecheckerDiagnostic(node ast.Node, err error) {
+	// find the innermost node that caused the error inside the document
+	var innermost *typechecker.Error
+	for cur := err; cur != nil; cur = errors.Unwrap(cur) {
+		if terr, ok := cur.(*typechecker.Error); ok && terr.Tok.TokenPos.FileName == doc.filename {
+			innermost = terr
+		}
+	}
+	if innermost != nil {
+		doc.addDiagnostic(innermost.Span(), "typechecker: "+innermost.Err.Error())
+		return
+	}
+
+	// otherwise, use the toplevel node, if it belongs to the document
+	if span := ast.NodeSpan(node); span.Start.FileName == doc.filename {
+		doc.addDiagnostic(span, "typechecker: "+err.Error())
+		return
+	}
+	doc.addDiagnostic(token.Span{}, "typechecker: "+err.Error())
+}
+
+// addDiagnostic adds an error diagnostic for the given span.
+func (doc *document) addDiagnostic(span token.Span, message string) {
+	doc.diagnostics = append(doc.diagnostics, Diagnostic{
+		Range:    doc.spanRange(span),
+		Severity: SeverityError,
+		Source:   "buresu",
+		Message:  message,
+	})
+}
+
+// toPosition converts a one-based token position, where columns count
+// runes, to a zero-based LSP position, where characters count UTF-16 units.
+func (doc *document) toPosition(pos token.Position) Position {
+	line := max(pos.LineNumber-1, 0)
+	if line >= len(doc.lines) {
+		return Position{Line: line}
+	}
+	var character int
+	for idx, r := range []rune(doc.lines[line]) {
+		if idx >= pos.LineColumn-1 {
+			break
+		}
+		character += utf16.RuneLen(r)
+	}
+	return Position{Line: line, Character: character}
+}
+
+// fromPosition is the inverse of toPosition.
+func (doc *document) fromPosition(pos Position) token.Position {
+	column := 1
+	if pos.Line < len(doc.lines) {
+		var units int
+		for _, r := range doc.lines[pos.Line] {
+			if units >= pos.Character {
+				break
+			}
+			units += utf16.RuneLen(r)
+			column++
+		}
+	}
+	return token.Position{FileName: doc.filename, LineNumber: pos.Line + 1, LineColumn: column}
+}
+
+// spanRange returns the LSP range of the given span.
+func (doc *document) spanRange(span token.Span) Range {
+	return Range{Start: doc.toPosition(span.Start), End: doc.toPosition(span.End)}
+}
+
+// tokenAt returns the index of the token at the given position.
+func (doc *document) tokenAt(pos token.Position) (int, bool) {
+	for idx, tok := range doc.tokens {
+		if tok.TokenType != token.EOF && !before(pos, tok.TokenPos) && before(pos, tok.TokenEnd) {
+			return idx, true
+		}
+	}
+	return 0, false
+}
+
+// before returns whether the position a precedes the position b.
+func before(a, b token.Position) bool {
+	if a.LineNumber != b.LineNumber {
+		return a.LineNumber < b.LineNumber
+	}
+	return a.LineColumn < b.LineColumn
+}
+
+// uriToFilename returns the file name of the given URI, which
+// is the URI itself when it does not refer to a file.
+func uriToFilename(uri string) string {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Scheme != "file" {
+		return uri
+	}
+	path := parsed.Path
+	if len(path) >= 3 && path[0] == '/' && path[2] == ':' {
+		path = path[1:] // e.g., `/C:/foo` on Windows
+	}
+	return filepath.FromSlash(path)
+}
+
+// filenameToURI returns the URI of the given file name.
+func filenameToURI(filename string) string {
+	path := filepath.ToSlash(filename)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path // e.g., `C:/foo` on Windows
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
@@ -0,0 +1,352 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package langserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bassosimone/buresu/pkg/includer"
+	"github.com/bassosimone/buresu/pkg/token"
+	"github.com/bassosimone/buresu/pkg/typechecker/simple"
+)
+
+// Server is a language server.
+//
+// Construct using [NewServer].
+type Server struct {
+	// cache caches the parsed included files across analyses.
+	cache *includer.Cache
+
+	// conn is the connection with the client.
+	conn *conn
+
+	// documents contains the open documents indexed by URI.
+	documents map[string]*document
+
+	// searchPath contains the locations where to search for included files.
+	searchPath []includer.Location
+
+	// stdlib is the location from which we load the standard library.
+	stdlib includer.Location
+
+	// shutdown indicates whether the client requested a shutdown.
+	shutdown bool
+}
+
+// NewServer creates a new [*Server] searching included files
+// in the directory of each document and then in the search path, and loading
+// the standard library runtime and prelude from the given location.
+func NewServer(searchPath []includer.Location, stdlib includer.Location) *Server {
+	return &Server{
+		cache:      includer.NewMemoryCache(),
+		documents:  map[string]*document{},
+		searchPath: searchPath,
+		stdlib:     stdlib,
+	}
+}
+
+// ErrExitWithoutShutdown indicates that the client sent
+// the exit notification without requesting a shutdown.
+var ErrExitWithoutShutdown = errors.New("langserver: exit without shutdown")
+
+// Serve serves the client reading requests from r and writing responses to w
+// until the client sends the exit notification or closes the connection.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	s.conn = newConn(r, w)
+	for {
+		msg, err := s.conn.read()
+		var rerr *ResponseError
+		switch {
+		case errors.Is(err, io.EOF):
+			return nil
+		case errors.As(err, &rerr):
+			if err := s.conn.reply(json.RawMessage("null"), nil, rerr); err != nil {
+				return err
+			}
+			continue
+		case err != nil:
+			return err
+		}
+
+		// notifications do not have an ID and do not want a response
+		if len(msg.ID) <= 0 {
+			if msg.Method == "exit" {
+				if !s.shutdown {
+					return ErrExitWithoutShutdown
+				}
+				return nil
+			}
+			if err := s.handleNotification(ctx, msg); err != nil {
+				return err
+			}
+			continue
+		}
+
+		result, err := s.handleRequest(ctx, msg)
+		if err := s.conn.reply(msg.ID, result, err); err != nil {
+			return err
+		}
+	}
+}
+
+// handleRequest handles a request and returns the result.
+func (s *Server) handleRequest(ctx context.Context, msg *message) (any, error) {
+	switch msg.Method {
+	case "initialize":
+		return &InitializeResult{
+			Capabilities: ServerCapabilities{
+				TextDocumentSync:   textDocumentSyncFull,
+				HoverProvider:      true,
+				DefinitionProvider: true,
+				CompletionProvider: &CompletionOptions{TriggerCharacters: []string{"(", "/"}},
+			},
+			ServerInfo: ServerInfo{Name: "buresu"},
+		}, nil
+
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.hover(params), nil
+
+	case "textDocument/definition":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.definition(params), nil
+
+	case "textDocument/completion":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.completion(params), nil
+
+	default:
+		return nil, &ResponseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not found: %s", msg.Method)}
+	}
+}
+
+// handleNotification handles a notification.
+func (s *Server) handleNotification(ctx context.Context, msg *message) error {
+	switch msg.Method {
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil // we cannot report errors for notifications
+		}
+		return s.update(ctx, params.TextDocument.URI, params.TextDocument.Text)
+
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := unmarshalParams(msg, &params); err != nil || len(params.ContentChanges) <= 0 {
+			return nil // we cannot report errors for notifications
+		}
+		text := params.ContentChanges[len(params.ContentChanges)-1].Text
+		return s.update(ctx, params.TextDocument.URI, text)
+
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil // we cannot report errors for notifications
+		}
+		delete(s.documents, params.TextDocument.URI)
+		return s.conn.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
+			URI:         params.TextDocument.URI,
+			Diagnostics: []Diagnostic{},
+		})
+
+	default:
+		return nil // e.g., initialized
+	}
+}
+
+// unmarshalParams unmarshals the params of the given message.
+func unmarshalParams(msg *message, params any) error {
+	if err := json.Unmarshal(msg.Params, params); err != nil {
+		return &ResponseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+// update analyzes the document with the given URI and text and publishes the diagnostics.
+func (s *Server) update(ctx context.Context, uri, text string) error {
+	doc := s.analyze(ctx, uri, text)
+	s.documents[uri] = doc
+	diagnostics := doc.diagnostics
+	if diagnostics == nil {
+		diagnostics = []Diagnostic{} // the specification wants an array
+	}
+	return s.conn.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: diagnostics,
+	})
+}
+
+// symbolAt returns the document, the token and the definition of the symbol
+// at the given position, where the definition is nil for built-in symbols.
+func (s *Server) symbolAt(params TextDocumentPositionParams) (*document, token.Token, *definition, bool) {
+	doc, found := s.documents[params.TextDocument.URI]
+	if !found || doc.symbols == nil {
+		return nil, token.Token{}, nil, false
+	}
+	idx, found := doc.tokenAt(doc.fromPosition(params.Position))
+	if !found || doc.tokens[idx].TokenType != token.ATOM {
+		return nil, token.Token{}, nil, false
+	}
+	tok := doc.tokens[idx]
+	for _, def := range doc.symbols.defs {
+		if def.tok.TokenPos == tok.TokenPos {
+			return doc, tok, def, true
+		}
+	}
+	for _, ref := range doc.symbols.refs {
+		if ref.tok.TokenPos == tok.TokenPos {
+			return doc, tok, ref.def, true
+		}
+	}
+	return nil, token.Token{}, nil, false
+}
+
+// hover returns the type of the symbol at the given position.
+func (s *Server) hover(params TextDocumentPositionParams) *Hover {
+	doc, tok, def, found := s.symbolAt(params)
+	if !found {
+		return nil
+	}
+
+	// the typechecker environment only contains the global symbols
+	var value strings.Builder
+	switch {
+	case def != nil && def.local:
+		fmt.Fprintf(&value, "```buresu\n%s\n```\n\nlocal symbol", tok.Value)
+	case doc.env != nil:
+		kind, err := doc.env.GetType(tok.Value)
+		if err != nil {
+			return nil
+		}
+		fmt.Fprintf(&value, "```buresu\n%s :: %s\n```", tok.Value, kind.String())
+	default:
+		return nil
+	}
+	if def != nil {
+		if docs := def.docs(); docs != "" {
+			fmt.Fprintf(&value, "\n\n%s", docs)
+		}
+	}
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: value.String()},
+		Range:    doc.spanRange(tok.Span()),
+	}
+}
+
+// definition returns the location of the definition of the symbol at the
+// given position or of the file included or imported at the given position.
+func (s *Server) definition(params TextDocumentPositionParams) *Location {
+	if loc := s.includeDefinition(params); loc != nil {
+		return loc
+	}
+	doc, _, def, found := s.symbolAt(params)
+	if !found || def == nil {
+		return nil
+	}
+	filename := def.tok.TokenPos.FileName
+	if filename == doc.filename {
+		return &Location{URI: doc.uri, Range: doc.spanRange(def.tok.Span())}
+	}
+	if strings.HasPrefix(filename, "<") {
+		return nil // e.g., the embedded standard library
+	}
+	filename, err := filepath.Abs(filename)
+	if err != nil {
+		return nil
+	}
+	pos := Position{Line: max(def.tok.TokenPos.LineNumber-1, 0), Character: max(def.tok.TokenPos.LineColumn-1, 0)}
+	return &Location{URI: filenameToURI(filename), Range: Range{Start: pos, End: pos}}
+}
+
+// includeDefinition returns the location of the file included or imported
+// at the given position, if the position is on the file path string.
+func (s *Server) includeDefinition(params TextDocumentPositionParams) *Location {
+	doc, found := s.documents[params.TextDocument.URI]
+	if !found {
+		return nil
+	}
+	idx, found := doc.tokenAt(doc.fromPosition(params.Position))
+	if !found || idx < 2 || doc.tokens[idx].TokenType != token.STRING {
+		return nil
+	}
+	if doc.tokens[idx-2].TokenType != token.OPEN {
+		return nil
+	}
+	if stmt := doc.tokens[idx-1]; stmt.Value != "include!" && stmt.Value != "import" {
+		return nil
+	}
+	filename, err := includer.Resolve(s.searchPath, doc.tokens[idx], doc.tokens[idx].Value)
+	if err != nil || !filepath.IsAbs(filename) {
+		return nil // e.g., the embedded standard library
+	}
+	return &Location{URI: filenameToURI(filename)}
+}
+
+// completion returns the symbols in scope at the given position.
+func (s *Server) completion(params TextDocumentPositionParams) *CompletionList {
+	list := &CompletionList{Items: []CompletionItem{}}
+	doc, found := s.documents[params.TextDocument.URI]
+	if !found || doc.symbols == nil {
+		return list
+	}
+
+	// gather the symbols defined by the program, then the symbols
+	// defined by the standard library and the built-in functions,
+	// using the typechecker to know the type of the global symbols
+	items := map[string]CompletionItem{}
+	locals := map[string]struct{}{}
+	for _, def := range doc.symbols.scopeAt(doc.fromPosition(params.Position)).visible() {
+		kind := CompletionKindVariable
+		if def.isLambda() {
+			kind = CompletionKindFunction
+		}
+		items[def.name] = CompletionItem{Label: def.name, Kind: kind}
+		if def.local {
+			locals[def.name] = struct{}{}
+		}
+	}
+	if doc.env != nil {
+		for _, symbol := range doc.env.Symbols() {
+			if _, found := locals[symbol]; found {
+				continue // the local symbol shadows the global one
+			}
+			kind, err := doc.env.GetType(symbol)
+			if err != nil {
+				continue
+			}
+			item := CompletionItem{Label: symbol, Kind: CompletionKindVariable, Detail: kind.String()}
+			if _, ok := kind.(*simple.Callable); ok {
+				item.Kind = CompletionKindFunction
+			}
+			items[symbol] = item
+		}
+	}
+
+	for _, item := range items {
+		list.Items = append(list.Items, item)
+	}
+	slices.SortFunc(list.Items, func(a, b CompletionItem) int {
+		return strings.Compare(a.Label, b.Label)
+	})
+	return list
+}
@@ -0,0 +1,428 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package langserver_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/bassosimone/buresu/pkg/includer"
+	"github.com/bassosimone/buresu/pkg/langserver"
+	"github.com/bassosimone/buresu/stdlib"
+	"github.com/google/go-cmp/cmp"
+)
+
+// client is a scripted LSP client talking with an in-process server.
+type client struct {
+	t      *testing.T
+	id     int
+	reader *textproto.Reader
+	writer io.WriteCloser
+	done   chan error
+
+	// notifications contains the notifications we have received
+	// while waiting for the responses to our requests.
+	notifications []map[string]json.RawMessage
+}
+
+// newClient starts a server and returns a client connected to it.
+func newClient(t *testing.T) *client {
+	serverReader, clientWriter := io.Pipe()
+	clientReader, serverWriter := io.Pipe()
+	server := langserver.NewServer([]includer.Location{stdlib.Location()}, stdlib.Location())
+	done := make(chan error, 1)
+	go func() {
+		err := server.Serve(context.Background(), serverReader, serverWriter)
+		serverWriter.Close()
+		done <- err
+	}()
+	c := &client{
+		t:      t,
+		reader: textproto.NewReader(bufio.NewReader(clientReader)),
+		writer: clientWriter,
+		done:   done,
+	}
+	c.call("initialize", map[string]any{"capabilities": map[string]any{}}, nil)
+	c.notify("initialized", map[string]any{})
+	return c
+}
+
+// send sends the given message to the server.
+func (c *client) send(msg map[string]any) {
+	msg["jsonrpc"] = "2.0"
+	data, err := json.Marshal(msg)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if _, err := fmt.Fprintf(c.writer, "Content-Length: %d\r\n\r\n%s", len(data), data); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// receive receives the next message from the server.
+func (c *client) receive() map[string]json.RawMessage {
+	header, err := c.reader.ReadMIMEHeader()
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.reader.R, body); err != nil {
+		c.t.Fatal(err)
+	}
+	var msg map[string]json.RawMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		c.t.Fatal(err)
+	}
+	return msg
+}
+
+// notify sends a notification to the server.
+func (c *client) notify(method string, params any) {
+	c.send(map[string]any{"method": method, "params": params})
+}
+
+// call sends a request and unmarshals the result, failing on errors.
+func (c *client) call(method string, params, result any) {
+	if rerr := c.callWithError(method, params, result); rerr != nil {
+		c.t.Fatalf("%s: %s", method, rerr.Message)
+	}
+}
+
+// callWithError is like call but returns the response error.
+func (c *client) callWithError(method string, params, result any) *langserver.ResponseError {
+	c.id++
+	c.send(map[string]any{"id": c.id, "method": method, "params": params})
+	for {
+		msg := c.receive()
+		if _, found := msg["id"]; !found {
+			c.notifications = append(c.notifications, msg)
+			continue
+		}
+		if string(msg["id"]) != strconv.Itoa(c.id) {
+			c.t.Fatalf("unexpected response id: %s", msg["id"])
+		}
+		if data, found := msg["error"]; found {
+			var rerr langserver.ResponseError
+			if err := json.Unmarshal(data, &rerr); err != nil {
+				c.t.Fatal(err)
+			}
+			return &rerr
+		}
+		if result != nil {
+			if err := json.Unmarshal(msg["result"], result); err != nil {
+				c.t.Fatal(err)
+			}
+		}
+		return nil
+	}
+}
+
+// diagnostics waits for the next diagnostics the server publishes.
+func (c *client) diagnostics() langserver.PublishDiagnosticsParams {
+	for {
+		var msg map[string]json.RawMessage
+		if len(c.notifications) > 0 {
+			msg, c.notifications = c.notifications[0], c.notifications[1:]
+		} else {
+			msg = c.receive()
+		}
+		if string(msg["method"]) != `"textDocument/publishDiagnostics"` {
+			continue
+		}
+		var params langserver.PublishDiagnosticsParams
+		if err := json.Unmarshal(msg["params"], &params); err != nil {
+			c.t.Fatal(err)
+		}
+		return params
+	}
+}
+
+// open opens the document with the given URI and text and returns the diagnostics.
+func (c *client) open(uri, text string) []langserver.Diagnostic {
+	c.notify("textDocument/didOpen", langserver.DidOpenTextDocumentParams{
+		TextDocument: langserver.TextDocumentItem{URI: uri, LanguageID: "buresu", Version: 1, Text: text},
+	})
+	params := c.diagnostics()
+	if params.URI != uri {
+		c.t.Fatalf("expected diagnostics for %s, got %s", uri, params.URI)
+	}
+	return params.Diagnostics
+}
+
+// close shuts down the server and waits for it to terminate.
+func (c *client) close() {
+	c.call("shutdown", nil, nil)
+	c.notify("exit", nil)
+	if err := <-c.done; err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// position returns the params for the given position within the given document.
+func position(uri string, line, character int) langserver.TextDocumentPositionParams {
+	return langserver.TextDocumentPositionParams{
+		TextDocument: langserver.TextDocumentIdentifier{URI: uri},
+		Position:     langserver.Position{Line: line, Character: character},
+	}
+}
+
+// fileURI returns the URI of the given test file.
+func fileURI(t *testing.T, name string) (string, string) {
+	filename, err := filepath.Abs(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "file://" + filepath.ToSlash(filename), filename
+}
+
+func TestServer(t *testing.T) {
+	uri, filename := fileURI(t, "main.brs")
+	text, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	libURI, _ := fileURI(t, "lib/square.brs")
+
+	c := newClient(t)
+	defer c.close()
+	if diags := c.open(uri, string(text)); len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %+v", diags)
+	}
+
+	t.Run("hover shows the types of the global symbols", func(t *testing.T) {
+		var hover langserver.Hover
+		c.call("textDocument/hover", position(uri, 4, 30), &hover) // square
+		if !strings.Contains(hover.Contents.Value, "square :: (Callable (Int) Int)") {
+			t.Fatalf("unexpected hover: %s", hover.Contents.Value)
+		}
+		if !strings.Contains(hover.Contents.Value, "Return the square of x.") {
+			t.Fatalf("missing docs in hover: %s", hover.Contents.Value)
+		}
+		expected := langserver.Range{
+			Start: langserver.Position{Line: 4, Character: 29},
+			End:   langserver.Position{Line: 4, Character: 35},
+		}
+		if hover.Range != expected {
+			t.Fatalf("unexpected range: %+v", hover.Range)
+		}
+	})
+
+	t.Run("hover shows the types of the built-in symbols", func(t *testing.T) {
+		var hover langserver.Hover
+		c.call("textDocument/hover", position(uri, 6, 2), &hover) // display
+		if !strings.Contains(hover.Contents.Value, "display :: (Callable") {
+			t.Fatalf("unexpected hover: %s", hover.Contents.Value)
+		}
+	})
+
+	t.Run("hover returns null outside of symbols", func(t *testing.T) {
+		var hover *langserver.Hover
+		c.call("textDocument/hover", position(uri, 1, 0), &hover)
+		if hover != nil {
+			t.Fatalf("unexpected hover: %+v", hover)
+		}
+	})
+
+	t.Run("definition of a global symbol in the same file", func(t *testing.T) {
+		var loc langserver.Location
+		c.call("textDocument/definition", position(uri, 6, 11), &loc) // twice
+		expected := langserver.Location{URI: uri, Range: langserver.Range{
+			Start: langserver.Position{Line: 2, Character: 8},
+			End:   langserver.Position{Line: 2, Character: 13},
+		}}
+		if loc != expected {
+			t.Fatalf("unexpected location: %+v", loc)
+		}
+	})
+
+	t.Run("definition of a lambda parameter", func(t *testing.T) {
+		var loc langserver.Location
+		c.call("textDocument/definition", position(uri, 2, 34), &loc) // v in (f v)
+		expected := langserver.Range{
+			Start: langserver.Position{Line: 2, Character: 25},
+			End:   langserver.Position{Line: 2, Character: 26},
+		}
+		if loc.URI != uri || loc.Range != expected {
+			t.Fatalf("unexpected location: %+v", loc)
+		}
+	})
+
+	t.Run("definition of a let binding", func(t *testing.T) {
+		var loc langserver.Location
+		c.call("textDocument/definition", position(uri, 4, 36), &loc) // n
+		expected := langserver.Range{
+			Start: langserver.Position{Line: 4, Character: 22},
+			End:   langserver.Position{Line: 4, Character: 23},
+		}
+		if loc.URI != uri || loc.Range != expected {
+			t.Fatalf("unexpected location: %+v", loc)
+		}
+	})
+
+	t.Run("definition of a loop variable", func(t *testing.T) {
+		var loc langserver.Location
+		c.call("textDocument/definition", position(uri, 8, 28), &loc) // c
+		expected := langserver.Range{
+			Start: langserver.Position{Line: 8, Character: 10},
+			End:   langserver.Position{Line: 8, Character: 11},
+		}
+		if loc.URI != uri || loc.Range != expected {
+			t.Fatalf("unexpected location: %+v", loc)
+		}
+	})
+
+	t.Run("definition of a symbol in an included file", func(t *testing.T) {
+		var loc langserver.Location
+		c.call("textDocument/definition", position(uri, 4, 30), &loc) // square
+		if loc.URI != libURI || loc.Range.Start.Line != 0 {
+			t.Fatalf("unexpected location: %+v", loc)
+		}
+	})
+
+	t.Run("definition of an included file", func(t *testing.T) {
+		var loc langserver.Location
+		c.call("textDocument/definition", position(uri, 0, 14), &loc)
+		if loc.URI != libURI {
+			t.Fatalf("unexpected location: %+v", loc)
+		}
+	})
+
+	t.Run("completion includes the symbols in scope", func(t *testing.T) {
+		var list langserver.CompletionList
+		c.call("textDocument/completion", position(uri, 2, 30), &list) // inside twice
+		labels := map[string]langserver.CompletionItem{}
+		for _, item := range list.Items {
+			labels[item.Label] = item
+		}
+		for _, name := range []string{"f", "v", "twice", "square", "answer", "display", "map"} {
+			if _, found := labels[name]; !found {
+				t.Errorf("missing completion for %s", name)
+			}
+		}
+		if labels["square"].Kind != langserver.CompletionKindFunction {
+			t.Errorf("unexpected kind for square: %+v", labels["square"])
+		}
+		if _, found := labels["n"]; found {
+			t.Error("unexpected completion for n, which is not in scope")
+		}
+	})
+
+	t.Run("unknown methods return an error", func(t *testing.T) {
+		if rerr := c.callWithError("textDocument/nonexistent", map[string]any{}, nil); rerr == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
+
+func TestServerDiagnostics(t *testing.T) {
+	uri, _ := fileURI(t, "scratch.brs")
+
+	tests := []struct {
+		name     string
+		text     string
+		expected langserver.Diagnostic
+	}{{
+		name: "scanner errors",
+		text: "(define x 1)\n(define y @)",
+		expected: langserver.Diagnostic{
+			Range: langserver.Range{
+				Start: langserver.Position{Line: 1, Character: 10},
+				End:   langserver.Position{Line: 1, Character: 11},
+			},
+			Message: "scanner: unexpected symbolic atom",
+		},
+	}, {
+		name: "parser errors",
+		text: "(define x 1)\n(lambda (x x) x)",
+		expected: langserver.Diagnostic{
+			Range: langserver.Range{
+				Start: langserver.Position{Line: 1, Character: 0},
+				End:   langserver.Position{Line: 1, Character: 1},
+			},
+			Message: `parser: lambda parameter "x" is duplicated`,
+		},
+	}, {
+		name: "includer errors",
+		text: "(define x 1)\n(include! \"nonexistent.brs\")",
+		expected: langserver.Diagnostic{
+			Range: langserver.Range{
+				Start: langserver.Position{Line: 1, Character: 0},
+				End:   langserver.Position{Line: 1, Character: 28},
+			},
+			Message: "includer: cannot find file nonexistent.brs",
+		},
+	}, {
+		name: "typechecker errors",
+		text: "(define x 1)\n(define x \"x\")",
+		expected: langserver.Diagnostic{
+			Range: langserver.Range{
+				Start: langserver.Position{Line: 1, Character: 0},
+				End:   langserver.Position{Line: 1, Character: 14},
+			},
+			Message: "typechecker: symbol already defined: x",
+		},
+	}}
+
+	c := newClient(t)
+	defer c.close()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := c.open(uri, tt.text)
+			if len(diags) != 1 {
+				t.Fatalf("expected one diagnostic, got %+v", diags)
+			}
+			if diags[0].Range != tt.expected.Range {
+				t.Errorf("unexpected range: %+v", diags[0].Range)
+			}
+			if !strings.HasPrefix(diags[0].Message, tt.expected.Message) {
+				t.Errorf("unexpected message: %s", diags[0].Message)
+			}
+			if diags[0].Severity != langserver.SeverityError {
+				t.Errorf("unexpected severity: %d", diags[0].Severity)
+			}
+		})
+	}
+
+	t.Run("we report all the parser errors", func(t *testing.T) {
+		diags := c.open(uri, "(lambda (x x) x)\n(define y 1)\n(define z (if))")
+		var messages []string
+		for _, diag := range diags {
+			messages = append(messages, diag.Message)
+		}
+		expected := []string{`parser: lambda parameter "x" is duplicated`, "parser: unexpected token CLOSE"}
+		if diff := cmp.Diff(expected, messages); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+
+	t.Run("changing a document clears the diagnostics", func(t *testing.T) {
+		c.notify("textDocument/didChange", langserver.DidChangeTextDocumentParams{
+			TextDocument:   langserver.TextDocumentIdentifier{URI: uri},
+			ContentChanges: []langserver.TextDocumentContentChangeEvent{{Text: "(define x 1)"}},
+		})
+		if params := c.diagnostics(); len(params.Diagnostics) != 0 {
+			t.Fatalf("unexpected diagnostics: %+v", params.Diagnostics)
+		}
+	})
+}
+
+func TestServerExitWithoutShutdown(t *testing.T) {
+	c := newClient(t)
+	c.notify("exit", nil)
+	if err := <-c.done; err != langserver.ErrExitWithoutShutdown {
+		t.Fatalf("expected ErrExitWithoutShutdown, got %v", err)
+	}
+}
@@ -0,0 +1,394 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package langserver
+
+import (
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/token"
+)
+
+// definition is the definition of a symbol.
+type definition struct {
+	// name is the symbol name.
+	name string
+
+	// tok is the token of the symbol name, when the definition is
+	// inside the document, or the token of the defining form otherwise.
+	tok token.Token
+
+	// node is the node defining the symbol, if any, which we use to
+	// obtain the documentation of the symbols bound to lambdas.
+	node ast.Node
+
+	// local indicates whether this is a local definition.
+	local bool
+}
+
+// docs returns the documentation of the symbol, if any.
+func (def *definition) docs() string {
+	var expr ast.Node
+	switch node := def.node.(type) {
+	case *ast.DefineExpr:
+		expr = node.Expr
+	case *ast.DeclareExpr:
+		expr = node.Expr
+	}
+	if lambda, ok := expr.(*ast.LambdaExpr); ok {
+		return lambda.Docs
+	}
+	return ""
+}
+
+// isLambda returns whether the symbol is bound to a lambda.
+func (def *definition) isLambda() bool {
+	switch node := def.node.(type) {
+	case *ast.DefineExpr:
+		_, ok := node.Expr.(*ast.LambdaExpr)
+		return ok
+	case *ast.DeclareExpr:
+		_, ok := node.Expr.(*ast.LambdaExpr)
+		return ok
+	}
+	return false
+}
+
+// reference is a reference to a symbol inside the document.
+type reference struct {
+	// tok is the token of the symbol name.
+	tok token.Token
+
+	// def is the definition of the symbol or nil if the symbol is not
+	// defined by the program (e.g., for the built-in functions).
+	def *definition
+}
+
+// scope is a lexical scope.
+type scope struct {
+	// parent is the parent scope or nil for the global scope.
+	parent *scope
+
+	// defs contains the symbols defined in this scope.
+	defs map[string]*definition
+
+	// start and end delimit the scope inside the document.
+	start, end token.Position
+}
+
+// newScope creates a new child scope of the given parent.
+func newScope(parent *scope, start, end token.Position) *scope {
+	return &scope{parent: parent, defs: map[string]*definition{}, start: start, end: end}
+}
+
+// lookup returns the definition of the given symbol, searching the parents.
+func (sc *scope) lookup(name string) *definition {
+	for cur := sc; cur != nil; cur = cur.parent {
+		if def, found := cur.defs[name]; found {
+			return def
+		}
+	}
+	return nil
+}
+
+// symbolIndex contains the definitions and the references of the
+// symbols of a document, along with the lexical scopes.
+type symbolIndex struct {
+	// filename is the name of the document file.
+	filename string
+
+	// tokens contains the document tokens.
+	tokens []token.Token
+
+	// index maps the position of each token to its index in tokens.
+	index map[token.Position]int
+
+	// closing maps the index of each OPEN token to the index
+	// of the matching CLOSE token, which allows to know where
+	// each form, and therefore each scope, ends.
+	closing map[int]int
+
+	// global is the global scope.
+	global *scope
+
+	// scopes contains the local scopes.
+	scopes []*scope
+
+	// defs contains the definitions inside the document.
+	defs []*definition
+
+	// refs contains the references inside the document.
+	refs []reference
+}
+
+// newSymbolIndex creates the [*symbolIndex] of the document with the given
+// file name, tokens and nodes, which may include nodes of other files.
+func newSymbolIndex(filename string, tokens []token.Token, nodes []ast.Node) *symbolIndex {
+	idx := &symbolIndex{
+		filename: filename,
+		tokens:   tokens,
+		index:    map[token.Position]int{},
+		closing:  map[int]int{},
+		global:   newScope(nil, token.Position{}, token.Position{}),
+	}
+
+	// index the tokens and match the parentheses
+	var stack []int
+	for i, tok := range tokens {
+		switch tok.TokenType {
+		case token.EOF:
+			continue
+		case token.OPEN:
+			stack = append(stack, i)
+		case token.CLOSE:
+			if len(stack) > 0 {
+				idx.closing[stack[len(stack)-1]] = i
+				stack = stack[:len(stack)-1]
+			}
+		}
+		idx.index[tok.TokenPos] = i
+	}
+
+	// register all the global symbols first, since a global
+	// lambda may reference symbols defined after it
+	for _, node := range nodes {
+		idx.defineGlobal(node)
+	}
+
+	// then walk the nodes of the document to find the references
+	for _, node := range nodes {
+		if ast.NodeToken(node).TokenPos.FileName == filename {
+			idx.walk(idx.global, node)
+		}
+	}
+	return idx
+}
+
+// defineGlobal registers the global symbols defined by the given node.
+func (idx *symbolIndex) defineGlobal(node ast.Node) {
+	switch node := node.(type) {
+	case *ast.DefineExpr:
+		idx.define(idx.global, node.Symbol, idx.tokenAfter(node.Token, 2), node, false)
+
+	case *ast.DeclareExpr:
+		idx.define(idx.global, node.Symbol, idx.tokenAfter(node.Token, 2), node, false)
+
+	case *ast.ImportStmt:
+		if len(node.Nodes) <= 0 {
+			return
+		}
+		decl, ok := node.Nodes[0].(*ast.ModuleStmt)
+		if !ok {
+			return
+		}
+		exports := map[string]struct{}{}
+		for _, symbol := range decl.Exports {
+			exports[symbol] = struct{}{}
+		}
+		for _, child := range node.Nodes {
+			var symbol string
+			switch child := child.(type) {
+			case *ast.DefineExpr:
+				symbol = child.Symbol
+			case *ast.DeclareExpr:
+				symbol = child.Symbol
+			}
+			if _, found := exports[symbol]; found {
+				idx.define(idx.global, node.Alias+"/"+symbol, ast.NodeToken(child), child, false)
+			}
+		}
+	}
+}
+
+// define registers the definition of the given symbol inside the given scope,
+// unless the symbol is already defined there (e.g., a function overload).
+func (idx *symbolIndex) define(sc *scope, name string, tok token.Token, node ast.Node, local bool) {
+	if _, found := sc.defs[name]; found {
+		return
+	}
+	def := &definition{name: name, tok: tok, node: node, local: local}
+	sc.defs[name] = def
+	if tok.TokenPos.FileName == idx.filename {
+		idx.defs = append(idx.defs, def)
+	}
+}
+
+// reference registers a reference to the given symbol.
+func (idx *symbolIndex) reference(sc *scope, name string, tok token.Token) {
+	idx.refs = append(idx.refs, reference{tok: tok, def: sc.lookup(name)})
+}
+
+// tokenAfter returns the token following the given document token by the
+// given offset or the token itself if it is not inside the document.
+func (idx *symbolIndex) tokenAfter(tok token.Token, offset int) token.Token {
+	if i, found := idx.index[tok.TokenPos]; found && tok.TokenPos.FileName == idx.filename {
+		if i+offset < len(idx.tokens) {
+			return idx.tokens[i+offset]
+		}
+	}
+	return tok
+}
+
+// tokenBefore is like tokenAfter but returns the token preceding the given one.
+func (idx *symbolIndex) tokenBefore(tok token.Token) token.Token {
+	if i, found := idx.index[tok.TokenPos]; found && i > 0 {
+		return idx.tokens[i-1]
+	}
+	return tok
+}
+
+// pushScope creates a new scope for the form starting at the given token.
+func (idx *symbolIndex) pushScope(parent *scope, tok token.Token) *scope {
+	sc := newScope(parent, tok.TokenPos, tok.TokenPos)
+	if i, found := idx.index[tok.TokenPos]; found {
+		if j, found := idx.closing[i]; found {
+			sc.end = idx.tokens[j].TokenPos
+		}
+	}
+	idx.scopes = append(idx.scopes, sc)
+	return sc
+}
+
+// walk walks the given node inside the given scope.
+func (idx *symbolIndex) walk(sc *scope, node ast.Node) {
+	switch node := node.(type) {
+	case *ast.BlockExpr:
+		child := idx.pushScope(sc, node.Token)
+		for _, expr := range node.Exprs {
+			switch expr := expr.(type) {
+			case *ast.DefineExpr:
+				idx.define(child, expr.Symbol, idx.tokenAfter(expr.Token, 2), expr, true)
+			case *ast.DeclareExpr:
+				idx.define(child, expr.Symbol, idx.tokenAfter(expr.Token, 2), expr, true)
+			}
+		}
+		for _, expr := range node.Exprs {
+			idx.walk(child, expr)
+		}
+
+	case *ast.CallExpr:
+		idx.walk(sc, node.Callable)
+		for _, arg := range node.Args {
+			idx.walk(sc, arg)
+		}
+
+	case *ast.CondExpr:
+		for _, c := range node.Cases {
+			idx.walk(sc, c.Predicate)
+			idx.walk(sc, c.Expr)
+		}
+		idx.walk(sc, node.ElseExpr)
+
+	case *ast.DeclareExpr:
+		// the declared lambda shares the token of the declare form, hence
+		// we cannot locate its parameters, and its body is usually `...`
+		idx.define(sc, node.Symbol, idx.tokenAfter(node.Token, 2), node, sc != idx.global)
+
+	case *ast.DefineExpr:
+		idx.walk(sc, node.Expr)
+		idx.define(sc, node.Symbol, idx.tokenAfter(node.Token, 2), node, sc != idx.global)
+
+	case *ast.ForExpr:
+		idx.walk(sc, node.From)
+		idx.walk(sc, node.To)
+		child := idx.pushScope(sc, node.Token)
+		idx.define(child, node.Symbol, idx.tokenAfter(node.Token, 3), node, true)
+		idx.walk(child, node.Expr)
+
+	case *ast.ForeachExpr:
+		idx.walk(sc, node.Seq)
+		child := idx.pushScope(sc, node.Token)
+		idx.define(child, node.Symbol, idx.tokenAfter(node.Token, 3), node, true)
+		idx.walk(child, node.Expr)
+
+	case *ast.LambdaExpr:
+		child := idx.pushScope(sc, node.Token)
+		for i, param := range node.Params {
+			idx.define(child, param, idx.tokenAfter(node.Token, 3+i), node, true)
+		}
+		idx.walk(child, node.Expr)
+
+	case *ast.LetExpr:
+		child := idx.pushScope(sc, node.Token)
+		for _, binding := range node.Bindings {
+			idx.walk(sc, binding.Expr)
+			idx.define(child, binding.Symbol, idx.tokenBefore(ast.NodeToken(binding.Expr)), node, true)
+		}
+		idx.walk(child, node.Expr)
+
+	case *ast.LetStarExpr:
+		child := idx.pushScope(sc, node.Token)
+		for _, binding := range node.Bindings {
+			idx.walk(child, binding.Expr)
+			idx.define(child, binding.Symbol, idx.tokenBefore(ast.NodeToken(binding.Expr)), node, true)
+		}
+		idx.walk(child, node.Expr)
+
+	case *ast.LetrecExpr:
+		child := idx.pushScope(sc, node.Token)
+		for _, binding := range node.Bindings {
+			idx.define(child, binding.Symbol, idx.tokenBefore(ast.NodeToken(binding.Expr)), node, true)
+		}
+		for _, binding := range node.Bindings {
+			idx.walk(child, binding.Expr)
+		}
+		idx.walk(child, node.Expr)
+
+	case *ast.QuasiquoteExpr:
+		for _, unquote := range node.Unquotes() {
+			idx.walk(sc, unquote.Expr)
+		}
+
+	case *ast.ReturnStmt:
+		idx.walk(sc, node.Expr)
+
+	case *ast.SetExpr:
+		idx.reference(sc, node.Symbol, idx.tokenAfter(node.Token, 2))
+		idx.walk(sc, node.Expr)
+
+	case *ast.SymbolName:
+		idx.reference(sc, node.Value, node.Token)
+
+	case *ast.WhileExpr:
+		idx.walk(sc, node.Predicate)
+		idx.walk(sc, node.Expr)
+
+	case *ast.YieldStmt:
+		idx.walk(sc, node.Expr)
+	}
+}
+
+// scopeAt returns the innermost scope containing the given position.
+func (idx *symbolIndex) scopeAt(pos token.Position) *scope {
+	innermost := idx.global
+	for _, sc := range idx.scopes {
+		if positionLess(pos, sc.start) || !positionLess(pos, sc.end) {
+			continue
+		}
+		// scopes are appended in preorder, so a later scope containing
+		// the position is nested inside the previous ones
+		innermost = sc
+	}
+	return innermost
+}
+
+// visible returns the definitions visible inside the given scope.
+func (sc *scope) visible() []*definition {
+	var defs []*definition
+	seen := map[string]struct{}{}
+	for cur := sc; cur != nil; cur = cur.parent {
+		for name, def := range cur.defs {
+			if _, found := seen[name]; !found {
+				seen[name] = struct{}{}
+				defs = append(defs, def)
+			}
+		}
+	}
+	return defs
+}
+
+// positionLess returns whether a precedes b inside the same file.
+func positionLess(a, b token.Position) bool {
+	if a.LineNumber != b.LineNumber {
+		return a.LineNumber < b.LineNumber
+	}
+	return a.LineColumn < b.LineColumn
+}
@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package langspec_test
+
+import (
+	"testing"
+
+	"github.com/bassosimone/buresu/internal/txtartesting"
+	"github.com/bassosimone/buresu/pkg/langspec"
+	"github.com/bassosimone/buresu/pkg/langspec/internal/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	for _, version := range langspec.Versions() {
+		t.Run("v"+version.String(), func(t *testing.T) {
+			// run the cases of the rules of this and earlier versions
+			for _, since := range langspec.Versions() {
+				if since > version {
+					break
+				}
+				testCases, err := txtartesting.LoadTestCases(conformance.Dir(since))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// make sure the suite is up to date with the rules
+				expected := make(map[string]*conformance.Case)
+				for _, tc := range conformance.Cases(since) {
+					expected[tc.Name] = tc
+				}
+				if len(testCases) != len(expected) {
+					t.Fatal("the suite is stale: run `go generate ./pkg/langspec`")
+				}
+
+				for _, tc := range testCases {
+					c, found := expected[tc.Name]
+					if !found {
+						t.Fatal("the suite is stale: run `go generate ./pkg/langspec`")
+					}
+					if c.Rule.Until != 0 && c.Rule.Until < version {
+						continue // a later version replaced the rule
+					}
+					t.Run("v"+since.String()+"/"+tc.Name, func(t *testing.T) {
+						if c.Valid != (tc.Error == "") {
+							t.Fatal("the test case disagrees with the rules")
+						}
+						output, err := conformance.Run(version, tc.Input)
+						if err := tc.CompareError(err); err != nil {
+							t.Fatal(err)
+						}
+						if err := tc.CompareTextOutput(output); err != nil {
+							t.Fatal(err)
+						}
+					})
+				}
+			}
+		})
+	}
+}
+
+func TestRulesFor(t *testing.T) {
+	for _, version := range langspec.Versions() {
+		seen := make(map[string]bool)
+		for _, rule := range langspec.RulesFor(version) {
+			if seen[rule.Name] {
+				t.Fatal("duplicate rule", rule.Name)
+			}
+			seen[rule.Name] = true
+			if rule.Since > version {
+				t.Fatal("rule from a later version", rule.Name)
+			}
+			if rule.Until != 0 && rule.Until < version {
+				t.Fatal("rule from an earlier version", rule.Name)
+			}
+			if rule.Doc == "" || len(rule.Valid) <= 0 {
+				t.Fatal("rule without documentation or valid examples", rule.Name)
+			}
+		}
+	}
+}
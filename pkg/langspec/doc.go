@@ -0,0 +1,21 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package langspec contains the versioned specification of the language.
+//
+// For each [Version], the specification consists of the grammar, written
+// using EBNF inside the spec directory, which [LoadGrammar] parses into
+// a machine-readable [*Grammar], and of the [Rule] list returned by
+// [RulesFor], which documents the constraints that the EBNF cannot express
+// (e.g., `return!` is only allowed inside lambdas) along with examples of
+// valid and invalid programs.
+//
+// We generate the conformance suite inside testdata/conformance by parsing
+// the examples of each rule and recording the results, such that the tests
+// detect changes in the parser. We store the examples of each rule once, in
+// the directory of the version introducing the rule, and the tests run them
+// against each later version containing the rule. Run `go generate` to
+// regenerate the suite after changing the rules or intentionally changing
+// the parser.
+package langspec
+
+//go:generate go run ./internal/genconformance
@@ -0,0 +1,516 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package langspec
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//go:embed spec/*.ebnf
+var specFS embed.FS
+
+// EBNF returns the source code of the grammar of the given [Version].
+func EBNF(version Version) (string, error) {
+	data, err := specFS.ReadFile(fmt.Sprintf("spec/v%d.ebnf", version))
+	if err != nil {
+		return "", fmt.Errorf("no grammar for language version %s", version)
+	}
+	return string(data), nil
+}
+
+// LoadGrammar loads and parses the grammar of the given [Version].
+func LoadGrammar(version Version) (*Grammar, error) {
+	source, err := EBNF(version)
+	if err != nil {
+		return nil, err
+	}
+	grammar, err := ParseGrammar(source)
+	if err != nil {
+		return nil, err
+	}
+	grammar.Version = version
+	return grammar, nil
+}
+
+// Grammar is the machine-readable grammar of a language [Version].
+type Grammar struct {
+	// Version is the language version.
+	Version Version
+
+	// Productions contains the productions in source order.
+	Productions []*Production
+}
+
+// Production returns the production with the given name, if any.
+func (g *Grammar) Production(name string) (*Production, bool) {
+	for _, prod := range g.Productions {
+		if prod.Name == name {
+			return prod, true
+		}
+	}
+	return nil, false
+}
+
+// Production is a grammar production (e.g., `Unit = "(" ")" .`).
+type Production struct {
+	// Name is the production name.
+	Name string
+
+	// Expr is the production expression, which is nil for the
+	// productions described by a comment (e.g., `letter`).
+	Expr Expression
+
+	// Rules contains the names of the rules listed by the `rule:`
+	// comments immediately preceding the production.
+	Rules []string
+}
+
+// Lexical returns whether the production is lexical, i.e., whether it
+// defines a token rather than a syntactic construct.
+func (p *Production) Lexical() bool {
+	r, _ := utf8.DecodeRuneInString(p.Name)
+	return unicode.IsLower(r)
+}
+
+// String converts the production back to EBNF.
+func (p *Production) String() string {
+	if p.Expr == nil {
+		return p.Name + " = ."
+	}
+	return fmt.Sprintf("%s = %s .", p.Name, p.Expr.String())
+}
+
+// Expression is an EBNF expression.
+type Expression interface {
+	// String converts the expression back to EBNF.
+	String() string
+}
+
+// Alternative is a list of alternative expressions (e.g., `a | b`).
+type Alternative []Expression
+
+// String implements [Expression].
+func (x Alternative) String() string {
+	return joinExpressions(x, " | ")
+}
+
+// Sequence is a list of expressions in sequence (e.g., `a b`).
+type Sequence []Expression
+
+// String implements [Expression].
+func (x Sequence) String() string {
+	return joinExpressions(x, " ")
+}
+
+// joinExpressions joins the given expressions using the given separator.
+func joinExpressions(exprs []Expression, sep string) string {
+	values := make([]string, 0, len(exprs))
+	for _, expr := range exprs {
+		values = append(values, expr.String())
+	}
+	return strings.Join(values, sep)
+}
+
+// Name is a reference to a production (e.g., `Expr`).
+type Name struct {
+	Name string
+}
+
+// String implements [Expression].
+func (x *Name) String() string {
+	return x.Name
+}
+
+// Token is a literal token (e.g., `"lambda"`).
+type Token struct {
+	Value string
+}
+
+// String implements [Expression].
+func (x *Token) String() string {
+	return strconv.Quote(x.Value)
+}
+
+// Range is a range of characters (e.g., `"a" … "z"`).
+type Range struct {
+	Begin *Token
+	End   *Token
+}
+
+// String implements [Expression].
+func (x *Range) String() string {
+	return fmt.Sprintf("%s … %s", x.Begin.String(), x.End.String())
+}
+
+// Group is a grouped expression (e.g., `( a | b )`).
+type Group struct {
+	Body Expression
+}
+
+// String implements [Expression].
+func (x *Group) String() string {
+	return fmt.Sprintf("( %s )", x.Body.String())
+}
+
+// Option is an optional expression (e.g., `[ a ]`).
+type Option struct {
+	Body Expression
+}
+
+// String implements [Expression].
+func (x *Option) String() string {
+	return fmt.Sprintf("[ %s ]", x.Body.String())
+}
+
+// Repetition is an expression repeated zero or more times (e.g., `{ a }`).
+type Repetition struct {
+	Body Expression
+}
+
+// String implements [Expression].
+func (x *Repetition) String() string {
+	return fmt.Sprintf("{ %s }", x.Body.String())
+}
+
+// ErrInvalidGrammar indicates that the grammar is not valid.
+var ErrInvalidGrammar = errors.New("invalid grammar")
+
+// ParseGrammar parses the given EBNF source code, making sure that each
+// production is defined once and that all the referenced productions exist.
+//
+// The grammar is as follows:
+//
+//	<grammar> ::= <production>*
+//
+//	<production> ::= <name> "=" [<alternative>] "."
+//
+//	<alternative> ::= <sequence> ("|" <sequence>)*
+//
+//	<sequence> ::= <term>+
+//
+//	<term> ::= <name> | <token> ["…" <token>]
+//	         | "(" <alternative> ")"
+//	         | "[" <alternative> "]"
+//	         | "{" <alternative> "}"
+//
+// where tokens are Go string literals and we skip Go comments.
+func ParseGrammar(source string) (*Grammar, error) {
+	gp := &grammarParser{source: source, lineno: 1}
+	grammar := &Grammar{}
+	defined := make(map[string]struct{})
+	for {
+		gp.next()
+		if gp.kind == ebnfEOF {
+			break
+		}
+		prod, err := gp.parseProduction()
+		if err != nil {
+			return nil, err
+		}
+		if _, found := defined[prod.Name]; found {
+			return nil, fmt.Errorf("%w: production %s defined more than once", ErrInvalidGrammar, prod.Name)
+		}
+		defined[prod.Name] = struct{}{}
+		grammar.Productions = append(grammar.Productions, prod)
+	}
+	for _, prod := range grammar.Productions {
+		for _, name := range References(prod.Expr) {
+			if _, found := defined[name]; !found {
+				return nil, fmt.Errorf("%w: production %s references undefined %s", ErrInvalidGrammar, prod.Name, name)
+			}
+		}
+	}
+	return grammar, nil
+}
+
+// References returns the names of the productions referenced by the
+// given expression, in the order in which they first appear.
+func References(expr Expression) []string {
+	var (
+		names []string
+		seen  = make(map[string]struct{})
+		visit func(Expression)
+	)
+	visit = func(expr Expression) {
+		switch expr := expr.(type) {
+		case Alternative:
+			for _, x := range expr {
+				visit(x)
+			}
+		case Sequence:
+			for _, x := range expr {
+				visit(x)
+			}
+		case *Group:
+			visit(expr.Body)
+		case *Option:
+			visit(expr.Body)
+		case *Repetition:
+			visit(expr.Body)
+		case *Name:
+			if _, found := seen[expr.Name]; !found {
+				seen[expr.Name] = struct{}{}
+				names = append(names, expr.Name)
+			}
+		}
+	}
+	visit(expr)
+	return names
+}
+
+// ebnfKind is the kind of an EBNF token.
+type ebnfKind int
+
+const (
+	ebnfEOF = ebnfKind(iota)
+	ebnfName
+	ebnfToken
+	ebnfOperator
+)
+
+// grammarParser parses EBNF source code.
+type grammarParser struct {
+	// source is the source code to parse.
+	source string
+
+	// offset is the offset of the next character to scan.
+	offset int
+
+	// lineno is the current line number.
+	lineno int
+
+	// kind is the kind of the current token.
+	kind ebnfKind
+
+	// value is the value of the current token.
+	value string
+
+	// rules contains the rules listed by the comments
+	// we have seen since the previous production.
+	rules []string
+}
+
+// errorf returns an error referring to the current line.
+func (gp *grammarParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: line %d: %s", ErrInvalidGrammar, gp.lineno, fmt.Sprintf(format, args...))
+}
+
+// next scans the next token, skipping whitespace and comments.
+func (gp *grammarParser) next() {
+	for gp.offset < len(gp.source) {
+		rest := gp.source[gp.offset:]
+		r, size := utf8.DecodeRuneInString(rest)
+		switch {
+		case r == '\n':
+			gp.lineno++
+			gp.offset += size
+
+		case unicode.IsSpace(r):
+			gp.offset += size
+
+		case strings.HasPrefix(rest, "//"):
+			line, _, _ := strings.Cut(rest, "\n")
+			if name, found := strings.CutPrefix(strings.TrimSpace(line[2:]), "rule:"); found {
+				gp.rules = append(gp.rules, strings.TrimSpace(name))
+			}
+			gp.offset += len(line)
+
+		case strings.HasPrefix(rest, "/*"):
+			comment, _, _ := strings.Cut(rest, "*/")
+			gp.lineno += strings.Count(comment, "\n")
+			gp.offset += min(len(comment)+2, len(rest))
+
+		case r == '_' || unicode.IsLetter(r):
+			end := strings.IndexFunc(rest, func(r rune) bool {
+				return r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+			})
+			if end < 0 {
+				end = len(rest)
+			}
+			gp.kind, gp.value = ebnfName, rest[:end]
+			gp.offset += end
+			return
+
+		case r == '"' || r == '`':
+			gp.kind, gp.value = ebnfToken, gp.scanLiteral(rest, r)
+			return
+
+		default:
+			gp.kind, gp.value = ebnfOperator, string(r)
+			gp.offset += size
+			return
+		}
+	}
+	gp.kind, gp.value = ebnfEOF, ""
+}
+
+// scanLiteral scans the Go string literal at the beginning of rest, which
+// is delimited by quote. On failure, the current token is an operator
+// containing the delimiter, such that the parser reports an error.
+func (gp *grammarParser) scanLiteral(rest string, quote rune) string {
+	for end := 1; end < len(rest); end++ {
+		if rest[end] == '\n' {
+			break
+		}
+		if quote == '"' && rest[end] == '\\' {
+			end++
+			continue
+		}
+		if rest[end] == byte(quote) {
+			value, err := strconv.Unquote(rest[:end+1])
+			if err != nil {
+				break
+			}
+			gp.offset += end + 1
+			return value
+		}
+	}
+	gp.offset++
+	gp.kind = ebnfOperator
+	return string(quote)
+}
+
+// expect consumes the given operator or returns an error.
+func (gp *grammarParser) expect(operator string) error {
+	if gp.kind != ebnfOperator || gp.value != operator {
+		return gp.errorf("expected %q, found %q", operator, gp.value)
+	}
+	gp.next()
+	return nil
+}
+
+// parseProduction parses a production.
+func (gp *grammarParser) parseProduction() (*Production, error) {
+	if gp.kind != ebnfName {
+		return nil, gp.errorf("expected production name, found %q", gp.value)
+	}
+	prod := &Production{Name: gp.value, Rules: gp.rules}
+	gp.rules = nil
+	gp.next()
+	if err := gp.expect("="); err != nil {
+		return nil, err
+	}
+	if gp.kind == ebnfOperator && gp.value == "." {
+		return prod, nil // e.g., `letter = /* ... */ .`
+	}
+	expr, err := gp.parseAlternative()
+	if err != nil {
+		return nil, err
+	}
+	prod.Expr = expr
+	if gp.kind != ebnfOperator || gp.value != "." {
+		return nil, gp.errorf("expected \".\", found %q", gp.value)
+	}
+	return prod, nil
+}
+
+// parseAlternative parses alternative sequences.
+func (gp *grammarParser) parseAlternative() (Expression, error) {
+	var alt Alternative
+	for {
+		seq, err := gp.parseSequence()
+		if err != nil {
+			return nil, err
+		}
+		alt = append(alt, seq)
+		if gp.kind != ebnfOperator || gp.value != "|" {
+			break
+		}
+		gp.next()
+	}
+	if len(alt) == 1 {
+		return alt[0], nil
+	}
+	return alt, nil
+}
+
+// parseSequence parses a sequence of terms.
+func (gp *grammarParser) parseSequence() (Expression, error) {
+	var seq Sequence
+	for {
+		term, err := gp.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		if term == nil {
+			break
+		}
+		seq = append(seq, term)
+	}
+	switch len(seq) {
+	case 0:
+		return nil, gp.errorf("expected expression, found %q", gp.value)
+	case 1:
+		return seq[0], nil
+	default:
+		return seq, nil
+	}
+}
+
+// parseTerm parses a term, returning nil if there is no term.
+func (gp *grammarParser) parseTerm() (Expression, error) {
+	switch {
+	case gp.kind == ebnfName:
+		name := &Name{Name: gp.value}
+		gp.next()
+		return name, nil
+
+	case gp.kind == ebnfToken:
+		tok := &Token{Value: gp.value}
+		gp.next()
+		if gp.kind != ebnfOperator || gp.value != "…" {
+			return tok, nil
+		}
+		gp.next()
+		if gp.kind != ebnfToken {
+			return nil, gp.errorf("expected token after \"…\", found %q", gp.value)
+		}
+		end := &Token{Value: gp.value}
+		gp.next()
+		return &Range{Begin: tok, End: end}, nil
+
+	case gp.kind == ebnfOperator && gp.value == "(":
+		body, err := gp.parseBody(")")
+		if err != nil {
+			return nil, err
+		}
+		return &Group{Body: body}, nil
+
+	case gp.kind == ebnfOperator && gp.value == "[":
+		body, err := gp.parseBody("]")
+		if err != nil {
+			return nil, err
+		}
+		return &Option{Body: body}, nil
+
+	case gp.kind == ebnfOperator && gp.value == "{":
+		body, err := gp.parseBody("}")
+		if err != nil {
+			return nil, err
+		}
+		return &Repetition{Body: body}, nil
+
+	default:
+		return nil, nil
+	}
+}
+
+// parseBody parses the body of a group, option or repetition, which
+// ends with the given closing operator.
+func (gp *grammarParser) parseBody(closing string) (Expression, error) {
+	gp.next() // consume the opening operator
+	body, err := gp.parseAlternative()
+	if err != nil {
+		return nil, err
+	}
+	if err := gp.expect(closing); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
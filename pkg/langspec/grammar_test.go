@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package langspec_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bassosimone/buresu/pkg/langspec"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadGrammar(t *testing.T) {
+	for _, version := range langspec.Versions() {
+		t.Run("v"+version.String(), func(t *testing.T) {
+			grammar, err := langspec.LoadGrammar(version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if grammar.Version != version {
+				t.Fatal("unexpected version", grammar.Version)
+			}
+
+			t.Run("we can parse the grammar we serialize", func(t *testing.T) {
+				var source string
+				for _, prod := range grammar.Productions {
+					source += prod.String() + "\n"
+				}
+				reparsed, err := langspec.ParseGrammar(source)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for idx, prod := range reparsed.Productions {
+					if diff := cmp.Diff(grammar.Productions[idx].String(), prod.String()); diff != "" {
+						t.Fatal(diff)
+					}
+				}
+			})
+
+			t.Run("all the productions are reachable from Program", func(t *testing.T) {
+				reachable := map[string]bool{"Program": true}
+				queue := []string{"Program"}
+				for len(queue) > 0 {
+					prod, found := grammar.Production(queue[0])
+					if !found {
+						t.Fatal("missing production", queue[0])
+					}
+					queue = queue[1:]
+					for _, name := range langspec.References(prod.Expr) {
+						if !reachable[name] {
+							reachable[name] = true
+							queue = append(queue, name)
+						}
+					}
+				}
+				for _, prod := range grammar.Productions {
+					if !reachable[prod.Name] {
+						t.Error("unreachable production", prod.Name)
+					}
+				}
+			})
+
+			t.Run("the productions and the rules are consistent", func(t *testing.T) {
+				referenced := make(map[string]bool)
+				for _, prod := range grammar.Productions {
+					for _, name := range prod.Rules {
+						referenced[name] = true
+					}
+				}
+				defined := make(map[string]bool)
+				for _, rule := range langspec.RulesFor(version) {
+					defined[rule.Name] = true
+					if !referenced[rule.Name] {
+						t.Error("rule not referenced by any production", rule.Name)
+					}
+				}
+				for name := range referenced {
+					if !defined[name] {
+						t.Error("production references undefined rule", name)
+					}
+				}
+			})
+		})
+	}
+
+	t.Run("we reject an unknown version", func(t *testing.T) {
+		if _, err := langspec.LoadGrammar(langspec.Latest + 1); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
+
+func TestParseGrammar(t *testing.T) {
+	t.Run("we parse all the expression kinds", func(t *testing.T) {
+		source := `
+			// rule: a-rule
+			A = B | "x" … "z" { B } [ "(" ] ( b | ` + "`\\`" + ` ) .
+			B = b .
+			b = /* a comment */ .
+		`
+		grammar, err := langspec.ParseGrammar(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, prod := range grammar.Productions {
+			got = append(got, prod.String())
+		}
+		expect := []string{
+			`A = B | "x" … "z" { B } [ "(" ] ( b | "\\" ) .`,
+			`B = b .`,
+			`b = .`,
+		}
+		if diff := cmp.Diff(expect, got); diff != "" {
+			t.Fatal(diff)
+		}
+		if diff := cmp.Diff([]string{"a-rule"}, grammar.Productions[0].Rules); diff != "" {
+			t.Fatal(diff)
+		}
+		if grammar.Productions[0].Lexical() || !grammar.Productions[2].Lexical() {
+			t.Fatal("unexpected lexical classification")
+		}
+	})
+
+	invalid := []struct {
+		name   string
+		source string
+	}{
+		{"undefined production", `A = B .`},
+		{"duplicate production", `A = "a" . A = "b" .`},
+		{"missing period", `A = "a"`},
+		{"unbalanced group", `A = ( "a" .`},
+		{"unterminated token", `A = "a .`},
+		{"missing equal", `A "a" .`},
+	}
+	for _, tc := range invalid {
+		t.Run("we reject "+tc.name, func(t *testing.T) {
+			_, err := langspec.ParseGrammar(tc.source)
+			if !errors.Is(err, langspec.ErrInvalidGrammar) {
+				t.Fatal("unexpected error", err)
+			}
+		})
+	}
+}
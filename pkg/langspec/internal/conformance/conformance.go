@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package conformance contains the code shared by the conformance suite
+// generator and by the tests running the conformance suite.
+package conformance
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/bassosimone/buresu/pkg/langspec"
+	"github.com/bassosimone/buresu/pkg/parser"
+	"github.com/bassosimone/buresu/pkg/scanner"
+)
+
+// Dir returns the directory containing the conformance test cases
+// of the rules the given version introduces, relative to the langspec
+// package directory.
+func Dir(version langspec.Version) string {
+	return filepath.Join("testdata", "conformance", "v"+version.String())
+}
+
+// Case is a conformance test case.
+type Case struct {
+	// Name is the name of the .txtar file.
+	Name string
+
+	// Rule is the rule the input refers to.
+	Rule *langspec.Rule
+
+	// Input is the program to parse.
+	Input string
+
+	// Valid indicates whether the program should parse.
+	Valid bool
+}
+
+// Cases returns the conformance test cases of the rules the given version
+// introduces, which also apply to the later versions containing the rules.
+func Cases(version langspec.Version) []*Case {
+	var cases []*Case
+	for _, rule := range langspec.RulesFor(version) {
+		if rule.Since != version {
+			continue
+		}
+		for idx, input := range rule.Valid {
+			cases = append(cases, &Case{
+				Name:  fmt.Sprintf("%s-valid-%d.txtar", rule.Name, idx+1),
+				Rule:  rule,
+				Input: input,
+				Valid: true,
+			})
+		}
+		for idx, input := range rule.Invalid {
+			cases = append(cases, &Case{
+				Name:  fmt.Sprintf("%s-invalid-%d.txtar", rule.Name, idx+1),
+				Rule:  rule,
+				Input: input,
+				Valid: false,
+			})
+		}
+	}
+	return cases
+}
+
+// Run scans and parses the input using the given version and returns
+// the parsed nodes, one per line, or the scanner or parser error.
+func Run(version langspec.Version, input string) (string, error) {
+	tokens, err := scanner.Scan("input.brs", strings.NewReader(input))
+	if err != nil {
+		return "", err
+	}
+	nodes, err := parser.ParseWithVersion(tokens, version)
+	if err != nil {
+		return "", err
+	}
+	var builder strings.Builder
+	for _, node := range nodes {
+		fmt.Fprintf(&builder, "%s\n", node.String())
+	}
+	return builder.String(), nil
+}
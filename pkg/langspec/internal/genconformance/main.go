@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Command genconformance regenerates the conformance suite of each
+// language version by parsing the examples of the rules that the version
+// introduces, which the tests also run against the later versions.
+//
+// We fail when a valid example does not parse or an invalid example
+// parses, since this means either the rule or the parser is wrong.
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/bassosimone/buresu/pkg/langspec"
+	"github.com/bassosimone/buresu/pkg/langspec/internal/conformance"
+	"golang.org/x/tools/txtar"
+)
+
+func main() {
+	for _, version := range langspec.Versions() {
+		if err := generate(version); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// generate regenerates the conformance test cases of the rules the given version introduces.
+func generate(version langspec.Version) error {
+	dir := conformance.Dir(version)
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, tc := range conformance.Cases(version) {
+		archive := &txtar.Archive{Files: []txtar.File{
+			{Name: "input", Data: []byte("\n" + tc.Input + "\n\n")},
+		}}
+		output, err := conformance.Run(version, tc.Input)
+		switch {
+		case tc.Valid && err != nil:
+			return fmt.Errorf("v%s: %s: unexpected error: %w", version, tc.Name, err)
+		case !tc.Valid && err == nil:
+			return fmt.Errorf("v%s: %s: expected an error", version, tc.Name)
+		case tc.Valid:
+			archive.Files = append(archive.Files, txtar.File{Name: "output", Data: []byte("\n" + output + "\n")})
+		default:
+			archive.Files = append(archive.Files, txtar.File{Name: "error", Data: []byte("\n" + err.Error() + "\n")})
+		}
+		if err := os.WriteFile(filepath.Join(dir, tc.Name), txtar.Format(archive), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,404 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package langspec
+
+// Rule is a rule of the language, which either documents a grammar
+// production or a constraint that the EBNF cannot express.
+type Rule struct {
+	// Name is the name identifying the rule (e.g., `return-only-inside-lambda`),
+	// which the `rule:` comments of the grammar productions refer to.
+	Name string
+
+	// Doc describes the rule.
+	Doc string
+
+	// Since is the first language version containing the rule.
+	Since Version
+
+	// Until is the last language version containing the rule, which is
+	// zero unless a later version replaces the rule with another one.
+	Until Version
+
+	// Valid contains programs that conform to the rule.
+	Valid []string
+
+	// Invalid contains programs that violate the rule.
+	Invalid []string
+}
+
+// RulesFor returns the rules of the given language [Version].
+func RulesFor(version Version) []*Rule {
+	var rules []*Rule
+	for _, rule := range allRules {
+		if rule.Since <= version && (rule.Until == 0 || version <= rule.Until) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// allRules contains all the rules, sorted like the grammar productions.
+var allRules = []*Rule{
+	{
+		Name:    "program",
+		Doc:     "A program is a sequence of top-level forms, which we evaluate in order.",
+		Since:   Version1,
+		Valid:   []string{"1 2 3", "(define x 1) x"},
+		Invalid: []string{"(define x 1", ")"},
+	},
+	{
+		Name:  "include-only-at-top-level",
+		Doc:   "The include!, import and module statements are only allowed at the top level.",
+		Since: Version1,
+		Valid: []string{`(module m (export x)) (include! "a.brs") (import "b.brs" as b)`},
+		Invalid: []string{
+			`(block (include! "a.brs"))`,
+			`(lambda () (block (import "b.brs" as b)))`,
+			`(define x (module m (export x)))`,
+		},
+	},
+	{
+		Name:    "literal",
+		Doc:     "Booleans, numbers, strings, characters and keywords evaluate to themselves.",
+		Since:   Version1,
+		Valid:   []string{`true false 1 -1.5 1/3 0xff "s" r"\d" #\a #\space :name`},
+		Invalid: []string{`#\unknown`, `"unterminated`},
+	},
+	{
+		Name:    "symbol",
+		Doc:     "Atoms other than true and false are symbols referring to values.",
+		Since:   Version1,
+		Valid:   []string{"x set-car! empty? m/x + <=>"},
+		Invalid: []string{"x!y", "@"},
+	},
+	{
+		Name:  "unit",
+		Doc:   "The empty list is the unit expression.",
+		Since: Version1,
+		Valid: []string{"()", "( )"},
+	},
+	{
+		Name:    "block",
+		Doc:     "A block evaluates its expressions in order and returns the value of the last one.",
+		Since:   Version1,
+		Valid:   []string{"(block 1 2 3)", "(block (define x 1) x)"},
+		Invalid: []string{"(block 1 2"},
+	},
+	{
+		Name:  "empty-block-is-unit",
+		Doc:   "An empty block is equivalent to the unit expression.",
+		Since: Version1,
+		Valid: []string{"(block)"},
+	},
+	{
+		Name:  "return",
+		Doc:   "The return! statement returns the value of its expression from the enclosing lambda.",
+		Since: Version1,
+		Valid: []string{"(lambda (x) (block (return! x)))"},
+		Invalid: []string{
+			"(lambda (x) (block (return!)))",
+			"(lambda (x) (block (return! x x)))",
+		},
+	},
+	{
+		Name:  "return-only-inside-lambda",
+		Doc:   "The return! statement is only allowed inside a block nested, at any depth, inside a lambda.",
+		Since: Version1,
+		Valid: []string{"(lambda () (while true (block (return! 1))))"},
+		Invalid: []string{
+			"(block (return! 1))",
+			"(lambda () (return! 1))",
+			"(lambda () (if true (return! 1)))",
+		},
+	},
+	{
+		Name:    "unreachable-code-after-return",
+		Doc:     "Nothing may follow a return! statement inside a block.",
+		Since:   Version1,
+		Valid:   []string{"(lambda () (block (display 1) (return! 1)))"},
+		Invalid: []string{"(lambda () (block (return! 1) (display 1)))"},
+	},
+	{
+		Name:  "break",
+		Doc:   "The break! statement leaves the innermost enclosing while loop, which evaluates to unit.",
+		Since: Version2,
+		Until: Version2,
+		Valid: []string{"(while true (block (break!)))"},
+		Invalid: []string{
+			"(while true (block (break! 1)))",
+			"(while true (break!))",
+		},
+	},
+	{
+		Name:  "break",
+		Doc:   "The break! statement leaves the innermost enclosing loop, which evaluates to unit.",
+		Since: Version3,
+		Valid: []string{"(while true (block (break!)))", "(for (i 0 3) (block (break!)))"},
+		Invalid: []string{
+			"(while true (block (break! 1)))",
+			"(foreach (x v) (break!))",
+		},
+	},
+	{
+		Name:    "continue",
+		Doc:     "The continue! statement skips to the next iteration of the innermost enclosing while loop.",
+		Since:   Version2,
+		Until:   Version2,
+		Valid:   []string{"(while (f) (block (continue!)))"},
+		Invalid: []string{"(while (f) (block (continue! 1)))"},
+	},
+	{
+		Name:    "continue",
+		Doc:     "The continue! statement skips to the next iteration of the innermost enclosing loop.",
+		Since:   Version3,
+		Valid:   []string{"(while (f) (block (continue!)))", "(foreach (x v) (block (continue!)))"},
+		Invalid: []string{"(for (i 0 3) (block (continue! 1)))"},
+	},
+	{
+		Name:  "loop-control-only-inside-while",
+		Doc:   "The break! and continue! statements are only allowed inside a block nested inside the body of a while loop, excluding nested lambdas.",
+		Since: Version2,
+		Until: Version2,
+		Valid: []string{
+			"(while (f) (block (if (g) (block (continue!))) (h)))",
+			"(lambda () (while true (block (while true (block (break!))) (break!))))",
+		},
+		Invalid: []string{
+			"(block (break!))",
+			"(lambda () (block (continue!)))",
+			"(while true (block ((lambda () (block (break!))))))",
+			"(while (block (break!)) 1)",
+		},
+	},
+	{
+		Name:  "loop-control-only-inside-loop",
+		Doc:   "The break! and continue! statements are only allowed inside a block nested inside the body of a while, for or foreach loop, excluding nested lambdas.",
+		Since: Version3,
+		Valid: []string{
+			"(while (f) (block (if (g) (block (continue!))) (h)))",
+			"(for (i 0 10) (block (foreach (x v) (block (break!))) (continue!)))",
+		},
+		Invalid: []string{
+			"(block (break!))",
+			"(for (i 0 3) (block ((lambda () (block (break!))))))",
+			"(for (i 0 (block (break!))) i)",
+			"(foreach (x (block (continue!))) x)",
+		},
+	},
+	{
+		Name:    "unreachable-code-after-loop-control",
+		Doc:     "Nothing may follow a break! or continue! statement inside a block.",
+		Since:   Version2,
+		Valid:   []string{"(while true (block (display 1) (break!)))"},
+		Invalid: []string{"(while true (block (break!) (display 1)))", "(while true (block (continue!) 1))"},
+	},
+	{
+		Name:  "yield",
+		Doc:   "The yield! statement suspends the generator running the enclosing lambda, handing the value of its expression to the consumer, and evaluates to unit once the consumer asks for the next value.",
+		Since: Version4,
+		Valid: []string{"(generator (lambda () (block (yield! 1) (yield! 2))))"},
+		Invalid: []string{
+			"(lambda () (block (yield!)))",
+			"(lambda () (block (yield! 1 2)))",
+		},
+	},
+	{
+		Name:  "yield-only-inside-lambda",
+		Doc:   "The yield! statement is only allowed inside a block nested, at any depth, inside a lambda.",
+		Since: Version4,
+		Valid: []string{"(lambda () (for (i 0 3) (block (yield! i))))"},
+		Invalid: []string{
+			"(block (yield! 1))",
+			"(lambda () (yield! 1))",
+			"(lambda () (if true (yield! 1)))",
+		},
+	},
+	{
+		Name:    "cond",
+		Doc:     "A cond evaluates the expression of the first case whose predicate is true, or the else expression, which defaults to unit.",
+		Since:   Version1,
+		Valid:   []string{"(cond ((< x 0) -1) ((> x 0) 1) (else 0))", "(cond (true 1))"},
+		Invalid: []string{"(cond (true))", "(cond (else 0) (true 1))"},
+	},
+	{
+		Name:  "cond-without-cases",
+		Doc:   "A cond without cases is equivalent to its else expression, if any, or to unit.",
+		Since: Version1,
+		Valid: []string{"(cond)", "(cond (else 1))"},
+	},
+	{
+		Name:    "if",
+		Doc:     "An if is equivalent to a cond with a single case, whose else expression defaults to unit.",
+		Since:   Version1,
+		Valid:   []string{"(if true 1 2)", "(if true 1)"},
+		Invalid: []string{"(if true)", "(if true 1 2 3)"},
+	},
+	{
+		Name:    "declare",
+		Doc:     "A declare defines a symbol whose value is a lambda, typically with an unspecified body.",
+		Since:   Version1,
+		Valid:   []string{`(declare f (lambda (x) "(@ x Int Int)" ...))`},
+		Invalid: []string{"(declare f 1)", "(declare (f) (lambda () ...))"},
+	},
+	{
+		Name:    "define",
+		Doc:     "A define binds a symbol in the current scope to the value of an expression.",
+		Since:   Version1,
+		Valid:   []string{"(define x 1)"},
+		Invalid: []string{"(define x)", "(define 1 x)"},
+	},
+	{
+		Name:    "set",
+		Doc:     "A set! assigns the value of an expression to an existing symbol.",
+		Since:   Version1,
+		Valid:   []string{"(set! x 1)"},
+		Invalid: []string{"(set! x)", "(set! (x) 1)"},
+	},
+	{
+		Name:    "lambda",
+		Doc:     "A lambda has parameters, optional documentation containing the type annotation, and a body.",
+		Since:   Version1,
+		Valid:   []string{"(lambda () 1)", `(lambda (x y) "Adds x and y." (+ x y))`},
+		Invalid: []string{"(lambda x x)", "(lambda (x))", "(lambda (1) 1)"},
+	},
+	{
+		Name:    "unique-lambda-params",
+		Doc:     "The parameters of a lambda must be unique.",
+		Since:   Version1,
+		Valid:   []string{"(lambda (x y) x)"},
+		Invalid: []string{"(lambda (x x) x)"},
+	},
+	{
+		Name:  "ellipsis-only-as-lambda-body",
+		Doc:   "The `...` ellipsis, denoting an unspecified body, is only allowed as the body of a lambda.",
+		Since: Version1,
+		Valid: []string{"(lambda (x) ...)"},
+		Invalid: []string{
+			"...",
+			"(lambda (x) (block ...))",
+			"(f ...)",
+		},
+	},
+	{
+		Name:  "let",
+		Doc:   "The let, let* and letrec forms bind symbols to values in a new scope and evaluate an expression.",
+		Since: Version1,
+		Valid: []string{
+			"(let ((x 1) (y 2)) (+ x y))",
+			"(let* ((x 1) (x (+ x 1))) x)",
+			"(letrec ((f (lambda () (g))) (g (lambda () 1))) (f))",
+		},
+		Invalid: []string{"(let (x 1) x)", "(let ((x 1)))", "(let* ((1 x)) x)"},
+	},
+	{
+		Name:    "unique-let-bindings",
+		Doc:     "The symbols bound by let and letrec must be unique, while let* allows binding a symbol again.",
+		Since:   Version1,
+		Valid:   []string{"(let* ((x 1) (x 2)) x)"},
+		Invalid: []string{"(let ((x 1) (x 2)) x)", "(letrec ((x 1) (x 2)) x)"},
+	},
+	{
+		Name:    "while",
+		Doc:     "A while evaluates its body as long as the predicate is true.",
+		Since:   Version1,
+		Valid:   []string{"(while (< x 10) (set! x (+ x 1)))"},
+		Invalid: []string{"(while true)", "(while true 1 2)"},
+	},
+	{
+		Name:  "for",
+		Doc:   "A for evaluates its body binding, in a new scope for each iteration, the symbol to the integers from the first bound (included) to the second one (excluded).",
+		Since: Version3,
+		Valid: []string{
+			"(for (i 0 10) (display i))",
+			"(for (i 0 10) (block (if (< i 5) (block (continue!))) (display i)))",
+		},
+		Invalid: []string{"(for (i 0) i)", "(for i 0 10 i)", "(for (1 0 10) 1)", "(for (i 0 10))"},
+	},
+	{
+		Name:  "foreach",
+		Doc:   "A foreach evaluates its body binding, in a new scope for each iteration, the symbol to the elements of a sequence (e.g., the characters of a string).",
+		Since: Version3,
+		Valid: []string{
+			`(foreach (c "abc") (display c))`,
+			"(foreach (x (vector 1 2 3)) (block (if (< 1 x) (block (break!))) (display x)))",
+		},
+		Invalid: []string{"(foreach (x) x)", "(foreach (x v w) x)", "(foreach x v x)"},
+	},
+	{
+		Name:    "quote",
+		Doc:     "A quote returns its expression without evaluating it, and `'x` abbreviates `(quote x)`.",
+		Since:   Version1,
+		Valid:   []string{"(quote (a b))", "'(a b)", "''x"},
+		Invalid: []string{"(quote)", "(quote a b)", "'"},
+	},
+	{
+		Name:    "quasiquote",
+		Doc:     "A quasiquote returns its expression without evaluating it, except for the unquoted expressions.",
+		Since:   Version1,
+		Valid:   []string{"`(a ,b ,@c)", "`(a `(b ,,c))"},
+		Invalid: []string{"`"},
+	},
+	{
+		Name:    "unquote-only-inside-quasiquote",
+		Doc:     "The `,` unquote and the `,@` unquote-splicing are only allowed inside a quasiquote.",
+		Since:   Version1,
+		Valid:   []string{"`,x"},
+		Invalid: []string{",x", "(f ,@x)", "`(a ,,b)"},
+	},
+	{
+		Name:    "splicing-only-inside-list",
+		Doc:     "The `,@` unquote-splicing is only allowed among the elements of a list.",
+		Since:   Version1,
+		Valid:   []string{"`(,@x)", "`(block ,@x)"},
+		Invalid: []string{"`,@x", "`(define x ,@y)"},
+	},
+	{
+		Name:    "call",
+		Doc:     "A non-empty list that is not a special form calls the value of its first element with the other ones.",
+		Since:   Version1,
+		Valid:   []string{"(f)", "(f 1 2)", "((lambda (x) x) 1)"},
+		Invalid: []string{"(f 1"},
+	},
+	{
+		Name:    "include",
+		Doc:     "An include! statement includes the content of the given file, once.",
+		Since:   Version1,
+		Valid:   []string{`(include! "lib/a.brs")`},
+		Invalid: []string{"(include! a)", `(include! "a.brs" "b.brs")`},
+	},
+	{
+		Name:    "import",
+		Doc:     "An import statement loads the given module, whose exports we access using the alias as prefix.",
+		Since:   Version1,
+		Valid:   []string{`(import "lib/m.brs" as m)`},
+		Invalid: []string{`(import "lib/m.brs")`, `(import "lib/m.brs" m)`},
+	},
+	{
+		Name:    "import-alias-without-slash",
+		Doc:     "The alias of an import must not contain `/`, which separates the alias from the exported name.",
+		Since:   Version1,
+		Valid:   []string{`(import "lib/m.brs" as lib-m)`},
+		Invalid: []string{`(import "lib/m.brs" as lib/m)`},
+	},
+	{
+		Name:    "module",
+		Doc:     "A module statement declares that the file is a module and lists the symbols it exports.",
+		Since:   Version1,
+		Valid:   []string{"(module m (export))", "(module m (export x y))"},
+		Invalid: []string{"(module m)", "(module m (x y))"},
+	},
+	{
+		Name:    "unique-module-exports",
+		Doc:     "The symbols exported by a module must be unique.",
+		Since:   Version1,
+		Valid:   []string{"(module m (export x y))"},
+		Invalid: []string{"(module m (export x x))"},
+	},
+	{
+		Name:    "number-literal-range",
+		Doc:     "Integers must fit 64 bits, floats must be finite, and rationals must have a nonzero denominator.",
+		Since:   Version1,
+		Valid:   []string{"9223372036854775807 -9223372036854775808 1.7976931348623157e308 1/2"},
+		Invalid: []string{"9223372036854775808", "1e999", "1/0"},
+	},
+}
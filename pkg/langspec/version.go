@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package langspec
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a version of the language, which allows to gate
+// syntax changes such that older programs keep working.
+type Version int
+
+const (
+	// Version1 is the first version of the language.
+	Version1 = Version(1)
+
+	// Version2 adds the break! and continue! statements.
+	Version2 = Version(2)
+
+	// Version3 adds the for and foreach loops.
+	Version3 = Version(3)
+
+	// Version4 adds the yield! statement for generators.
+	Version4 = Version(4)
+
+	// Latest is the latest version of the language.
+	Latest = Version4
+)
+
+// Versions returns all the versions of the language, in ascending order.
+func Versions() []Version {
+	var versions []Version
+	for version := Version1; version <= Latest; version++ {
+		versions = append(versions, version)
+	}
+	return versions
+}
+
+// String returns the version number as a string (e.g., `1`).
+func (v Version) String() string {
+	return strconv.Itoa(int(v))
+}
+
+// ParseVersion parses the given version, which is either a version
+// number (e.g., `1`) or `latest`, which denotes the [Latest] version.
+func ParseVersion(value string) (Version, error) {
+	if value == "latest" {
+		return Latest, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < int(Version1) || number > int(Latest) {
+		return 0, fmt.Errorf("unknown language version: %s (supported: %s, latest)", value, versionsList())
+	}
+	return Version(number), nil
+}
+
+// versionsList returns the comma separated list of all the versions.
+func versionsList() string {
+	var values []string
+	for _, version := range Versions() {
+		values = append(values, version.String())
+	}
+	return strings.Join(values, ", ")
+}
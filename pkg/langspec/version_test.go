@@ -0,0 +1,35 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package langspec_test
+
+import (
+	"testing"
+
+	"github.com/bassosimone/buresu/pkg/langspec"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		value  string
+		expect langspec.Version
+		err    bool
+	}{
+		{"latest", langspec.Latest, false},
+		{"1", langspec.Version1, false},
+		{"0", 0, true},
+		{(langspec.Latest + 1).String(), 0, true},
+		{"v1", 0, true},
+		{"", 0, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.value, func(t *testing.T) {
+			version, err := langspec.ParseVersion(tc.value)
+			if (err != nil) != tc.err {
+				t.Fatal("unexpected error", err)
+			}
+			if version != tc.expect {
+				t.Fatal("unexpected version", version)
+			}
+		})
+	}
+}
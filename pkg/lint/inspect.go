@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package lint
+
+import "github.com/bassosimone/buresu/pkg/ast"
+
+// Inspect traverses the given node in depth-first order calling f for
+// each node. If f returns false, we do not traverse the node children.
+//
+// We do not traverse the nodes of the modules loaded by import statements,
+// since they belong to other files, nor the quoted expressions, since
+// they are data rather than code.
+func Inspect(node ast.Node, f func(ast.Node) bool) {
+	if node == nil || !f(node) {
+		return
+	}
+	for _, child := range children(node) {
+		Inspect(child, f)
+	}
+}
+
+// children returns the children of the given node.
+func children(node ast.Node) []ast.Node {
+	switch node := node.(type) {
+	case *ast.BlockExpr:
+		return node.Exprs
+
+	case *ast.CallExpr:
+		return append([]ast.Node{node.Callable}, node.Args...)
+
+	case *ast.CondExpr:
+		var nodes []ast.Node
+		for _, c := range node.Cases {
+			nodes = append(nodes, c.Predicate, c.Expr)
+		}
+		return append(nodes, node.ElseExpr)
+
+	case *ast.DeclareExpr:
+		return []ast.Node{node.Expr}
+
+	case *ast.DefineExpr:
+		return []ast.Node{node.Expr}
+
+	case *ast.ForExpr:
+		return []ast.Node{node.From, node.To, node.Expr}
+
+	case *ast.ForeachExpr:
+		return []ast.Node{node.Seq, node.Expr}
+
+	case *ast.LambdaExpr:
+		return []ast.Node{node.Expr}
+
+	case *ast.LetExpr:
+		return letChildren(node.Bindings, node.Expr)
+
+	case *ast.LetStarExpr:
+		return letChildren(node.Bindings, node.Expr)
+
+	case *ast.LetrecExpr:
+		return letChildren(node.Bindings, node.Expr)
+
+	case *ast.QuasiquoteExpr:
+		var nodes []ast.Node
+		for _, unquote := range node.Unquotes() {
+			nodes = append(nodes, unquote.Expr)
+		}
+		return nodes
+
+	case *ast.ReturnStmt:
+		return []ast.Node{node.Expr}
+
+	case *ast.SetExpr:
+		return []ast.Node{node.Expr}
+
+	case *ast.WhileExpr:
+		return []ast.Node{node.Predicate, node.Expr}
+
+	case *ast.YieldStmt:
+		return []ast.Node{node.Expr}
+
+	default:
+		return nil
+	}
+}
+
+// letChildren returns the children of a let, let* or letrec form.
+func letChildren(bindings []ast.LetBinding, expr ast.Node) []ast.Node {
+	var nodes []ast.Node
+	for _, binding := range bindings {
+		nodes = append(nodes, binding.Expr)
+	}
+	return append(nodes, expr)
+}
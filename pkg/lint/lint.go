@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package lint implements a static analyzer for Buresu programs.
+//
+// The analyzer consists of [*Rule] passes over the AST, each of which
+// reports [Diagnostic] values for the code it deems suspicious. Use
+// [Rules] to obtain all the available rules, [Lookup] to find a rule
+// by name, and [Run] to run the selected rules over the nodes.
+//
+// The rules resolve the symbols lexically, therefore, to avoid false
+// positives, you should run them on the nodes returned by the includer.
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/token"
+)
+
+// Diagnostic is a problem found by a [*Rule].
+type Diagnostic struct {
+	Pos     token.Position
+	Rule    string
+	Message string
+}
+
+// String returns the diagnostic message with file position details.
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: lint: %s (%s)", d.Pos, d.Message, d.Rule)
+}
+
+// Rule is a lint rule.
+type Rule struct {
+	// Name is the name identifying the rule (e.g., `unused-param`).
+	Name string
+
+	// Doc is a one-line description of the rule.
+	Doc string
+
+	// Run runs the rule reporting diagnostics using the pass.
+	Run func(pass *Pass)
+}
+
+// Pass contains the nodes a [*Rule] should analyze.
+type Pass struct {
+	// Nodes contains the nodes to analyze.
+	Nodes []ast.Node
+
+	// bindings contains the lazily-resolved bindings.
+	bindings *resolution
+
+	// diagnostics contains the diagnostics reported so far.
+	diagnostics []Diagnostic
+
+	// rule is the rule currently running.
+	rule *Rule
+}
+
+// Reportf reports a diagnostic for the current rule at the given token.
+func (pass *Pass) Reportf(tok token.Token, format string, args ...any) {
+	pass.diagnostics = append(pass.diagnostics, Diagnostic{
+		Pos:     tok.TokenPos,
+		Rule:    pass.rule.Name,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// resolve returns the result of resolving the symbols, which
+// we compute once and share across the rules needing it.
+func (pass *Pass) resolve() *resolution {
+	if pass.bindings == nil {
+		pass.bindings = resolve(pass.Nodes)
+	}
+	return pass.bindings
+}
+
+// Run runs the given rules over the given nodes and returns the
+// diagnostics sorted by position and then by rule name.
+func Run(nodes []ast.Node, rules []*Rule) []Diagnostic {
+	pass := &Pass{Nodes: nodes}
+	for _, rule := range rules {
+		pass.rule = rule
+		rule.Run(pass)
+	}
+	slices.SortStableFunc(pass.diagnostics, func(a, b Diagnostic) int {
+		switch {
+		case a.Pos.FileName != b.Pos.FileName:
+			return strings.Compare(a.Pos.FileName, b.Pos.FileName)
+		case a.Pos.LineNumber != b.Pos.LineNumber:
+			return a.Pos.LineNumber - b.Pos.LineNumber
+		case a.Pos.LineColumn != b.Pos.LineColumn:
+			return a.Pos.LineColumn - b.Pos.LineColumn
+		default:
+			return strings.Compare(a.Rule, b.Rule)
+		}
+	})
+	return pass.diagnostics
+}
+
+// Rules returns all the available rules sorted by name.
+func Rules() []*Rule {
+	return []*Rule{
+		condWithoutElse,
+		infiniteLoop,
+		setUndefined,
+		shadow,
+		unreachableCode,
+		unusedDefine,
+		unusedParam,
+	}
+}
+
+// Lookup returns the rule with the given name, if any.
+func Lookup(name string) (*Rule, bool) {
+	for _, rule := range Rules() {
+		if rule.Name == name {
+			return rule, true
+		}
+	}
+	return nil, false
+}
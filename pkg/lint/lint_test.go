@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package lint_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bassosimone/buresu/internal/txtartesting"
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/includer"
+	"github.com/bassosimone/buresu/pkg/lint"
+	"github.com/bassosimone/buresu/pkg/parser"
+	"github.com/bassosimone/buresu/pkg/scanner"
+)
+
+// parse scans and parses the given source code.
+func parse(t *testing.T, filename, source string) []ast.Node {
+	tokens, err := scanner.Scan(filename, strings.NewReader(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes, err := parser.Parse(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return nodes
+}
+
+func TestRun(t *testing.T) {
+	testCases, err := txtartesting.LoadTestCases("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var lines []string
+			for _, diag := range lint.Run(parse(t, "input.brs", tc.Input), lint.Rules()) {
+				lines = append(lines, diag.String())
+			}
+			if err := tc.CompareTextOutput(strings.Join(lines, "\n")); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestRunCleanCode(t *testing.T) {
+	files := []string{
+		filepath.Join("..", "..", "example", "fact.brs"),
+		filepath.Join("..", "..", "example", "fib.brs"),
+		filepath.Join("..", "..", "stdlib", "prelude.brs"),
+	}
+	for _, file := range files {
+		t.Run(file, func(t *testing.T) {
+			source, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			nodes, err := includer.Include(nil, parse(t, file, string(source)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, diag := range lint.Run(nodes, lint.Rules()) {
+				t.Errorf("unexpected diagnostic: %s", diag)
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for _, rule := range lint.Rules() {
+		got, found := lint.Lookup(rule.Name)
+		if !found || got != rule {
+			t.Fatalf("cannot find rule %s", rule.Name)
+		}
+	}
+	if _, found := lint.Lookup("nonexistent"); found {
+		t.Fatal("found nonexistent rule")
+	}
+}
+
+func TestInspect(t *testing.T) {
+	nodes := parse(t, "input.brs", `(define f (lambda (x) (block (quote (a b)) (+ x 1))))`)
+	var visited []string
+	lint.Inspect(nodes[0], func(node ast.Node) bool {
+		visited = append(visited, node.String())
+		_, isCall := node.(*ast.CallExpr)
+		return !isCall
+	})
+	expected := []string{
+		`(define f (lambda (x) "" (block (quote (a b)) (+ x 1))))`,
+		`(lambda (x) "" (block (quote (a b)) (+ x 1)))`,
+		`(block (quote (a b)) (+ x 1))`,
+		`(quote (a b))`,
+		`(+ x 1)`,
+	}
+	if strings.Join(visited, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("unexpected nodes:\n%s", strings.Join(visited, "\n"))
+	}
+}
@@ -0,0 +1,226 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package lint
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+)
+
+// condWithoutElse flags cond forms lacking an else branch, which evaluate
+// to Unit when no predicate is true.
+//
+// Because the parser desugars `(if <predicate> <expr>)` into a cond with
+// a single case, we only flag conds with at least two cases, which cannot
+// originate from an if, to avoid flagging the idiomatic if without else.
+var condWithoutElse = &Rule{
+	Name: "cond-without-else",
+	Doc:  "Flag cond forms with two or more cases but no else branch.",
+	Run: func(pass *Pass) {
+		for _, node := range pass.Nodes {
+			Inspect(node, func(node ast.Node) bool {
+				cond, ok := node.(*ast.CondExpr)
+				if !ok || len(cond.Cases) < 2 {
+					return true
+				}
+				// the parser uses the cond token for the implicit else branch
+				if unit, ok := cond.ElseExpr.(*ast.UnitExpr); ok && unit.Token.TokenPos == cond.Token.TokenPos {
+					pass.Reportf(cond.Token, "cond without else branch evaluates to () when no case matches")
+				}
+				return true
+			})
+		}
+	},
+}
+
+// infiniteLoop flags while loops whose predicate is the true literal and
+// whose body does not contain any return or break statement to exit the loop,
+// or any yield statement suspending the generator running the loop.
+var infiniteLoop = &Rule{
+	Name: "infinite-loop",
+	Doc:  "Flag while loops with a constant true predicate and no return!, break! or yield! in the body.",
+	Run: func(pass *Pass) {
+		for _, node := range pass.Nodes {
+			Inspect(node, func(node ast.Node) bool {
+				loop, ok := node.(*ast.WhileExpr)
+				if !ok {
+					return true
+				}
+				if _, ok := loop.Predicate.(*ast.TrueLiteral); ok && !containsReturn(loop.Expr) && !containsBreak(loop.Expr) && !containsYield(loop.Expr) {
+					pass.Reportf(loop.Token, "while loop with true predicate never terminates")
+				}
+				return true
+			})
+		}
+	},
+}
+
+// containsReturn returns whether the given node contains a return
+// statement that is not nested inside another lambda.
+func containsReturn(node ast.Node) (found bool) {
+	Inspect(node, func(node ast.Node) bool {
+		switch node.(type) {
+		case *ast.ReturnStmt:
+			found = true
+			return false
+		case *ast.LambdaExpr:
+			return false // returns from the nested lambda
+		default:
+			return !found
+		}
+	})
+	return
+}
+
+// containsYield returns whether the given node contains a yield
+// statement that is not nested inside another lambda.
+func containsYield(node ast.Node) (found bool) {
+	Inspect(node, func(node ast.Node) bool {
+		switch node.(type) {
+		case *ast.YieldStmt:
+			found = true
+			return false
+		case *ast.LambdaExpr:
+			return false // yields from the nested lambda
+		default:
+			return !found
+		}
+	})
+	return
+}
+
+// containsBreak returns whether the given node contains a break
+// statement that is not nested inside another loop or lambda.
+func containsBreak(node ast.Node) (found bool) {
+	Inspect(node, func(node ast.Node) bool {
+		switch node.(type) {
+		case *ast.BreakStmt:
+			found = true
+			return false
+		case *ast.LambdaExpr, *ast.ForExpr, *ast.ForeachExpr, *ast.WhileExpr:
+			return false // breaks the nested loop
+		default:
+			return !found
+		}
+	})
+	return
+}
+
+// setUndefined flags set! expressions assigning symbols that the
+// program does not define, which fail at runtime.
+var setUndefined = &Rule{
+	Name: "set-undefined",
+	Doc:  "Flag set! of symbols that are not defined in scope.",
+	Run: func(pass *Pass) {
+		for _, set := range pass.resolve().undefinedSets {
+			pass.Reportf(set.Token, "set! of undefined symbol %s", set.Symbol)
+		}
+	},
+}
+
+// shadow flags bindings hiding a binding of an enclosing scope.
+var shadow = &Rule{
+	Name: "shadow",
+	Doc:  "Flag symbols shadowing a symbol defined in an enclosing scope.",
+	Run: func(pass *Pass) {
+		for _, s := range pass.resolve().shadowings {
+			pass.Reportf(s.inner.tok, "%s %s shadows the %s defined at %s",
+				s.inner.kind, s.inner.name, s.outer.kind, s.outer.tok.TokenPos)
+		}
+	},
+}
+
+// unreachableCode flags the expressions of a block following an expression
+// that always returns, which we never evaluate. The parser already rejects
+// expressions directly following return!, so we deal with expressions such as
+// nested blocks ending with return! and conds whose branches all return.
+var unreachableCode = &Rule{
+	Name: "unreachable-code",
+	Doc:  "Flag expressions following an expression that always executes return!.",
+	Run: func(pass *Pass) {
+		for _, node := range pass.Nodes {
+			Inspect(node, func(node ast.Node) bool {
+				block, ok := node.(*ast.BlockExpr)
+				if !ok {
+					return true
+				}
+				for idx, expr := range block.Exprs[:max(len(block.Exprs)-1, 0)] {
+					if alwaysReturns(expr) {
+						pass.Reportf(ast.NodeToken(block.Exprs[idx+1]), "unreachable code after return!")
+						break
+					}
+				}
+				return true
+			})
+		}
+	},
+}
+
+// alwaysReturns returns whether evaluating the given node always
+// executes a return statement of the enclosing lambda.
+func alwaysReturns(node ast.Node) bool {
+	switch node := node.(type) {
+	case *ast.ReturnStmt:
+		return true
+
+	case *ast.BlockExpr:
+		return slices.ContainsFunc(node.Exprs, alwaysReturns)
+
+	case *ast.CondExpr:
+		if len(node.Cases) > 0 && alwaysReturns(node.Cases[0].Predicate) {
+			return true
+		}
+		for _, c := range node.Cases {
+			if !alwaysReturns(c.Expr) {
+				return false
+			}
+		}
+		return alwaysReturns(node.ElseExpr)
+
+	case *ast.LetExpr:
+		return alwaysReturns(node.Expr)
+
+	case *ast.LetStarExpr:
+		return alwaysReturns(node.Expr)
+
+	case *ast.LetrecExpr:
+		return alwaysReturns(node.Expr)
+
+	default:
+		return false
+	}
+}
+
+// unusedDefine flags local define and let bindings that we never read,
+// ignoring the global ones, which other files may use.
+var unusedDefine = &Rule{
+	Name: "unused-define",
+	Doc:  "Flag local define and let bindings that are never used.",
+	Run: func(pass *Pass) {
+		for _, b := range pass.resolve().bindings {
+			if b.global || b.uses > 0 || strings.HasPrefix(b.name, "_") {
+				continue
+			}
+			if b.kind == bindingDefine || b.kind == bindingLet {
+				pass.Reportf(b.tok, "%s %s is never used", b.kind, b.name)
+			}
+		}
+	},
+}
+
+// unusedParam flags lambda parameters that we never read, ignoring the
+// parameters starting with `_` and the ones of declared lambdas.
+var unusedParam = &Rule{
+	Name: "unused-param",
+	Doc:  "Flag lambda parameters that are never used (use a `_` prefix to silence).",
+	Run: func(pass *Pass) {
+		for _, b := range pass.resolve().bindings {
+			if b.kind != bindingParameter || b.declaration || b.uses > 0 || strings.HasPrefix(b.name, "_") {
+				continue
+			}
+			pass.Reportf(b.tok, "parameter %s is never used", b.name)
+		}
+	},
+}
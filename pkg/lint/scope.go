@@ -0,0 +1,269 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package lint
+
+import (
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/token"
+)
+
+// binding is a symbol bound by define, declare, lambda, let or a loop.
+type binding struct {
+	// name is the symbol name.
+	name string
+
+	// kind describes how we bound the symbol (e.g., "parameter").
+	kind string
+
+	// tok is the token of the form binding the symbol.
+	tok token.Token
+
+	// global indicates whether the symbol is bound in the global scope.
+	global bool
+
+	// lambda indicates whether the symbol is defined as a lambda, in
+	// which case defining it again creates an overload.
+	lambda bool
+
+	// declaration indicates whether the symbol is the parameter of
+	// a lambda with an unspecified (i.e., `...`) body.
+	declaration bool
+
+	// uses counts the number of times we read the symbol.
+	uses int
+}
+
+// These are the kinds of bindings.
+const (
+	bindingDeclare   = "declare"
+	bindingDefine    = "define"
+	bindingLet       = "let binding"
+	bindingLoop      = "loop variable"
+	bindingParameter = "parameter"
+)
+
+// shadowing is a binding shadowing another binding.
+type shadowing struct {
+	inner, outer *binding
+}
+
+// resolution is the result of resolving the symbols.
+type resolution struct {
+	// bindings contains all the bindings in the order we created them.
+	bindings []*binding
+
+	// shadowings contains the bindings shadowing other bindings.
+	shadowings []shadowing
+
+	// undefinedSets contains the set! expressions of undefined symbols.
+	undefinedSets []*ast.SetExpr
+}
+
+// scope is a lexical scope.
+type scope struct {
+	parent   *scope
+	bindings map[string]*binding
+}
+
+// lookup returns the binding of the given symbol, searching the parents.
+func (sc *scope) lookup(name string) *binding {
+	for cur := sc; cur != nil; cur = cur.parent {
+		if b, found := cur.bindings[name]; found {
+			return b
+		}
+	}
+	return nil
+}
+
+// resolver resolves the symbols lexically.
+type resolver struct {
+	global *scope
+	result *resolution
+}
+
+// resolve resolves the symbols of the given nodes.
+func resolve(nodes []ast.Node) *resolution {
+	r := &resolver{
+		global: &scope{bindings: map[string]*binding{}},
+		result: &resolution{},
+	}
+
+	// register all the global symbols first, since a global
+	// lambda may reference symbols defined after it
+	for _, node := range nodes {
+		switch node := node.(type) {
+		case *ast.DefineExpr:
+			r.bind(r.global, node.Symbol, bindingDefine, node.Token, isLambda(node.Expr), false)
+		case *ast.DeclareExpr:
+			r.bind(r.global, node.Symbol, bindingDeclare, node.Token, true, false)
+		case *ast.ImportStmt:
+			r.bindImports(node)
+		}
+	}
+
+	for _, node := range nodes {
+		r.walk(r.global, node)
+	}
+	return r.result
+}
+
+// bindImports binds the symbols exported by the imported module as `<alias>/<name>`.
+func (r *resolver) bindImports(node *ast.ImportStmt) {
+	if len(node.Nodes) <= 0 {
+		return
+	}
+	if decl, ok := node.Nodes[0].(*ast.ModuleStmt); ok {
+		for _, symbol := range decl.Exports {
+			r.bind(r.global, node.Alias+"/"+symbol, bindingDefine, node.Token, false, false)
+		}
+	}
+}
+
+// bind binds the given symbol in the given scope, unless it is already bound
+// there, in which case the new definition replaces or overloads the existing one.
+func (r *resolver) bind(sc *scope, name, kind string, tok token.Token, lambda, declaration bool) {
+	if _, found := sc.bindings[name]; found {
+		return
+	}
+	b := &binding{
+		name:        name,
+		kind:        kind,
+		tok:         tok,
+		global:      sc == r.global,
+		lambda:      lambda,
+		declaration: declaration,
+	}
+	if sc.parent != nil {
+		// defining a lambda with the same name of a lambda defined in
+		// a parent scope creates an overload rather than shadowing it
+		if outer := sc.parent.lookup(name); outer != nil && !(outer.lambda && lambda && kind == bindingDefine) {
+			r.result.shadowings = append(r.result.shadowings, shadowing{inner: b, outer: outer})
+		}
+	}
+	sc.bindings[name] = b
+	r.result.bindings = append(r.result.bindings, b)
+}
+
+// push creates a new child scope of the given scope.
+func (r *resolver) push(parent *scope) *scope {
+	return &scope{parent: parent, bindings: map[string]*binding{}}
+}
+
+// walk resolves the symbols of the given node inside the given scope.
+func (r *resolver) walk(sc *scope, node ast.Node) {
+	switch node := node.(type) {
+	case *ast.BlockExpr:
+		child := r.push(sc)
+		for _, expr := range node.Exprs {
+			switch expr := expr.(type) {
+			case *ast.DefineExpr:
+				r.bind(child, expr.Symbol, bindingDefine, expr.Token, isLambda(expr.Expr), false)
+			case *ast.DeclareExpr:
+				r.bind(child, expr.Symbol, bindingDeclare, expr.Token, true, false)
+			}
+		}
+		for _, expr := range node.Exprs {
+			r.walk(child, expr)
+		}
+
+	case *ast.CallExpr:
+		r.walk(sc, node.Callable)
+		for _, arg := range node.Args {
+			r.walk(sc, arg)
+		}
+
+	case *ast.CondExpr:
+		for _, c := range node.Cases {
+			r.walk(sc, c.Predicate)
+			r.walk(sc, c.Expr)
+		}
+		r.walk(sc, node.ElseExpr)
+
+	case *ast.DeclareExpr:
+		// the body of a declared lambda is usually `...`
+		r.bind(sc, node.Symbol, bindingDeclare, node.Token, true, false)
+
+	case *ast.DefineExpr:
+		r.walk(sc, node.Expr)
+		r.bind(sc, node.Symbol, bindingDefine, node.Token, isLambda(node.Expr), false)
+
+	case *ast.ForExpr:
+		r.walk(sc, node.From)
+		r.walk(sc, node.To)
+		child := r.push(sc)
+		r.bind(child, node.Symbol, bindingLoop, node.Token, false, false)
+		r.walk(child, node.Expr)
+
+	case *ast.ForeachExpr:
+		r.walk(sc, node.Seq)
+		child := r.push(sc)
+		r.bind(child, node.Symbol, bindingLoop, node.Token, false, false)
+		r.walk(child, node.Expr)
+
+	case *ast.LambdaExpr:
+		child := r.push(sc)
+		_, declaration := node.Expr.(*ast.EllipsisLiteral)
+		for _, param := range node.Params {
+			r.bind(child, param, bindingParameter, node.Token, false, declaration)
+		}
+		r.walk(child, node.Expr)
+
+	case *ast.LetExpr:
+		child := r.push(sc)
+		for _, b := range node.Bindings {
+			r.walk(sc, b.Expr)
+			r.bind(child, b.Symbol, bindingLet, node.Token, isLambda(b.Expr), false)
+		}
+		r.walk(child, node.Expr)
+
+	case *ast.LetStarExpr:
+		child := r.push(sc)
+		for _, b := range node.Bindings {
+			r.walk(child, b.Expr)
+			r.bind(child, b.Symbol, bindingLet, node.Token, isLambda(b.Expr), false)
+		}
+		r.walk(child, node.Expr)
+
+	case *ast.LetrecExpr:
+		child := r.push(sc)
+		for _, b := range node.Bindings {
+			r.bind(child, b.Symbol, bindingLet, node.Token, isLambda(b.Expr), false)
+		}
+		for _, b := range node.Bindings {
+			r.walk(child, b.Expr)
+		}
+		r.walk(child, node.Expr)
+
+	case *ast.QuasiquoteExpr:
+		for _, unquote := range node.Unquotes() {
+			r.walk(sc, unquote.Expr)
+		}
+
+	case *ast.ReturnStmt:
+		r.walk(sc, node.Expr)
+
+	case *ast.SetExpr:
+		if sc.lookup(node.Symbol) == nil {
+			r.result.undefinedSets = append(r.result.undefinedSets, node)
+		}
+		r.walk(sc, node.Expr)
+
+	case *ast.SymbolName:
+		if b := sc.lookup(node.Value); b != nil {
+			b.uses++
+		}
+
+	case *ast.WhileExpr:
+		r.walk(sc, node.Predicate)
+		r.walk(sc, node.Expr)
+
+	case *ast.YieldStmt:
+		r.walk(sc, node.Expr)
+	}
+}
+
+// isLambda returns whether the given node is a lambda.
+func isLambda(node ast.Node) bool {
+	_, ok := node.(*ast.LambdaExpr)
+	return ok
+}
@@ -3,14 +3,17 @@
 package parser
 
 import (
+	"errors"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/bassosimone/buresu/pkg/ast"
 	"github.com/bassosimone/buresu/pkg/token"
 )
 
 // parseEllipsis parses the `...` token in a lambda body.
-func (p *parser) parseEllipsis(flags int) (ast.Node, error) {
+func (p *Parser) parseEllipsis(flags int) (ast.Node, error) {
 	// Syntax: ELLIPSIS
 	tok, err := p.match(token.ELLIPSIS)
 	if err != nil {
@@ -19,11 +22,11 @@ func (p *parser) parseEllipsis(flags int) (ast.Node, error) {
 	if flags&allowEllipsis == 0 {
 		return nil, newError(tok, "unexpected ELLIPSIS token")
 	}
-	return &ast.EllipsisLiteral{Token: tok}, nil
+	return &ast.EllipsisLiteral{Token: tok, End: tok.TokenEnd}, nil
 }
 
 // parseSymbol parses an atom token into an AST node.
-func (p *parser) parseSymbol() (ast.Node, error) {
+func (p *Parser) parseSymbol() (ast.Node, error) {
 	// Syntax: ATOM
 	tok, err := p.match(token.ATOM)
 	if err != nil {
@@ -32,38 +35,86 @@ func (p *parser) parseSymbol() (ast.Node, error) {
 	var rv ast.Node
 	switch {
 	case tok.Value == "false":
-		rv = &ast.FalseLiteral{Token: tok}
+		rv = &ast.FalseLiteral{Token: tok, End: tok.TokenEnd}
 	case tok.Value == "true":
-		rv = &ast.TrueLiteral{Token: tok}
+		rv = &ast.TrueLiteral{Token: tok, End: tok.TokenEnd}
 	default:
-		rv = &ast.SymbolName{Token: tok, Value: tok.Value}
+		rv = &ast.SymbolName{Token: tok, End: tok.TokenEnd, Value: tok.Value}
 	}
 	return rv, nil
 }
 
-// parseNumber parses a number token into an AST node.
-func (p *parser) parseNumber() (ast.Node, error) {
+// parseNumber parses a number token into an AST node, making sure
+// that the value of the number fits the corresponding type.
+func (p *Parser) parseNumber() (ast.Node, error) {
 	// Syntax: NUMBER
 	tok, err := p.match(token.NUMBER)
 	if err != nil {
 		return nil, err
 	}
-	var rv ast.Node
-	if strings.Contains(tok.Value, ".") {
-		rv = &ast.FloatLiteral{Token: tok, Value: tok.Value}
-	} else {
-		rv = &ast.IntLiteral{Token: tok, Value: tok.Value}
+	switch {
+	case strings.Contains(tok.Value, "/"):
+		rv := &ast.RationalLiteral{Token: tok, End: tok.TokenEnd, Value: tok.Value}
+		if _, err := rv.Rat(); err != nil {
+			if errors.Is(err, ast.ErrZeroDenominator) {
+				return nil, newError(tok, "rational literal has zero denominator: %s", tok.Value)
+			}
+			return nil, newError(tok, "invalid rational literal: %s", tok.Value)
+		}
+		return rv, nil
+
+	case !strings.ContainsAny(tok.Value, "xXoObB") && strings.ContainsAny(tok.Value, ".eE"):
+		rv := &ast.FloatLiteral{Token: tok, End: tok.TokenEnd, Value: tok.Value}
+		if _, err := rv.Float64(); err != nil {
+			if errors.Is(err, strconv.ErrRange) {
+				return nil, newError(tok, "float literal out of range: %s", tok.Value)
+			}
+			return nil, newError(tok, "invalid float literal: %s", tok.Value)
+		}
+		return rv, nil
+
+	default:
+		rv := &ast.IntLiteral{Token: tok, End: tok.TokenEnd, Value: tok.Value}
+		if _, err := rv.Int(); err != nil {
+			if errors.Is(err, strconv.ErrRange) {
+				return nil, newError(tok, "integer literal out of range: %s", tok.Value)
+			}
+			return nil, newError(tok, "invalid integer literal: %s", tok.Value)
+		}
+		return rv, nil
 	}
-	return rv, nil
 }
 
 // parseString parses a string token into an AST node.
-func (p *parser) parseString() (ast.Node, error) {
+func (p *Parser) parseString() (ast.Node, error) {
 	// Syntax: STRING
 	tok, err := p.match(token.STRING)
 	if err != nil {
 		return nil, err
 	}
-	rv := &ast.StringLiteral{Token: tok, Value: tok.Value}
+	rv := &ast.StringLiteral{Token: tok, End: tok.TokenEnd, Value: tok.Value}
+	return rv, nil
+}
+
+// parseChar parses a character token into an AST node.
+func (p *Parser) parseChar() (ast.Node, error) {
+	// Syntax: CHAR
+	tok, err := p.match(token.CHAR)
+	if err != nil {
+		return nil, err
+	}
+	value, _ := utf8.DecodeRuneInString(tok.Value)
+	rv := &ast.CharLiteral{Token: tok, End: tok.TokenEnd, Value: value}
+	return rv, nil
+}
+
+// parseKeyword parses a keyword token into an AST node.
+func (p *Parser) parseKeyword() (ast.Node, error) {
+	// Syntax: KEYWORD
+	tok, err := p.match(token.KEYWORD)
+	if err != nil {
+		return nil, err
+	}
+	rv := &ast.KeywordLiteral{Token: tok, End: tok.TokenEnd, Value: tok.Value}
 	return rv, nil
 }
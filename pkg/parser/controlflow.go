@@ -8,7 +8,7 @@ import (
 )
 
 // parseBlock parses a block form into an AST node.
-func (p *parser) parseBlock(tok token.Token) (ast.Node, error) {
+func (p *Parser) parseBlock(tok token.Token) (ast.Node, error) {
 	// Syntax: OPEN "block" <expr>* CLOSE
 	if _, err := p.match(token.OPEN); err != nil {
 		return nil, err
@@ -18,23 +18,24 @@ func (p *parser) parseBlock(tok token.Token) (ast.Node, error) {
 	}
 
 	var (
-		exprs   []ast.Node
-		seenret bool
+		exprs    []ast.Node
+		seenjump bool
 	)
 	for p.peek().TokenType != token.CLOSE {
-		// make sure nothing follows a return statement
-		if seenret {
+		// make sure nothing follows a return, break or continue statement
+		if seenjump {
 			return nil, newError(tok, "unreachable code")
 		}
 
-		expr, err := p.parseWithFlags(allowReturn)
+		expr, err := p.parseWithFlags(allowReturn | allowSplicing)
 		if err != nil {
 			return nil, err
 		}
 
-		// remember if we've seen a return statement
-		if _, ok := expr.(*ast.ReturnStmt); ok {
-			seenret = true
+		// remember if we've seen a return, break or continue statement
+		switch expr.(type) {
+		case *ast.ReturnStmt, *ast.BreakStmt, *ast.ContinueStmt:
+			seenjump = true
 		}
 
 		exprs = append(exprs, expr)
@@ -43,14 +44,14 @@ func (p *parser) parseBlock(tok token.Token) (ast.Node, error) {
 	_, _ = p.match(token.CLOSE) // can't fail
 
 	if len(exprs) <= 0 {
-		return &ast.UnitExpr{Token: tok}, nil
+		return &ast.UnitExpr{Token: tok, End: p.end()}, nil
 	}
 
-	return &ast.BlockExpr{Token: tok, Exprs: exprs}, nil
+	return &ast.BlockExpr{Token: tok, End: p.end(), Exprs: exprs}, nil
 }
 
 // parseCond parses the cond special form into an AST node.
-func (p *parser) parseCond(tok token.Token) (ast.Node, error) {
+func (p *Parser) parseCond(tok token.Token) (ast.Node, error) {
 	// Syntax: OPEN "cond" (OPEN <predicate> <expr> CLOSE)* (OPEN "else" <expr> CLOSE)? CLOSE
 	if _, err := p.match(token.OPEN); err != nil {
 		return nil, err
@@ -104,7 +105,7 @@ func (p *parser) parseCond(tok token.Token) (ast.Node, error) {
 
 	// 3. reduce to `Unit` in case it's all empty
 	if len(cases) <= 0 && elseExpr == nil {
-		return &ast.UnitExpr{Token: tok}, nil
+		return &ast.UnitExpr{Token: tok, End: p.end()}, nil
 	}
 
 	// 4. reduce to `else` if there are no cases
@@ -112,17 +113,17 @@ func (p *parser) parseCond(tok token.Token) (ast.Node, error) {
 		return elseExpr, nil
 	}
 
-	// 5. add the else branch if missing
+	// 5. add the else branch if missing, spanning the whole form
 	if elseExpr == nil {
-		elseExpr = &ast.UnitExpr{Token: tok}
+		elseExpr = &ast.UnitExpr{Token: tok, End: p.end()}
 	}
 
-	rv := &ast.CondExpr{Token: tok, Cases: cases, ElseExpr: elseExpr}
+	rv := &ast.CondExpr{Token: tok, End: p.end(), Cases: cases, ElseExpr: elseExpr}
 	return rv, nil
 }
 
 // parseIf parses an if form into an AST node.
-func (p *parser) parseIf(tok token.Token) (ast.Node, error) {
+func (p *Parser) parseIf(tok token.Token) (ast.Node, error) {
 	// Syntax: OPEN "if" <predicate> <then-expr> <else-expr>? CLOSE
 	if _, err := p.match(token.OPEN); err != nil {
 		return nil, err
@@ -141,8 +142,8 @@ func (p *parser) parseIf(tok token.Token) (ast.Node, error) {
 		return nil, err
 	}
 
-	// 2. add else branch if present and consume final CLOSE
-	var elseExpr ast.Node = &ast.UnitExpr{Token: tok}
+	// 2. add else branch, spanning the whole form if missing, and consume final CLOSE
+	var elseExpr ast.Node
 	if p.peek().TokenType != token.CLOSE {
 		elseExpr, err = p.parseWithFlags(0)
 		if err != nil {
@@ -150,14 +151,17 @@ func (p *parser) parseIf(tok token.Token) (ast.Node, error) {
 		}
 	}
 	_, _ = p.match(token.CLOSE) // cannot fail
+	if elseExpr == nil {
+		elseExpr = &ast.UnitExpr{Token: tok, End: p.end()}
+	}
 
 	// 3. desugar `if` into a `cond`
 	cases := []ast.CondCase{{Predicate: predicate, Expr: thenExpr}}
-	return &ast.CondExpr{Token: tok, Cases: cases, ElseExpr: elseExpr}, nil
+	return &ast.CondExpr{Token: tok, End: p.end(), Cases: cases, ElseExpr: elseExpr}, nil
 }
 
 // parseWhile parses a while form into an AST node.
-func (p *parser) parseWhile(tok token.Token) (ast.Node, error) {
+func (p *Parser) parseWhile(tok token.Token) (ast.Node, error) {
 	// Syntax: OPEN "while" <predicate> <expr> CLOSE
 	if _, err := p.match(token.OPEN); err != nil {
 		return nil, err
@@ -170,12 +174,105 @@ func (p *parser) parseWhile(tok token.Token) (ast.Node, error) {
 	if err != nil {
 		return nil, err
 	}
-	expr, err := p.parseWithFlags(0)
+	expr, err := p.parseLoopBody()
 	if err != nil {
 		return nil, err
 	}
 	if _, err := p.match(token.CLOSE); err != nil {
 		return nil, err
 	}
-	return &ast.WhileExpr{Token: tok, Predicate: predicate, Expr: expr}, nil
+	return &ast.WhileExpr{Token: tok, End: p.end(), Predicate: predicate, Expr: expr}, nil
+}
+
+// parseFor parses a for form into an AST node.
+func (p *Parser) parseFor(tok token.Token) (ast.Node, error) {
+	// Syntax: OPEN "for" OPEN ATOM <from> <to> CLOSE <expr> CLOSE
+	if _, err := p.match(token.OPEN); err != nil {
+		return nil, err
+	}
+	if _, err := p.matchAtomWithName("for"); err != nil {
+		return nil, err
+	}
+
+	// 1. OPEN ATOM <from> <to> CLOSE
+	if _, err := p.match(token.OPEN); err != nil {
+		return nil, err
+	}
+	symbol, err := p.match(token.ATOM)
+	if err != nil {
+		return nil, err
+	}
+	from, err := p.parseWithFlags(0)
+	if err != nil {
+		return nil, err
+	}
+	to, err := p.parseWithFlags(0)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.match(token.CLOSE); err != nil {
+		return nil, err
+	}
+
+	// 2. <expr> CLOSE
+	expr, err := p.parseLoopBody()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.match(token.CLOSE); err != nil {
+		return nil, err
+	}
+
+	rv := &ast.ForExpr{Token: tok, End: p.end(), Symbol: symbol.Value, From: from, To: to, Expr: expr}
+	return rv, nil
+}
+
+// parseForeach parses a foreach form into an AST node.
+func (p *Parser) parseForeach(tok token.Token) (ast.Node, error) {
+	// Syntax: OPEN "foreach" OPEN ATOM <seq> CLOSE <expr> CLOSE
+	if _, err := p.match(token.OPEN); err != nil {
+		return nil, err
+	}
+	if _, err := p.matchAtomWithName("foreach"); err != nil {
+		return nil, err
+	}
+
+	// 1. OPEN ATOM <seq> CLOSE
+	if _, err := p.match(token.OPEN); err != nil {
+		return nil, err
+	}
+	symbol, err := p.match(token.ATOM)
+	if err != nil {
+		return nil, err
+	}
+	seq, err := p.parseWithFlags(0)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.match(token.CLOSE); err != nil {
+		return nil, err
+	}
+
+	// 2. <expr> CLOSE
+	expr, err := p.parseLoopBody()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.match(token.CLOSE); err != nil {
+		return nil, err
+	}
+
+	return &ast.ForeachExpr{Token: tok, End: p.end(), Symbol: symbol.Value, Seq: seq, Expr: expr}, nil
+}
+
+// parseLoopBody parses the body of a loop tracking the depth inside
+// loops so we know when it is legal to accept break! and continue!.
+func (p *Parser) parseLoopBody() (ast.Node, error) {
+	p.loopdepth++
+	expr, err := p.parseWithFlags(0)
+	if err != nil {
+		return nil, err
+	}
+	p.loopdepth--
+	return expr, nil
 }
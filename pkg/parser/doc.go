@@ -5,4 +5,10 @@
 // It turns a sequence of tokens (defined into the token package
 // and generated by the scanner package) into a sequence of abstract
 // syntax tree (AST) nodes (defined into the ast package).
+//
+// Use [Parse] to parse a complete slice of tokens. Otherwise, use [New]
+// with a [TokenReader] and [*Parser.ParseNext] to read the tokens lazily
+// and obtain one top-level form at a time. By default, we parse the
+// latest version of the language, while [ParseWithVersion] and
+// [NewWithVersion] parse the given [langspec.Version].
 package parser
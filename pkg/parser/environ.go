@@ -8,7 +8,7 @@ import (
 )
 
 // parseDeclare parses a declare form into an AST node.
-func (p *parser) parseDeclare(tok token.Token) (ast.Node, error) {
+func (p *Parser) parseDeclare(tok token.Token) (ast.Node, error) {
 	// Syntax: OPEN "declare" <symbol> <lambda> CLOSE
 	if _, err := p.match(token.OPEN); err != nil {
 		return nil, err
@@ -31,11 +31,11 @@ func (p *parser) parseDeclare(tok token.Token) (ast.Node, error) {
 		return nil, err
 	}
 
-	return &ast.DeclareExpr{Token: tok, Symbol: symbol.Value, Expr: lambda}, nil
+	return &ast.DeclareExpr{Token: tok, End: p.end(), Symbol: symbol.Value, Expr: lambda}, nil
 }
 
 // parseDefine parses a define form into an AST node.
-func (p *parser) parseDefine(tok token.Token) (ast.Node, error) {
+func (p *Parser) parseDefine(tok token.Token) (ast.Node, error) {
 	// Syntax: OPEN "define" <symbol> <expr> CLOSE
 	if _, err := p.match(token.OPEN); err != nil {
 		return nil, err
@@ -44,12 +44,12 @@ func (p *parser) parseDefine(tok token.Token) (ast.Node, error) {
 		return nil, err
 	}
 	return p.parseDefineOrSet(tok, func(tok token.Token, symbol string, expr ast.Node) ast.Node {
-		return &ast.DefineExpr{Token: tok, Symbol: symbol, Expr: expr}
+		return &ast.DefineExpr{Token: tok, End: p.end(), Symbol: symbol, Expr: expr}
 	})
 }
 
 // parseSet parses a set form into an AST node.
-func (p *parser) parseSet(tok token.Token) (ast.Node, error) {
+func (p *Parser) parseSet(tok token.Token) (ast.Node, error) {
 	// Syntax: OPEN "set!" <symbol> <expr> CLOSE
 	if _, err := p.match(token.OPEN); err != nil {
 		return nil, err
@@ -58,11 +58,11 @@ func (p *parser) parseSet(tok token.Token) (ast.Node, error) {
 		return nil, err
 	}
 	return p.parseDefineOrSet(tok, func(tok token.Token, symbol string, expr ast.Node) ast.Node {
-		return &ast.SetExpr{Token: tok, Symbol: symbol, Expr: expr}
+		return &ast.SetExpr{Token: tok, End: p.end(), Symbol: symbol, Expr: expr}
 	})
 }
 
-func (p *parser) parseDefineOrSet(
+func (p *Parser) parseDefineOrSet(
 	tok token.Token, build func(token.Token, string, ast.Node) ast.Node) (ast.Node, error) {
 	// Syntax: ... <symbol> <expr> CLOSE
 	symbol, err := p.match(token.ATOM)
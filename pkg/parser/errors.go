@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/langspec"
 	"github.com/bassosimone/buresu/pkg/token"
 )
 
@@ -35,7 +36,12 @@ func (err ErrIncompleteInput) Unwrap() error {
 // Parse processes the provided tokens and returns a slice
 // of AST nodes or an error if parsing fails.
 func Parse(tokens []token.Token) (nodes []ast.Node, err error) {
-	return newParser(tokens).Parse()
+	return New(&sliceReader{tokens}).Parse()
+}
+
+// ParseWithVersion is like [Parse] but parses the given language version.
+func ParseWithVersion(tokens []token.Token, version langspec.Version) ([]ast.Node, error) {
+	return NewWithVersion(&sliceReader{tokens}, version).Parse()
 }
 
 // Error represents a parsing error with position and message.
@@ -44,6 +50,11 @@ type Error struct {
 	Message string
 }
 
+// Span returns the source code span of the token causing the error.
+func (e *Error) Span() token.Span {
+	return e.Tok.Span()
+}
+
 // Error returns the error message with file position details.
 func (e *Error) Error() string {
 	return fmt.Sprintf(
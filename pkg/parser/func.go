@@ -7,7 +7,7 @@ import (
 	"github.com/bassosimone/buresu/pkg/token"
 )
 
-func (p *parser) parseCall(tok token.Token) (ast.Node, error) {
+func (p *Parser) parseCall(tok token.Token) (ast.Node, error) {
 	// Syntax: OPEN <callable> <expr> ... CLOSE
 	var args []ast.Node
 
@@ -16,14 +16,14 @@ func (p *parser) parseCall(tok token.Token) (ast.Node, error) {
 	}
 
 	// <callable>
-	callable, err := p.parseWithFlags(0)
+	callable, err := p.parseWithFlags(allowSplicing)
 	if err != nil {
 		return nil, err
 	}
 
 	// <expr> ... CLOSE
 	for p.peek().TokenType != token.CLOSE {
-		expr, err := p.parseWithFlags(0)
+		expr, err := p.parseWithFlags(allowSplicing)
 		if err != nil {
 			return nil, err
 		}
@@ -33,13 +33,14 @@ func (p *parser) parseCall(tok token.Token) (ast.Node, error) {
 
 	rv := &ast.CallExpr{
 		Token:    tok,
+		End:      p.end(),
 		Callable: callable,
 		Args:     args,
 	}
 	return rv, nil
 }
 
-func (p *parser) parseLambda(tok token.Token) (ast.Node, error) {
+func (p *Parser) parseLambda(tok token.Token) (ast.Node, error) {
 	// Syntax: OPEN "lambda" OPEN <param>* CLOSE [STRING] <expr> CLOSE
 	if _, err := p.match(token.OPEN); err != nil {
 		return nil, err
@@ -80,19 +81,25 @@ func (p *parser) parseLambda(tok token.Token) (ast.Node, error) {
 	// 3. <expr> CLOSE
 	//
 	// Track the depth inside lambdas so we know when it
-	// is legal to accept a return statement.
+	// is legal to accept a return statement. Also, reset the
+	// depth inside loops, since break! and continue! cannot
+	// leave a loop enclosing the lambda.
+	loopdepth := p.loopdepth
 	p.lambdadepth++
+	p.loopdepth = 0
 	expr, err := p.parseWithFlags(allowEllipsis)
 	if err != nil {
 		return nil, err
 	}
 	p.lambdadepth--
+	p.loopdepth = loopdepth
 	if _, err := p.match(token.CLOSE); err != nil {
 		return nil, err
 	}
 
 	rv := &ast.LambdaExpr{
 		Token:  tok,
+		End:    p.end(),
 		Params: params,
 		Docs:   docs,
 		Expr:   expr,
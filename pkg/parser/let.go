@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package parser
+
+import (
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/token"
+)
+
+// parseLet parses a let form into an AST node.
+func (p *Parser) parseLet(tok token.Token) (ast.Node, error) {
+	// Syntax: OPEN "let" <bindings> <expr> CLOSE
+	bindings, expr, err := p.parseLetForm("let", true)
+	if err != nil {
+		return nil, err
+	}
+	return &ast.LetExpr{Token: tok, End: p.end(), Bindings: bindings, Expr: expr}, nil
+}
+
+// parseLetStar parses a let* form into an AST node.
+func (p *Parser) parseLetStar(tok token.Token) (ast.Node, error) {
+	// Syntax: OPEN "let*" <bindings> <expr> CLOSE
+	//
+	// Because each binding lives in its own nested scope, let* allows
+	// to bind the same symbol more than once, like nested lets would.
+	bindings, expr, err := p.parseLetForm("let*", false)
+	if err != nil {
+		return nil, err
+	}
+	return &ast.LetStarExpr{Token: tok, End: p.end(), Bindings: bindings, Expr: expr}, nil
+}
+
+// parseLetrec parses a letrec form into an AST node.
+func (p *Parser) parseLetrec(tok token.Token) (ast.Node, error) {
+	// Syntax: OPEN "letrec" <bindings> <expr> CLOSE
+	bindings, expr, err := p.parseLetForm("letrec", true)
+	if err != nil {
+		return nil, err
+	}
+	return &ast.LetrecExpr{Token: tok, End: p.end(), Bindings: bindings, Expr: expr}, nil
+}
+
+// parseLetForm parses the common structure of the let, let* and letrec
+// forms. When uniq is true, we reject duplicate symbols.
+func (p *Parser) parseLetForm(name string, uniq bool) ([]ast.LetBinding, ast.Node, error) {
+	// Syntax: OPEN <name> OPEN (OPEN <symbol> <expr> CLOSE)* CLOSE <expr> CLOSE
+	if _, err := p.match(token.OPEN); err != nil {
+		return nil, nil, err
+	}
+	if _, err := p.matchAtomWithName(name); err != nil {
+		return nil, nil, err
+	}
+
+	// 1. OPEN (OPEN <symbol> <expr> CLOSE)* CLOSE
+	var (
+		bindings []ast.LetBinding
+		uniqnam  = make(map[string]struct{})
+	)
+	if _, err := p.match(token.OPEN); err != nil {
+		return nil, nil, err
+	}
+	for p.peek().TokenType != token.CLOSE {
+		if _, err := p.match(token.OPEN); err != nil {
+			return nil, nil, err
+		}
+		symbol, err := p.match(token.ATOM)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, found := uniqnam[symbol.Value]; found && uniq {
+			return nil, nil, newError(symbol, "%s binding %q is duplicated", name, symbol.Value)
+		}
+		uniqnam[symbol.Value] = struct{}{}
+		expr, err := p.parseWithFlags(0)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err := p.match(token.CLOSE); err != nil {
+			return nil, nil, err
+		}
+		bindings = append(bindings, ast.LetBinding{Symbol: symbol.Value, Expr: expr})
+	}
+	_, _ = p.match(token.CLOSE) // cannot fail
+
+	// 2. <expr> CLOSE
+	expr, err := p.parseWithFlags(0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := p.match(token.CLOSE); err != nil {
+		return nil, nil, err
+	}
+	return bindings, expr, nil
+}
@@ -3,54 +3,152 @@
 package parser
 
 import (
+	"errors"
+	"io"
+
 	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/langspec"
 	"github.com/bassosimone/buresu/pkg/token"
 )
 
-// parser is a structure that holds the tokens to be parsed and
-// the current position in the token list.
-type parser struct {
+// TokenReader reads tokens one at a time (e.g., a scanner.Scanner).
+type TokenReader interface {
+	// Next returns the next token or an error. At the end of
+	// the input, it returns the EOF token on each call.
+	Next() (token.Token, error)
+}
+
+// Parser parses top-level forms one at a time, reading the tokens
+// lazily, which allows to process huge or interactive inputs.
+//
+// Use [New] to construct.
+type Parser struct {
 	// current is the current position in the tokens list.
 	current int
 
+	// err is the error we got reading the tokens, if any.
+	err error
+
 	// lambdadepth is the current depth of lambda expressions: if zero
 	// we're at top-level, if one we're inside a lambda, if two we're
 	// inside a lambda inside a lambda, and so on.
 	lambdadepth int
 
-	// tokens contains the tokens to be parsed.
+	// loopdepth is the current depth of loops inside the
+	// innermost lambda, which we use to reject break! and continue!
+	// statements that are not inside a loop.
+	loopdepth int
+
+	// quasiquotedepth is the current depth of quasiquote expressions, which
+	// we decrement when parsing unquotes, such that we can reject unquotes
+	// that are not inside a quasiquote.
+	quasiquotedepth int
+
+	// reader is where we read the tokens from.
+	reader TokenReader
+
+	// tokens contains the tokens we have read but not yet discarded.
 	tokens []token.Token
+
+	// version is the language version we're parsing.
+	version langspec.Version
+}
+
+// New creates a new [*Parser] reading tokens from the given [TokenReader]
+// and parsing the [langspec.Latest] version of the language.
+func New(reader TokenReader) *Parser {
+	return NewWithVersion(reader, langspec.Latest)
 }
 
-// newParser creates a new parser instance with the provided tokens.
-func newParser(tokens []token.Token) *parser {
-	return &parser{tokens: tokens, current: 0, lambdadepth: 0}
+// NewWithVersion is like [New] but parses the given language version.
+func NewWithVersion(reader TokenReader, version langspec.Version) *Parser {
+	return &Parser{reader: reader, current: 0, lambdadepth: 0, version: version}
 }
 
-// Parse processes the tokens and returns a slice of AST nodes.
-func (p *parser) Parse() ([]ast.Node, error) {
+// Parse parses the remaining tokens and returns a slice of AST nodes.
+func (p *Parser) Parse() ([]ast.Node, error) {
 	var nodes []ast.Node
-	for p.peek().TokenType != token.EOF {
-		node, err := p.parseWithFlags(allowInclude) // only at top-level
+	for {
+		node, err := p.ParseNext()
+		if errors.Is(err, io.EOF) {
+			return nodes, nil
+		}
 		if err != nil {
 			return nil, err
 		}
 		nodes = append(nodes, node)
 	}
-	return nodes, nil
+}
+
+// ParseNext parses and returns the next top-level form, reading only
+// the tokens we need to parse it, or returns [io.EOF] at the end of the
+// input. When reading the tokens fails, we return the read error.
+//
+// Note that ParseNext returns an [ErrIncompleteInput] error when the
+// input ends in the middle of a form, and that, after any error, the
+// parser state is undefined and the caller should not continue parsing.
+func (p *Parser) ParseNext() (ast.Node, error) {
+	// discard the tokens of the previous forms except the last
+	// one, which we need to compute the end of the nodes
+	if p.current > 1 {
+		p.tokens = append(p.tokens[:0], p.tokens[p.current-1:]...)
+		p.current = 1
+	}
+	if p.peek().TokenType == token.EOF {
+		if p.err != nil {
+			return nil, p.err
+		}
+		return nil, io.EOF
+	}
+	node, err := p.parseWithFlags(allowInclude) // only at top-level
+	if err != nil && p.err != nil {
+		return nil, p.err // the parse error is a consequence
+	}
+	return node, err
+}
+
+// fill reads tokens until the given position is in the tokens
+// list, unless we have already read the EOF token. When reading
+// fails, we remember the error and pretend we reached the EOF.
+func (p *Parser) fill(index int) {
+	for len(p.tokens) <= index {
+		if n := len(p.tokens); n > 0 && p.tokens[n-1].TokenType == token.EOF {
+			return
+		}
+		tok, err := p.reader.Next()
+		if err != nil {
+			var pos token.Position
+			if n := len(p.tokens); n > 0 {
+				pos = p.tokens[n-1].TokenEnd
+			}
+			p.err = err
+			tok = token.Token{TokenType: token.EOF, TokenPos: pos, TokenEnd: pos}
+		}
+		p.tokens = append(p.tokens, tok)
+	}
 }
 
 // advance moves the current position to the next token.
 //
 // This method is safe to call even if the current token is the last one.
-func (p *parser) advance() {
-	if p.current < len(p.tokens)-1 {
+func (p *Parser) advance() {
+	if p.peek().TokenType != token.EOF {
 		p.current++
 	}
 }
 
+// end returns the position right after the last consumed token, which
+// is the end of the node we have just finished parsing.
+func (p *Parser) end() token.Position {
+	if p.current > 0 {
+		return p.tokens[p.current-1].TokenEnd
+	}
+	return token.Position{}
+}
+
 // peek returns the current token being processed.
-func (p *parser) peek() token.Token {
+func (p *Parser) peek() token.Token {
+	p.fill(p.current)
 	if p.current < len(p.tokens) {
 		return p.tokens[p.current]
 	}
@@ -58,15 +156,33 @@ func (p *parser) peek() token.Token {
 }
 
 // peekNext returns the next token to be processed.
-func (p *parser) peekNext() token.Token {
+func (p *Parser) peekNext() token.Token {
+	p.fill(p.current + 1)
 	if p.current+1 < len(p.tokens) {
 		return p.tokens[p.current+1]
 	}
 	return token.Token{}
 }
 
+// sliceReader is a [TokenReader] reading from a slice of tokens.
+type sliceReader struct {
+	tokens []token.Token
+}
+
+// Next implements [TokenReader].
+func (r *sliceReader) Next() (token.Token, error) {
+	if len(r.tokens) <= 0 {
+		return token.Token{TokenType: token.EOF}, nil
+	}
+	tok := r.tokens[0]
+	if tok.TokenType != token.EOF {
+		r.tokens = r.tokens[1:]
+	}
+	return tok, nil
+}
+
 // match consumes the current token if it matches the expected type.
-func (p *parser) match(tt token.TokenType) (token.Token, error) {
+func (p *Parser) match(tt token.TokenType) (token.Token, error) {
 	tok := p.peek()
 	if tok.TokenType != tt {
 		err := newError(tok, "expected token %s, found %s", tt, tok.TokenType)
@@ -80,12 +196,12 @@ func (p *parser) match(tt token.TokenType) (token.Token, error) {
 }
 
 // check returns true if the current token matches the expected type.
-func (p *parser) check(tt token.TokenType) bool {
+func (p *Parser) check(tt token.TokenType) bool {
 	return p.peek().TokenType == tt
 }
 
 // matchAtomWithName consumes the current token if it matches the expected type and name.
-func (p *parser) matchAtomWithName(name string) (token.Token, error) {
+func (p *Parser) matchAtomWithName(name string) (token.Token, error) {
 	tok := p.peek()
 	if tok.TokenType != token.ATOM || tok.Value != name {
 		err := newError(tok, "expected atom with name %s, found %s", name, tok.Value)
@@ -102,11 +218,16 @@ const (
 	// allowReturn allows parseWithFlags to parse statements
 	allowReturn = 1 << iota
 
-	// allowInclude allows parseWithFlags to parse include
+	// allowInclude allows parseWithFlags to parse include, import
+	// and module, which are only allowed at the toplevel
 	allowInclude
 
 	// allowEllipsis allows parsing `...`
 	allowEllipsis
+
+	// allowSplicing allows parsing `,@`, which is only allowed
+	// among the elements of a list, where we splice the values
+	allowSplicing
 )
 
 // parseWithFlags parses atoms, numbers, strings, expressions, and
@@ -115,7 +236,7 @@ const (
 // allowed inside a block expression, or include, which is only allowed
 // at the toplevel. The flags parameter controls when it is legal to
 // accept these context-dependent constructs.
-func (p *parser) parseWithFlags(flags int) (ast.Node, error) {
+func (p *Parser) parseWithFlags(flags int) (ast.Node, error) {
 	switch tp := p.peek(); tp.TokenType {
 	case token.ATOM:
 		return p.parseSymbol()
@@ -123,10 +244,20 @@ func (p *parser) parseWithFlags(flags int) (ast.Node, error) {
 		return p.parseNumber()
 	case token.STRING:
 		return p.parseString()
+	case token.CHAR:
+		return p.parseChar()
+	case token.KEYWORD:
+		return p.parseKeyword()
 	case token.OPEN:
 		return p.parseForm(flags)
 	case token.ELLIPSIS:
 		return p.parseEllipsis(flags)
+	case token.QUOTE:
+		return p.parseQuoteShorthand()
+	case token.QUASIQUOTE:
+		return p.parseQuasiquote()
+	case token.UNQUOTE, token.UNQUOTE_SPLICING:
+		return p.parseUnquote(flags)
 	default:
 		err := newError(tp, "unexpected token %s", tp.TokenType)
 		if tp.TokenType == token.EOF {
@@ -137,13 +268,13 @@ func (p *parser) parseWithFlags(flags int) (ast.Node, error) {
 }
 
 // parseForm parses a form honoring the given flags.
-func (p *parser) parseForm(flags int) (ast.Node, error) {
+func (p *Parser) parseForm(flags int) (ast.Node, error) {
 	tok := p.peek()
 
 	if p.peekNext().TokenType == token.CLOSE {
 		p.advance() // consume OPEN
 		p.advance() // consume CLOSE
-		return &ast.UnitExpr{Token: tok}, nil
+		return &ast.UnitExpr{Token: tok, End: p.end()}, nil
 	}
 
 	form := p.peekNext()
@@ -154,19 +285,44 @@ func (p *parser) parseForm(flags int) (ast.Node, error) {
 			"declare":  p.parseDeclare,
 			"define":   p.parseDefine,
 			"if":       p.parseIf,
+			"import":   p.parseStmtNotAllowed("import", p.parseImport),
 			"include!": p.parseStmtNotAllowed("include!", p.parseInclude),
 			"lambda":   p.parseLambda,
+			"let":      p.parseLet,
+			"let*":     p.parseLetStar,
+			"letrec":   p.parseLetrec,
+			"module":   p.parseStmtNotAllowed("module", p.parseModule),
 			"quote":    p.parseQuote,
-			"return!":  p.parseStmtNotAllowed("return!", p.parseReturn),
+			"return!":  p.parseBlockStmtNotAllowed("return!", p.parseReturn),
 			"set!":     p.parseSet,
 			"while":    p.parseWhile,
 		}
 		if flags&allowInclude != 0 {
+			specialForms["import"] = p.parseImport
 			specialForms["include!"] = p.parseInclude
+			specialForms["module"] = p.parseModule
 		}
 		if flags&allowReturn != 0 {
 			specialForms["return!"] = p.parseReturn
 		}
+		if p.version >= langspec.Version4 {
+			specialForms["yield!"] = p.parseBlockStmtNotAllowed("yield!", p.parseYield)
+			if flags&allowReturn != 0 {
+				specialForms["yield!"] = p.parseYield
+			}
+		}
+		if p.version >= langspec.Version3 {
+			specialForms["for"] = p.parseFor
+			specialForms["foreach"] = p.parseForeach
+		}
+		if p.version >= langspec.Version2 {
+			specialForms["break!"] = p.parseBlockStmtNotAllowed("break!", p.parseBreak)
+			specialForms["continue!"] = p.parseBlockStmtNotAllowed("continue!", p.parseContinue)
+			if flags&allowReturn != 0 {
+				specialForms["break!"] = p.parseBreak
+				specialForms["continue!"] = p.parseContinue
+			}
+		}
 		if parseFunc, found := specialForms[form.Value]; found {
 			return parseFunc(tok)
 		}
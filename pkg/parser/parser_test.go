@@ -3,11 +3,17 @@
 package parser_test
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"strings"
 	"testing"
 
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/langspec"
 	"github.com/bassosimone/buresu/pkg/parser"
 	"github.com/bassosimone/buresu/pkg/scanner"
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestParser(t *testing.T) {
@@ -249,6 +255,64 @@ func TestParser(t *testing.T) {
 			expectedError:  "<stdin>:1:18: parser: expected token CLOSE, found EOF",
 		},
 
+		// import tests
+		{
+			input:          "(import \"lib/math.brs\" as math)",
+			expectedOutput: "(import \"lib/math.brs\" as math)",
+			shouldFail:     false,
+			expectedError:  "",
+		},
+		{
+			input:          "(block (import \"lib/math.brs\" as math))",
+			expectedOutput: "",
+			shouldFail:     true,
+			expectedError:  "<stdin>:1:8: parser: import statement not allowed in this context",
+		},
+		{
+			input:          "(import \"lib/math.brs\" math)",
+			expectedOutput: "",
+			shouldFail:     true,
+			expectedError:  "<stdin>:1:24: parser: expected atom with name as, found math",
+		},
+		{
+			input:          "(import \"lib/math.brs\" as m/x)",
+			expectedOutput: "",
+			shouldFail:     true,
+			expectedError:  "<stdin>:1:27: parser: import alias \"m/x\" must not contain '/'",
+		},
+
+		// module tests
+		{
+			input:          "(module math (export square cube))",
+			expectedOutput: "(module math (export square cube))",
+			shouldFail:     false,
+			expectedError:  "",
+		},
+		{
+			input:          "(module math (export))",
+			expectedOutput: "(module math (export ))",
+			shouldFail:     false,
+			expectedError:  "",
+		},
+		{
+			input:          "(block (module math (export square)))",
+			expectedOutput: "",
+			shouldFail:     true,
+			expectedError:  "<stdin>:1:8: parser: module statement not allowed in this context",
+		},
+		{
+			input:          "(module math (export square square))",
+			expectedOutput: "",
+			shouldFail:     true,
+			expectedError:  "<stdin>:1:29: parser: module export \"square\" is duplicated",
+		},
+		{
+			input:          "(module math (square))",
+			expectedOutput: "",
+			shouldFail:     true,
+			expectedError:  "<stdin>:1:15: parser: expected atom with name export, found square",
+		},
+
 		// lambda tests
 		{
 			input:          "(lambda x)",
@@ -303,6 +367,64 @@ func TestParser(t *testing.T) {
 			shouldFail:     false,
 		},
 
+		// let tests
+		{
+			input:          "(let ((x 1) (y 2)) (+ x y))",
+			expectedOutput: "(let ((x 1) (y 2)) (+ x y))",
+			shouldFail:     false,
+		},
+		{
+			input:          "(let () 42)",
+			expectedOutput: "(let () 42)",
+			shouldFail:     false,
+		},
+		{
+			input:          "(let ((x 1) (x 2)) x)",
+			expectedOutput: "",
+			shouldFail:     true,
+			expectedError:  "<stdin>:1:14: parser: let binding \"x\" is duplicated",
+		},
+		{
+			input:          "(let (x 1) x)",
+			expectedOutput: "",
+			shouldFail:     true,
+			expectedError:  "<stdin>:1:7: parser: expected token OPEN, found ATOM",
+		},
+		{
+			input:          "(let ((1 x)) x)",
+			expectedOutput: "",
+			shouldFail:     true,
+			expectedError:  "<stdin>:1:8: parser: expected token ATOM, found NUMBER",
+		},
+		{
+			input:          "(let ((x 1))",
+			expectedOutput: "",
+			shouldFail:     true,
+			expectedError:  "<stdin>:1:12: parser: unexpected token EOF",
+		},
+		{
+			input:          "(let ((x 1)) x",
+			expectedOutput: "",
+			shouldFail:     true,
+			expectedError:  "<stdin>:1:14: parser: expected token CLOSE, found EOF",
+		},
+		{
+			input:          "(let* ((x 1) (x (+ x 1))) x)",
+			expectedOutput: "(let* ((x 1) (x (+ x 1))) x)",
+			shouldFail:     false,
+		},
+		{
+			input:          "(letrec ((f (lambda () (g))) (g (lambda () (f)))) (f))",
+			expectedOutput: "(letrec ((f (lambda () \"\" (g ))) (g (lambda () \"\" (f )))) (f ))",
+			shouldFail:     false,
+		},
+		{
+			input:          "(letrec ((f 1) (f 2)) f)",
+			expectedOutput: "",
+			shouldFail:     true,
+			expectedError:  "<stdin>:1:17: parser: letrec binding \"f\" is duplicated",
+		},
+
 		// quote tests
 		{
 			input:          "(quote (1 2 3))",
@@ -321,6 +443,60 @@ func TestParser(t *testing.T) {
 			shouldFail:     true,
 			expectedError:  "<stdin>:1:14: parser: expected token CLOSE, found EOF",
 		},
+		{
+			input:          "'(1 2 3)",
+			expectedOutput: "'(1 2 3)",
+			shouldFail:     false,
+		},
+		{
+			input:          "'",
+			expectedOutput: "",
+			shouldFail:     true,
+			expectedError:  "<stdin>:1:1: parser: unexpected token EOF",
+		},
+
+		// char and keyword tests
+		{
+			input:          `(f #\a #\space #\u{1F600} :name)`,
+			expectedOutput: `(f #\a #\space #\u{1F600} :name)`,
+			shouldFail:     false,
+		},
+
+		// quasiquote tests
+		{
+			input:          "`(a ,b ,@c '(d ,e))",
+			expectedOutput: "`(a ,b ,@c '(d ,e))",
+			shouldFail:     false,
+		},
+		{
+			input:          "`(a `(b ,(c ,d)))",
+			expectedOutput: "`(a `(b ,(c ,d)))",
+			shouldFail:     false,
+		},
+		{
+			input:          "(a ,b)",
+			expectedOutput: "",
+			shouldFail:     true,
+			expectedError:  "<stdin>:1:4: parser: , outside of quasiquote",
+		},
+		{
+			input:          "`(a ,,b)",
+			expectedOutput: "",
+			shouldFail:     true,
+			expectedError:  "<stdin>:1:6: parser: , outside of quasiquote",
+		},
+		{
+			input:          "`,@a",
+			expectedOutput: "",
+			shouldFail:     true,
+			expectedError:  "<stdin>:1:2: parser: ,@ outside of a list",
+		},
+		{
+			input:          "`(define x ,@a)",
+			expectedOutput: "",
+			shouldFail:     true,
+			expectedError:  "<stdin>:1:12: parser: ,@ outside of a list",
+		},
 
 		// return tests
 		{
@@ -339,13 +515,13 @@ func TestParser(t *testing.T) {
 			input:          "(lambda () (return! 42))",
 			expectedOutput: "",
 			shouldFail:     true,
-			expectedError:  "<stdin>:1:12: parser: return! statement not allowed in this context",
+			expectedError:  "<stdin>:1:12: parser: return! statement not allowed in this context: it must appear directly inside a block",
 		},
 		{
 			input:          "(lambda () (lambda () (return! 42)))",
 			expectedOutput: "(lambda () \"\" (lambda () \"\" (return! 42)))",
 			shouldFail:     true,
-			expectedError:  "<stdin>:1:23: parser: return! statement not allowed in this context",
+			expectedError:  "<stdin>:1:23: parser: return! statement not allowed in this context: it must appear directly inside a block",
 		},
 		{
 			input:          "(lambda () (return!",
@@ -394,6 +570,55 @@ func TestParser(t *testing.T) {
 			shouldFail:     true,
 			expectedError:  "<stdin>:1:14: parser: expected token CLOSE, found EOF",
 		},
+
+		// yield tests
+		{
+			input:          "(lambda () (block (yield! 1) (yield! 2)))",
+			expectedOutput: "(lambda () \"\" (block (yield! 1) (yield! 2)))",
+			shouldFail:     false,
+		},
+		{
+			input:          "(block (yield! 42))",
+			expectedOutput: "",
+			shouldFail:     true,
+			expectedError:  "<stdin>:1:8: parser: yield! outside of lambda",
+		},
+		{
+			input:          "(lambda () (yield! 42))",
+			expectedOutput: "",
+			shouldFail:     true,
+			expectedError:  "<stdin>:1:12: parser: yield! statement not allowed in this context: it must appear directly inside a block",
+		},
+		{
+			input:          "(lambda () (block (yield! 42",
+			expectedOutput: "",
+			shouldFail:     true,
+			expectedError:  "<stdin>:1:28: parser: expected token CLOSE, found EOF",
+		},
+
+		// for and foreach tests
+		{
+			input:          "(for (i 0 10) i)",
+			expectedOutput: "(for (i 0 10) i)",
+			shouldFail:     false,
+		},
+		{
+			input:          "(foreach (x \"abc\") (block (continue!)))",
+			expectedOutput: "(foreach (x \"abc\") (block (continue!)))",
+			shouldFail:     false,
+		},
+		{
+			input:          "(for (i 0) i)",
+			expectedOutput: "",
+			shouldFail:     true,
+			expectedError:  "<stdin>:1:10: parser: unexpected token CLOSE",
+		},
+		{
+			input:          "(foreach (1 x) x)",
+			expectedOutput: "",
+			shouldFail:     true,
+			expectedError:  "<stdin>:1:11: parser: expected token ATOM, found NUMBER",
+		},
 	}
 
 	for _, test := range tests {
@@ -429,3 +654,355 @@ func TestParser(t *testing.T) {
 		})
 	}
 }
+
+func TestParserSpans(t *testing.T) {
+	input := "(define sq (lambda (x)\n  (* x x)))\n(if (< 1 2) \"é\")"
+	tokens, err := scanner.Scan("<stdin>", strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes, err := parser.Parse(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	define := nodes[0].(*ast.DefineExpr)
+	lambda := define.Expr.(*ast.LambdaExpr)
+	cond := nodes[1].(*ast.CondExpr)
+
+	expected := []string{
+		"<stdin>:1:1-2:12 [0,34)",   // define
+		"<stdin>:1:12-2:11 [11,33)", // lambda
+		"<stdin>:2:3-2:10 [25,32)",  // (* x x)
+		"<stdin>:2:6-2:7 [28,29)",   // x
+		"<stdin>:3:1-3:17 [35,52)",  // if
+		"<stdin>:3:5-3:12 [39,46)",  // (< 1 2)
+		"<stdin>:3:13-3:16 [47,51)", // "é"
+		"<stdin>:3:1-3:17 [35,52)",  // implicit else
+	}
+	var got []string
+	for _, node := range []ast.Node{
+		define,
+		lambda,
+		lambda.Expr,
+		lambda.Expr.(*ast.CallExpr).Args[0],
+		cond,
+		cond.Cases[0].Predicate,
+		cond.Cases[0].Expr,
+		cond.ElseExpr,
+	} {
+		span := ast.NodeSpan(node)
+		got = append(got, fmt.Sprintf("%s [%d,%d)", span, span.Start.Offset, span.End.Offset))
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestParseNumbers(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect string
+	}{
+		// valid literals
+		{"42", "*ast.IntLiteral"},
+		{"0x7fff_ffff_ffff_ffff", "*ast.IntLiteral"},
+		{"-0x8000_0000_0000_0000", "*ast.IntLiteral"},
+		{"0xe", "*ast.IntLiteral"},
+		{"1e9", "*ast.FloatLiteral"},
+		{".5", "*ast.FloatLiteral"},
+		{"1_000.000_1", "*ast.FloatLiteral"},
+		{"1/3", "*ast.RationalLiteral"},
+		{"-100000000000000000000000/3", "*ast.RationalLiteral"},
+
+		// invalid literals
+		{"9223372036854775808", "<stdin>:1:1: parser: integer literal out of range: 9223372036854775808"},
+		{"0x1_0000_0000_0000_0000", "<stdin>:1:1: parser: integer literal out of range: 0x1_0000_0000_0000_0000"},
+		{"1e400", "<stdin>:1:1: parser: float literal out of range: 1e400"},
+		{"1/0", "<stdin>:1:1: parser: rational literal has zero denominator: 1/0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tokens, err := scanner.Scan("<stdin>", strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			nodes, err := parser.Parse(tokens)
+			var got string
+			switch {
+			case err != nil:
+				got = err.Error()
+			case len(nodes) != 1:
+				t.Fatalf("expected a single node, got %d", len(nodes))
+			default:
+				got = fmt.Sprintf("%T", nodes[0])
+			}
+			if got != tt.expect {
+				t.Fatalf("expected %q, got %q", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestParseStringsRoundTrip(t *testing.T) {
+	inputs := []string{
+		`"a\x41\u{1F600}\n"`,
+		`r#"C:\path "quoted""#`,
+		"(lambda (x)\n  \"\"\"\n  Identity function.\n\n  :: (Callable (Int) Int)\n  \"\"\"\n  x)",
+	}
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			parse := func(source string) []ast.Node {
+				tokens, err := scanner.Scan("<stdin>", strings.NewReader(source))
+				if err != nil {
+					t.Fatal(err)
+				}
+				nodes, err := parser.Parse(tokens)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return nodes
+			}
+			// make sure that we obtain the same value after a round trip
+			first := parse(input)
+			second := parse(first[0].String())
+			if diff := cmp.Diff(first[0].String(), second[0].String()); diff != "" {
+				t.Fatal(diff)
+			}
+			value := func(node ast.Node) string {
+				if lambda, ok := node.(*ast.LambdaExpr); ok {
+					return lambda.Docs
+				}
+				return node.(*ast.StringLiteral).Value
+			}
+			if diff := cmp.Diff(value(first[0]), value(second[0])); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+// lineReader is an [io.Reader] returning a line on each read, which
+// allows us to check that the parser only reads the tokens it needs.
+type lineReader struct {
+	lines []string
+}
+
+// Read implements [io.Reader].
+func (r *lineReader) Read(data []byte) (int, error) {
+	if len(r.lines) <= 0 {
+		return 0, io.EOF
+	}
+	count := copy(data, r.lines[0])
+	r.lines = r.lines[1:]
+	return count, nil
+}
+
+func TestParseNext(t *testing.T) {
+	t.Run("we parse one top-level form at a time", func(t *testing.T) {
+		reader := &lineReader{lines: []string{"(define x 1)\n", "(+ x\n", "2) x\n"}}
+		p := parser.New(scanner.New("<stdin>", reader))
+
+		node, err := p.ParseNext()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if node.String() != "(define x 1)" {
+			t.Fatalf("unexpected node: %s", node.String())
+		}
+		if len(reader.lines) != 2 {
+			t.Fatalf("expected 2 unread lines, got %d", len(reader.lines))
+		}
+
+		var got []string
+		for {
+			node, err := p.ParseNext()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, node.String())
+		}
+		if diff := cmp.Diff([]string{"(+ x 2)", "x"}, got); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+
+	t.Run("we report incomplete input", func(t *testing.T) {
+		p := parser.New(scanner.New("<stdin>", strings.NewReader("(+ 1")))
+		if _, err := p.ParseNext(); !parser.IsErrIncompleteInput(err) {
+			t.Fatalf("expected incomplete input error, got %v", err)
+		}
+	})
+
+	t.Run("we report scanner errors", func(t *testing.T) {
+		p := parser.New(scanner.New("<stdin>", strings.NewReader("x \"a")))
+		if _, err := p.ParseNext(); err != nil {
+			t.Fatal(err)
+		}
+		_, err := p.ParseNext()
+		var serr *scanner.Error
+		if !errors.As(err, &serr) {
+			t.Fatalf("expected *scanner.Error, got %v", err)
+		}
+	})
+}
+
+func TestParseWithRecovery(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+		errors   []string
+	}{
+		{
+			name:     "without errors",
+			input:    "(define x 1) x",
+			expected: []string{"(define x 1)", "x"},
+			errors:   nil,
+		},
+		{
+			name:     "with errors in multiple forms",
+			input:    "(define x (if)) (define y 1) (lambda (z z) z) y",
+			expected: []string{"<error>", "(define y 1)", "<error>", "y"},
+			errors: []string{
+				"<stdin>:1:14: parser: unexpected token CLOSE",
+				"<stdin>:1:30: parser: lambda parameter \"z\" is duplicated",
+			},
+		},
+		{
+			name:     "with a missing closing parenthesis",
+			input:    "(define x (+ 1 2)\n(define y 1)",
+			expected: []string{"<error>", "(define y 1)"},
+			errors:   []string{"<stdin>:2:1: parser: expected token CLOSE, found OPEN"},
+		},
+		{
+			name:     "with a stray closing parenthesis",
+			input:    "(define x 1)) x",
+			expected: []string{"(define x 1)", "<error>", "x"},
+			errors:   []string{"<stdin>:1:13: parser: unexpected token CLOSE"},
+		},
+		{
+			name:     "with an error inside a quote shorthand",
+			input:    "',@x y",
+			expected: []string{"<error>", "y"},
+			errors:   []string{"<stdin>:1:2: parser: ,@ outside of quasiquote"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := scanner.Scan("<stdin>", strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			nodes, errs := parser.ParseWithRecovery(tokens)
+
+			var got []string
+			for _, node := range nodes {
+				got = append(got, node.String())
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Fatal(diff)
+			}
+
+			var messages []string
+			for _, err := range errs {
+				messages = append(messages, err.Error())
+			}
+			if diff := cmp.Diff(tt.errors, messages); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestParseWithVersion(t *testing.T) {
+	const input = "(while true (block (break!)))"
+	tokens, err := scanner.Scan("<stdin>", strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("version 1 does not know about break!", func(t *testing.T) {
+		nodes, err := parser.ParseWithVersion(tokens, langspec.Version1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body := nodes[0].(*ast.WhileExpr).Expr.(*ast.BlockExpr)
+		if _, ok := body.Exprs[0].(*ast.CallExpr); !ok {
+			t.Fatalf("expected a call, got %T", body.Exprs[0])
+		}
+	})
+
+	t.Run("version 2 parses break!", func(t *testing.T) {
+		nodes, err := parser.ParseWithVersion(tokens, langspec.Version2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body := nodes[0].(*ast.WhileExpr).Expr.(*ast.BlockExpr)
+		if _, ok := body.Exprs[0].(*ast.BreakStmt); !ok {
+			t.Fatalf("expected a break statement, got %T", body.Exprs[0])
+		}
+	})
+
+	t.Run("version 2 does not know about for", func(t *testing.T) {
+		tokens, err := scanner.Scan("<stdin>", strings.NewReader("(for (i 0 10) i)"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodes, err := parser.ParseWithVersion(tokens, langspec.Version2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := nodes[0].(*ast.CallExpr); !ok {
+			t.Fatalf("expected a call, got %T", nodes[0])
+		}
+	})
+
+	t.Run("version 3 does not know about yield!", func(t *testing.T) {
+		tokens, err := scanner.Scan("<stdin>", strings.NewReader("(lambda () (block (yield! 1)))"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodes, err := parser.ParseWithVersion(tokens, langspec.Version3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body := nodes[0].(*ast.LambdaExpr).Expr.(*ast.BlockExpr)
+		if _, ok := body.Exprs[0].(*ast.CallExpr); !ok {
+			t.Fatalf("expected a call, got %T", body.Exprs[0])
+		}
+	})
+
+	t.Run("version 2 reports loop control outside of while", func(t *testing.T) {
+		tokens, err := scanner.Scan("<stdin>", strings.NewReader("(block (break!))"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = parser.ParseWithVersion(tokens, langspec.Version2)
+		if err == nil || err.Error() != "<stdin>:1:8: parser: break! outside of while" {
+			t.Fatal("unexpected error", err)
+		}
+	})
+
+	t.Run("recovery resets the loop depth", func(t *testing.T) {
+		tokens, err := scanner.Scan("<stdin>", strings.NewReader("(while true (block (f 1)\n(block (break!))"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, errs := parser.ParseWithRecovery(tokens)
+		var got []string
+		for _, err := range errs {
+			got = append(got, err.Error())
+		}
+		expect := []string{
+			"<stdin>:2:16: parser: unexpected token EOF",
+			"<stdin>:2:8: parser: break! outside of loop",
+		}
+		if diff := cmp.Diff(expect, got); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+}
@@ -8,7 +8,7 @@ import (
 )
 
 // parseQuote parses the quote special form into an AST node.
-func (p *parser) parseQuote(tok token.Token) (ast.Node, error) {
+func (p *Parser) parseQuote(tok token.Token) (ast.Node, error) {
 	// Syntax: OPEN "quote" <expr> CLOSE
 	if _, err := p.match(token.OPEN); err != nil {
 		return nil, err
@@ -24,5 +24,57 @@ func (p *parser) parseQuote(tok token.Token) (ast.Node, error) {
 	if _, err := p.match(token.CLOSE); err != nil {
 		return nil, err
 	}
-	return &ast.QuoteExpr{Token: tok, Expr: expr}, nil
+	return &ast.QuoteExpr{Token: tok, End: p.end(), Expr: expr}, nil
+}
+
+// parseQuoteShorthand parses the `'x` shorthand for `(quote x)`.
+func (p *Parser) parseQuoteShorthand() (ast.Node, error) {
+	// Syntax: QUOTE <expr>
+	tok, err := p.match(token.QUOTE)
+	if err != nil {
+		return nil, err
+	}
+	expr, err := p.parseWithFlags(0)
+	if err != nil {
+		return nil, err
+	}
+	return &ast.QuoteExpr{Token: tok, End: p.end(), Expr: expr}, nil
+}
+
+// parseQuasiquote parses a quasiquote into an AST node.
+func (p *Parser) parseQuasiquote() (ast.Node, error) {
+	// Syntax: QUASIQUOTE <expr>
+	tok, err := p.match(token.QUASIQUOTE)
+	if err != nil {
+		return nil, err
+	}
+	p.quasiquotedepth++
+	defer func() { p.quasiquotedepth-- }()
+	expr, err := p.parseWithFlags(0)
+	if err != nil {
+		return nil, err
+	}
+	return &ast.QuasiquoteExpr{Token: tok, End: p.end(), Expr: expr}, nil
+}
+
+// parseUnquote parses an unquote or an unquote-splicing into an AST node.
+func (p *Parser) parseUnquote(flags int) (ast.Node, error) {
+	// Syntax: (UNQUOTE | UNQUOTE_SPLICING) <expr>
+	tok := p.peek()
+	if p.quasiquotedepth <= 0 {
+		return nil, newError(tok, "%s outside of quasiquote", tok.Value)
+	}
+	splicing := tok.TokenType == token.UNQUOTE_SPLICING
+	if splicing && flags&allowSplicing == 0 {
+		return nil, newError(tok, "%s outside of a list", tok.Value)
+	}
+	p.advance()
+
+	p.quasiquotedepth--
+	defer func() { p.quasiquotedepth++ }()
+	expr, err := p.parseWithFlags(0)
+	if err != nil {
+		return nil, err
+	}
+	return &ast.UnquoteExpr{Token: tok, End: p.end(), Splicing: splicing, Expr: expr}, nil
 }
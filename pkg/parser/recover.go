@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package parser
+
+import (
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/token"
+)
+
+// ParseWithRecovery is like [Parse] but does not stop at the first error,
+// which is useful to report all the errors of a file (e.g., in an editor).
+//
+// When a top-level form contains an error, we skip its tokens, resynchronizing
+// on balanced parentheses, and continue parsing the following forms. We return
+// the partial AST, where an [*ast.ErrorExpr] replaces each form we could not
+// parse, along with the errors in source order, which is empty on success.
+func ParseWithRecovery(tokens []token.Token) ([]ast.Node, []error) {
+	return New(&sliceReader{tokens}).parseWithRecovery()
+}
+
+// parseWithRecovery implements [ParseWithRecovery].
+func (p *Parser) parseWithRecovery() ([]ast.Node, []error) {
+	var (
+		errs  []error
+		nodes []ast.Node
+	)
+	for p.peek().TokenType != token.EOF {
+		start, tok := p.current, p.peek()
+		node, err := p.parseWithFlags(allowInclude) // only at top-level
+		if err == nil {
+			nodes = append(nodes, node)
+			continue
+		}
+		errs = append(errs, err)
+
+		// restart from the beginning of the form, whose parsing may
+		// have left the parser state inconsistent, and skip it
+		p.current, p.lambdadepth, p.loopdepth, p.quasiquotedepth = start, 0, 0, 0
+		p.synchronize()
+		nodes = append(nodes, &ast.ErrorExpr{Token: tok, End: p.end(), Err: err})
+	}
+	return nodes, errs
+}
+
+// synchronize skips the tokens of the current top-level form, which ends
+// when its parentheses are balanced. Because a missing closing parenthesis
+// would cause us to skip all the following forms, we also stop when we find
+// an opening parenthesis at the beginning of a line, which most likely
+// starts the next top-level form. We always consume at least one token.
+func (p *Parser) synchronize() {
+	depth := 0
+	for {
+		tok := p.peek()
+		switch tok.TokenType {
+		case token.EOF:
+			return
+
+		case token.OPEN:
+			if depth > 0 && tok.TokenPos.LineColumn == 1 {
+				return
+			}
+			depth++
+
+		case token.CLOSE:
+			depth--
+		}
+		p.advance()
+
+		// a quote shorthand is part of the following form
+		if depth <= 0 && !isQuoteShorthand(tok.TokenType) {
+			return
+		}
+	}
+}
+
+// isQuoteShorthand returns whether the given token type is a quote shorthand.
+func isQuoteShorthand(tt token.TokenType) bool {
+	switch tt {
+	case token.QUOTE, token.QUASIQUOTE, token.UNQUOTE, token.UNQUOTE_SPLICING:
+		return true
+	default:
+		return false
+	}
+}
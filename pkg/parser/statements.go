@@ -3,7 +3,10 @@
 package parser
 
 import (
+	"strings"
+
 	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/langspec"
 	"github.com/bassosimone/buresu/pkg/token"
 )
 
@@ -12,7 +15,7 @@ type parseFunc func(tok token.Token) (ast.Node, error)
 // parseStmtNotAllowed is a wrapper for statement parsing functions
 // that ensures we report an error if there's a statement in a context
 // in which it is not allowed by the grammar.
-func (p *parser) parseStmtNotAllowed(name string, fx parseFunc) parseFunc {
+func (p *Parser) parseStmtNotAllowed(name string, fx parseFunc) parseFunc {
 	return func(tok token.Token) (ast.Node, error) {
 		if _, err := fx(tok); err != nil {
 			return nil, err
@@ -21,8 +24,20 @@ func (p *parser) parseStmtNotAllowed(name string, fx parseFunc) parseFunc {
 	}
 }
 
+// parseBlockStmtNotAllowed is like parseStmtNotAllowed for the statements
+// the grammar only allows inside a block, e.g., `(lambda () (yield! 1))`, where
+// the error explains how to rewrite the code, e.g., `(lambda () (block (yield! 1)))`.
+func (p *Parser) parseBlockStmtNotAllowed(name string, fx parseFunc) parseFunc {
+	return func(tok token.Token) (ast.Node, error) {
+		if _, err := fx(tok); err != nil {
+			return nil, err
+		}
+		return nil, newError(tok, "%s statement not allowed in this context: it must appear directly inside a block", name)
+	}
+}
+
 // parseReturn parses a return form into an AST node.
-func (p *parser) parseReturn(tok token.Token) (ast.Node, error) {
+func (p *Parser) parseReturn(tok token.Token) (ast.Node, error) {
 	// Syntax: OPEN "return!" <expr> CLOSE
 	if _, err := p.match(token.OPEN); err != nil {
 		return nil, err
@@ -46,11 +61,83 @@ func (p *parser) parseReturn(tok token.Token) (ast.Node, error) {
 	if _, err := p.match(token.CLOSE); err != nil {
 		return nil, err
 	}
-	return &ast.ReturnStmt{Token: tok, Expr: expr}, nil
+	return &ast.ReturnStmt{Token: tok, End: p.end(), Expr: expr}, nil
+}
+
+// parseYield parses a yield form into an AST node.
+func (p *Parser) parseYield(tok token.Token) (ast.Node, error) {
+	// Syntax: OPEN "yield!" <expr> CLOSE
+	if _, err := p.match(token.OPEN); err != nil {
+		return nil, err
+	}
+	if _, err := p.matchAtomWithName("yield!"); err != nil {
+		return nil, err
+	}
+
+	// 1. reject yield outside of any lambda
+	if p.lambdadepth <= 0 {
+		return nil, newError(tok, "yield! outside of lambda")
+	}
+
+	// 2. <expr>
+	expr, err := p.parseWithFlags(0)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. CLOSE
+	if _, err := p.match(token.CLOSE); err != nil {
+		return nil, err
+	}
+	return &ast.YieldStmt{Token: tok, End: p.end(), Expr: expr}, nil
+}
+
+// parseBreak parses a break form into an AST node.
+func (p *Parser) parseBreak(tok token.Token) (ast.Node, error) {
+	// Syntax: OPEN "break!" CLOSE
+	if err := p.parseLoopStmt(tok, "break!"); err != nil {
+		return nil, err
+	}
+	return &ast.BreakStmt{Token: tok, End: p.end()}, nil
+}
+
+// parseContinue parses a continue form into an AST node.
+func (p *Parser) parseContinue(tok token.Token) (ast.Node, error) {
+	// Syntax: OPEN "continue!" CLOSE
+	if err := p.parseLoopStmt(tok, "continue!"); err != nil {
+		return nil, err
+	}
+	return &ast.ContinueStmt{Token: tok, End: p.end()}, nil
+}
+
+// parseLoopStmt parses the tokens of the break! and continue! statements,
+// which are only allowed inside the body of a loop.
+func (p *Parser) parseLoopStmt(tok token.Token, name string) error {
+	if _, err := p.match(token.OPEN); err != nil {
+		return err
+	}
+	if _, err := p.matchAtomWithName(name); err != nil {
+		return err
+	}
+
+	// 1. reject the statement outside of any loop, where the only loop
+	// before the for and foreach loops of version 3 is while
+	if p.loopdepth <= 0 {
+		if p.version < langspec.Version3 {
+			return newError(tok, "%s outside of while", name)
+		}
+		return newError(tok, "%s outside of loop", name)
+	}
+
+	// 2. CLOSE
+	if _, err := p.match(token.CLOSE); err != nil {
+		return err
+	}
+	return nil
 }
 
 // parseInclude parses an include form into an AST node.
-func (p *parser) parseInclude(tok token.Token) (ast.Node, error) {
+func (p *Parser) parseInclude(tok token.Token) (ast.Node, error) {
 	// Syntax: OPEN "include!" STRING CLOSE
 	if _, err := p.match(token.OPEN); err != nil {
 		return nil, err
@@ -70,5 +157,88 @@ func (p *parser) parseInclude(tok token.Token) (ast.Node, error) {
 	if _, err := p.match(token.CLOSE); err != nil {
 		return nil, err
 	}
-	return &ast.IncludeStmt{Token: tok, FilePath: filepath}, nil
+	return &ast.IncludeStmt{Token: tok, End: p.end(), FilePath: filepath}, nil
+}
+
+// parseImport parses an import form into an AST node.
+func (p *Parser) parseImport(tok token.Token) (ast.Node, error) {
+	// Syntax: OPEN "import" STRING "as" ATOM CLOSE
+	if _, err := p.match(token.OPEN); err != nil {
+		return nil, err
+	}
+	if _, err := p.matchAtomWithName("import"); err != nil {
+		return nil, err
+	}
+
+	// 1. STRING
+	node, err := p.parseString()
+	if err != nil {
+		return nil, err
+	}
+	filepath := node.(*ast.StringLiteral).Value // guaranteed to be a string
+
+	// 2. "as" ATOM
+	if _, err := p.matchAtomWithName("as"); err != nil {
+		return nil, err
+	}
+	alias, err := p.match(token.ATOM)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(alias.Value, "/") {
+		return nil, newError(alias, "import alias %q must not contain '/'", alias.Value)
+	}
+
+	// 3. CLOSE
+	if _, err := p.match(token.CLOSE); err != nil {
+		return nil, err
+	}
+	return &ast.ImportStmt{Token: tok, End: p.end(), FilePath: filepath, Alias: alias.Value}, nil
+}
+
+// parseModule parses a module form into an AST node.
+func (p *Parser) parseModule(tok token.Token) (ast.Node, error) {
+	// Syntax: OPEN "module" ATOM OPEN "export" ATOM* CLOSE CLOSE
+	if _, err := p.match(token.OPEN); err != nil {
+		return nil, err
+	}
+	if _, err := p.matchAtomWithName("module"); err != nil {
+		return nil, err
+	}
+
+	// 1. ATOM
+	name, err := p.match(token.ATOM)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. OPEN "export" ATOM* CLOSE
+	if _, err := p.match(token.OPEN); err != nil {
+		return nil, err
+	}
+	if _, err := p.matchAtomWithName("export"); err != nil {
+		return nil, err
+	}
+	var (
+		exports []string
+		uniqnam = make(map[string]struct{})
+	)
+	for p.peek().TokenType != token.CLOSE {
+		symbol, err := p.match(token.ATOM)
+		if err != nil {
+			return nil, err
+		}
+		if _, found := uniqnam[symbol.Value]; found {
+			return nil, newError(symbol, "module export %q is duplicated", symbol.Value)
+		}
+		uniqnam[symbol.Value] = struct{}{}
+		exports = append(exports, symbol.Value)
+	}
+	_, _ = p.match(token.CLOSE) // cannot fail
+
+	// 3. CLOSE
+	if _, err := p.match(token.CLOSE); err != nil {
+		return nil, err
+	}
+	return &ast.ModuleStmt{Token: tok, End: p.end(), Name: name.Value, Exports: exports}, nil
 }
@@ -4,27 +4,48 @@
 //
 // The scanner takes in input source code and emits a sequence
 // of tokens (a type defined in the token package).
+//
+// Use [Scan] to scan a whole file. Otherwise, use [New] and
+// [*Scanner.Next] to read the input lazily, one token at a time.
 package scanner
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/bassosimone/buresu/pkg/token"
 )
 
 // Scan scans the given file and returns a slice of tokens or an error.
 func Scan(filename string, file io.Reader) ([]token.Token, error) {
-	return newScanner(filename, file).Scan()
+	return New(filename, file).scanAll()
+}
+
+// ScanWithTrivia is like [Scan] but retains comments and blank lines as
+// the leading and trailing trivia of the tokens. Comments at the end of
+// the file become the leading trivia of the EOF token.
+func ScanWithTrivia(filename string, file io.Reader) ([]token.Token, error) {
+	return NewWithTrivia(filename, file).scanAll()
 }
 
 // Error represents a scanning error with position and message.
 type Error struct {
 	Pos     token.Position
 	Message string
+
+	// End is the position right after the character causing the error.
+	End token.Position
+}
+
+// Span returns the source code span of the error, which starts at the
+// beginning of the token we were scanning and includes the offending character.
+func (e *Error) Span() token.Span {
+	return token.Span{Start: e.Pos, End: e.End}
 }
 
 // Error returns the error message with file position details.
@@ -38,47 +59,123 @@ func (e *Error) Error() string {
 	)
 }
 
-// scanner is the internal scanner implementation.
-type scanner struct {
+// Scanner scans tokens one at a time, reading the input lazily, which
+// allows to process huge or interactive inputs (e.g., the standard input).
+//
+// Use [New] or [NewWithTrivia] to construct.
+type Scanner struct {
 	filename string
 	reader   *bufio.Reader
 	lineno   int
 	col      int
 	current  rune
+
+	// offset is the byte offset of the current rune.
+	offset int
+
+	// next is the byte offset of the rune following the current one.
+	next int
+
+	// last is the position right after the last rune we consumed.
+	last token.Position
+
+	// source, when not nil, accumulates the runes we consume, which
+	// allows us to know the source code of the string literals.
+	source *strings.Builder
+
+	// trivia indicates whether we should retain the trivia.
+	trivia bool
+
+	// leading contains the trivia preceding the next token.
+	leading []token.Trivia
+
+	// lineHasContent indicates whether the current line contains
+	// tokens or comments, which allows to detect blank lines.
+	lineHasContent bool
+
+	// sawNewline indicates whether we have seen a newline
+	// since the last token, which allows to detect trailing trivia.
+	sawNewline bool
+
+	// pending is the last token we scanned, which, when retaining trivia,
+	// we return only after scanning the following token, since a comment
+	// on the same line becomes part of its trailing trivia.
+	pending *token.Token
+
+	// started indicates whether we have loaded the first rune, which we
+	// defer until the first call to Next, such that New does not block.
+	started bool
+
+	// err is the error, other than [io.EOF], that interrupted reading.
+	err error
 }
 
-// newScanner creates a new scanner for the given filename and reader.
-func newScanner(filename string, reader io.Reader) *scanner {
-	scanner := &scanner{
+// New creates a new [*Scanner] for the given filename and reader.
+func New(filename string, reader io.Reader) *Scanner {
+	return &Scanner{
 		filename: filename,
 		reader:   bufio.NewReader(reader),
 		lineno:   1,
 		col:      0,
 		current:  0,
 	}
-	scanner.advance() // load the first rune
+}
+
+// NewWithTrivia is like [New] but the scanner retains the trivia as
+// documented by [ScanWithTrivia]. Because a comment following a token on
+// the same line is part of its trailing trivia, [*Scanner.Next] returns a
+// token only after scanning the following one.
+func NewWithTrivia(filename string, reader io.Reader) *Scanner {
+	scanner := New(filename, reader)
+	scanner.trivia = true
 	return scanner
 }
 
 // position returns the current position in the source code.
-func (s *scanner) position() token.Position {
+func (s *Scanner) position() token.Position {
 	return token.Position{
 		FileName:   s.filename,
 		LineNumber: s.lineno,
 		LineColumn: s.col,
+		Offset:     s.offset,
+	}
+}
+
+// endOfCurrent returns the position right after the current rune, or the
+// position right after the last rune we consumed at the end of the input.
+func (s *Scanner) endOfCurrent() token.Position {
+	if s.current == 0 {
+		return s.last
+	}
+	return token.Position{
+		FileName:   s.filename,
+		LineNumber: s.lineno,
+		LineColumn: s.col + 1,
+		Offset:     s.next,
 	}
 }
 
 // advance reads the next rune from the input and updates the scanner's state.
 //
 // Note: advance is a no-op if the scanner has reached the end of the input.
-func (s *scanner) advance() {
-	r, _, err := s.reader.ReadRune()
+func (s *Scanner) advance() {
+	if s.current != 0 {
+		s.last = s.endOfCurrent()
+		if s.source != nil {
+			s.source.WriteRune(s.current)
+		}
+	}
+	s.offset = s.next
+	r, size, err := s.reader.ReadRune()
 	if err != nil {
+		if !errors.Is(err, io.EOF) && s.err == nil {
+			s.err = err
+		}
 		s.current = 0
 		return
 	}
 	s.current = r
+	s.next += size
 	s.col++
 	if s.current == '\n' {
 		s.lineno++
@@ -86,147 +183,427 @@ func (s *scanner) advance() {
 	}
 }
 
-// newError creates a new scanning error with the given position and message.
-func newError(pos token.Position, message string) error {
+// newError creates a new scanning error with the given position and message,
+// which spans until the end of the current rune, i.e., the offending one.
+//
+// When reading the input failed, we return the read error instead, since
+// the scanning error is just a consequence of the truncated input.
+func (s *Scanner) newError(pos token.Position, message string) error {
+	if s.err != nil {
+		return s.err
+	}
 	return &Error{
 		Pos:     pos,
 		Message: message,
+		End:     s.endOfCurrent(),
 	}
 }
 
-// newToken creates a new token with the given type, position, and value.
-func (s *scanner) newToken(tokenType token.TokenType, pos token.Position, value string) token.Token {
-	return token.Token{TokenType: tokenType, TokenPos: pos, Value: value}
+// newToken creates a new token with the given type, position, and value,
+// which ends right after the last rune we consumed.
+func (s *Scanner) newToken(tokenType token.TokenType, pos token.Position, value string) token.Token {
+	return token.Token{TokenType: tokenType, TokenPos: pos, Value: value, TokenEnd: s.last}
 }
 
-// Scan scans the input and returns a slice of tokens or an error.
-func (s *scanner) Scan() ([]token.Token, error) {
+// Next scans and returns the next token, reading only the input we need
+// to scan it. At the end of the input, Next returns the EOF token and keeps
+// returning it on subsequent calls. After an error, the scanner state is
+// undefined and the caller should not call Next again.
+func (s *Scanner) Next() (token.Token, error) {
+	if !s.started {
+		s.started = true
+		s.advance() // load the first rune
+	}
+	if !s.trivia {
+		return s.scanToken()
+	}
+	for s.pending == nil || s.pending.TokenType != token.EOF {
+		tok, err := s.scanToken()
+		if err != nil {
+			return token.Token{}, err
+		}
+		prev := s.pending
+		s.pending = &tok
+		if prev != nil {
+			return *prev, nil
+		}
+	}
+	return *s.pending, nil
+}
+
+// scanAll scans the whole input and returns a slice of tokens or an error.
+func (s *Scanner) scanAll() ([]token.Token, error) {
 	var tokens []token.Token
+	for {
+		tok, err := s.Next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, tok)
+		if tok.TokenType == token.EOF {
+			return tokens, nil
+		}
+	}
+}
+
+// scanToken skips whitespace and comments and scans the next token.
+func (s *Scanner) scanToken() (token.Token, error) {
 	for {
 		pos := s.position()
 		chr := s.current
 
 		switch {
 		case chr == 0:
-			tokens = append(tokens, s.newToken(token.EOF, pos, ""))
-			return tokens, nil
+			if s.err != nil {
+				return token.Token{}, s.err
+			}
+			eof := s.newToken(token.EOF, pos, "")
+			eof.TokenEnd = pos
+			return s.emit(eof), nil
 
 		case unicode.IsSpace(chr):
+			if chr == '\n' {
+				s.newline()
+			}
 			s.advance()
 			continue
 
 		case chr == '(':
-			tokens = append(tokens, s.newToken(token.OPEN, pos, string(chr)))
 			s.advance()
-			continue
+			return s.emit(s.newToken(token.OPEN, pos, string(chr))), nil
 
 		case chr == ')':
-			tokens = append(tokens, s.newToken(token.CLOSE, pos, string(chr)))
 			s.advance()
-			continue
+			return s.emit(s.newToken(token.CLOSE, pos, string(chr))), nil
+
+		case chr == '\'':
+			s.advance()
+			return s.emit(s.newToken(token.QUOTE, pos, string(chr))), nil
+
+		case chr == '`':
+			s.advance()
+			return s.emit(s.newToken(token.QUASIQUOTE, pos, string(chr))), nil
+
+		case chr == ',' && s.lookahead(0) == '@':
+			s.advance()
+			s.advance()
+			return s.emit(s.newToken(token.UNQUOTE_SPLICING, pos, ",@")), nil
+
+		case chr == ',':
+			s.advance()
+			return s.emit(s.newToken(token.UNQUOTE, pos, string(chr))), nil
+
+		case chr == '#' && s.lookahead(0) == '\\':
+			tok, err := s.scanChar(pos)
+			if err != nil {
+				return token.Token{}, err
+			}
+			return s.emit(tok), nil
+
+		case chr == ':' && (unicode.IsLetter(s.lookahead(0)) || s.lookahead(0) == '_'):
+			tok, err := s.scanKeyword(pos)
+			if err != nil {
+				return token.Token{}, err
+			}
+			return s.emit(tok), nil
 
 		case chr == ';':
-			s.skipComment()
+			s.scanComment()
 			continue
 
 		case chr == '-':
-			if s.lookaheadIsDigit() {
-				token, err := s.scanNumber(pos)
+			if s.lookaheadIsNumber() {
+				tok, err := s.scanNumber(pos)
 				if err != nil {
-					return nil, err
+					return token.Token{}, err
 				}
-				tokens = append(tokens, token)
-				continue
+				return s.emit(tok), nil
 			}
-			token, err := s.scanSymbolicAtom(pos)
+			tok, err := s.scanSymbolicAtom(pos)
 			if err != nil {
-				return nil, err
+				return token.Token{}, err
 			}
-			tokens = append(tokens, token)
-			continue
+			return s.emit(tok), nil
 
-		case unicode.IsDigit(chr):
-			token, err := s.scanNumber(pos)
+		case unicode.IsDigit(chr) || (chr == '.' && isDigitOfBase(s.lookahead(0), 10)):
+			tok, err := s.scanNumber(pos)
 			if err != nil {
-				return nil, err
+				return token.Token{}, err
 			}
-			tokens = append(tokens, token)
-			continue
+			return s.emit(tok), nil
+
+		case chr == '"' && s.lookahead(0) == '"' && s.lookahead(1) == '"':
+			tok, err := s.scanMultilineString(pos)
+			if err != nil {
+				return token.Token{}, err
+			}
+			return s.emit(tok), nil
+
+		case chr == 'r' && (s.lookahead(0) == '"' || s.lookahead(0) == '#'):
+			tok, err := s.scanRawString(pos)
+			if err != nil {
+				return token.Token{}, err
+			}
+			return s.emit(tok), nil
 
 		case chr == '"':
-			token, err := s.scanString(pos)
+			tok, err := s.scanString(pos)
 			if err != nil {
-				return nil, err
+				return token.Token{}, err
 			}
-			tokens = append(tokens, token)
-			continue
+			return s.emit(tok), nil
 
 		case unicode.IsLetter(chr) || chr == '_':
-			token, err := s.scanAlphabeticAtom(pos)
+			tok, err := s.scanAlphabeticAtom(pos)
 			if err != nil {
-				return nil, err
+				return token.Token{}, err
 			}
-			tokens = append(tokens, token)
-			continue
+			return s.emit(tok), nil
 
 		default:
-			token, err := s.scanSymbolicAtom(pos)
+			tok, err := s.scanSymbolicAtom(pos)
 			if err != nil {
-				return nil, err
+				return token.Token{}, err
 			}
-			tokens = append(tokens, token)
-			continue
+			return s.emit(tok), nil
 		}
 	}
 }
 
-// skipComment skips over a comment in the input.
-func (s *scanner) skipComment() {
+// emit returns the given token after attaching the pending
+// leading trivia to it when we are retaining trivia.
+func (s *Scanner) emit(tok token.Token) token.Token {
+	if s.trivia {
+		tok.Leading, s.leading = s.leading, nil
+		s.lineHasContent, s.sawNewline = true, false
+	}
+	return tok
+}
+
+// newline updates the trivia state when we encounter a newline.
+func (s *Scanner) newline() {
+	if s.trivia && !s.lineHasContent {
+		s.leading = append(s.leading, token.Trivia{Kind: token.BLANK})
+	}
+	s.lineHasContent, s.sawNewline = false, true
+}
+
+// scanComment scans a comment in the input, which is either the trailing
+// trivia of the pending token, if on the same line, or leading trivia.
+func (s *Scanner) scanComment() {
+	var value strings.Builder
 	for s.current != '\n' && s.current != 0 {
+		value.WriteRune(s.current)
 		s.advance()
 	}
+	if s.trivia {
+		comment := token.Trivia{Kind: token.COMMENT, Value: value.String()}
+		if s.pending != nil && !s.sawNewline {
+			s.pending.Trailing = append(s.pending.Trailing, comment)
+		} else {
+			s.leading = append(s.leading, comment)
+		}
+		s.lineHasContent = true
+	}
+	if s.current == '\n' {
+		s.newline()
+	}
 	s.advance()
 }
 
 // scanNumber scans a number token from the input.
-func (s *scanner) scanNumber(pos token.Position) (token.Token, error) {
+//
+// The grammar is as follows:
+//
+//	<number> ::= ["-"] (<radix> | <decimal> | <rational>)
+//
+//	<radix> ::= "0" ("x" | "X") <digits>
+//	          | "0" ("o" | "O") <digits>
+//	          | "0" ("b" | "B") <digits>
+//
+//	<decimal> ::= <digits> ["." [<digits>]] [<exponent>]
+//	            | "." <digits> [<exponent>]
+//
+//	<exponent> ::= ("e" | "E") ["+" | "-"] <digits>
+//
+//	<rational> ::= <digits> "/" <digits>
+//
+// where single underscores may separate successive digits (e.g., `1_000`)
+// and may also follow the radix prefix (e.g., `0x_ff`).
+func (s *Scanner) scanNumber(pos token.Position) (token.Token, error) {
 	var value strings.Builder
+
+	// 1. scan the optional sign
+	if s.current == '-' {
+		s.consume(&value)
+	}
+
+	// 2. scan literals with a radix prefix
+	if s.current == '0' {
+		if base, kind, ok := radixOf(s.lookahead(0)); ok {
+			s.consume(&value)
+			s.consume(&value)
+			count, err := s.scanDigits(pos, &value, base, kind, true)
+			if err != nil {
+				return token.Token{}, err
+			}
+			if count <= 0 {
+				return token.Token{}, s.newError(pos, fmt.Sprintf("%s literal has no digits", kind))
+			}
+			if s.current == '.' || s.current == '/' {
+				return token.Token{}, s.newError(pos, fmt.Sprintf("%s literal must be an integer", kind))
+			}
+			return s.endNumber(pos, &value)
+		}
+	}
+
+	// 3. scan the integer part, which is empty for numbers such as `.5`
+	if _, err := s.scanDigits(pos, &value, 10, "decimal", false); err != nil {
+		return token.Token{}, err
+	}
+
+	// 4. scan the denominator of rational literals
+	if s.current == '/' {
+		s.consume(&value)
+		count, err := s.scanDigits(pos, &value, 10, "decimal", false)
+		if err != nil {
+			return token.Token{}, err
+		}
+		if count <= 0 {
+			return token.Token{}, s.newError(pos, "rational literal has no denominator")
+		}
+		return s.endNumber(pos, &value)
+	}
+
+	// 5. scan the fractional part
+	if s.current == '.' {
+		s.consume(&value)
+		if _, err := s.scanDigits(pos, &value, 10, "decimal", false); err != nil {
+			return token.Token{}, err
+		}
+		if s.current == '.' {
+			return token.Token{}, s.newError(pos, "multiple dots in number literal")
+		}
+	}
+
+	// 6. scan the exponent
+	if s.current == 'e' || s.current == 'E' {
+		s.consume(&value)
+		if s.current == '+' || s.current == '-' {
+			s.consume(&value)
+		}
+		count, err := s.scanDigits(pos, &value, 10, "decimal", false)
+		if err != nil {
+			return token.Token{}, err
+		}
+		if count <= 0 {
+			return token.Token{}, s.newError(pos, "exponent has no digits")
+		}
+	}
+
+	return s.endNumber(pos, &value)
+}
+
+// radixOf returns the base and the kind of number literal
+// corresponding to the given radix prefix character.
+func radixOf(chr rune) (int, string, bool) {
+	switch chr {
+	case 'x', 'X':
+		return 16, "hexadecimal", true
+	case 'o', 'O':
+		return 8, "octal", true
+	case 'b', 'B':
+		return 2, "binary", true
+	default:
+		return 0, "", false
+	}
+}
+
+// consume appends the current rune to the value and advances.
+func (s *Scanner) consume(value *strings.Builder) {
 	value.WriteRune(s.current)
 	s.advance()
-	seendot := false
+}
 
+// scanDigits scans digits of the given base, which may be separated by single
+// underscores, and returns the number of digits. The kind is the kind of number
+// literal for error messages, and leadingUnderscore indicates whether the
+// digits may start with an underscore, which is the case after a radix prefix.
+func (s *Scanner) scanDigits(pos token.Position,
+	value *strings.Builder, base int, kind string, leadingUnderscore bool) (int, error) {
+	count, underscore := 0, false
 	for {
 		chr := s.current
-		if chr == 0 {
-			break
-		} else if chr == '.' {
-			if seendot {
-				return token.Token{}, newError(pos, "multiple dots in number literal")
+		switch {
+		case chr == '_':
+			if underscore || (count <= 0 && !leadingUnderscore) {
+				return 0, s.newError(pos, "'_' must separate successive digits")
 			}
-			seendot = true
-		} else if !unicode.IsDigit(chr) {
-			if !unicode.IsSpace(chr) && !strings.ContainsRune("()", chr) {
-				err := newError(pos, fmt.Sprintf("expected [ ()], found: %U '%c'", chr, chr))
-				return token.Token{}, err
+			underscore = true
+
+		case isDigitOfBase(chr, base):
+			underscore = false
+			count++
+
+		case chr >= '0' && chr <= '9':
+			return 0, s.newError(pos, fmt.Sprintf("invalid digit '%c' in %s literal", chr, kind))
+
+		default:
+			if underscore {
+				return 0, s.newError(pos, "'_' must separate successive digits")
 			}
-			break
+			return count, nil
 		}
-		value.WriteRune(chr)
-		s.advance()
+		s.consume(value)
+	}
+}
+
+// isDigitOfBase returns whether the given rune is a digit of the given base.
+func isDigitOfBase(chr rune, base int) bool {
+	switch {
+	case chr >= '0' && chr <= '9':
+		return int(chr-'0') < base
+	case base == 16:
+		return (chr >= 'a' && chr <= 'f') || (chr >= 'A' && chr <= 'F')
+	default:
+		return false
 	}
+}
 
+// endNumber ensures the number is followed by a separator and returns the number token.
+func (s *Scanner) endNumber(pos token.Position, value *strings.Builder) (token.Token, error) {
+	chr := s.current
+	if chr != 0 && !unicode.IsSpace(chr) && !strings.ContainsRune("()", chr) {
+		err := s.newError(pos, fmt.Sprintf("expected [ ()], found: %U '%c'", chr, chr))
+		return token.Token{}, err
+	}
 	return s.newToken(token.NUMBER, pos, value.String()), nil
 }
 
+// startString starts scanning a string literal, which
+// entails recording the source code of the literal.
+func (s *Scanner) startString() {
+	s.source = &strings.Builder{}
+}
+
+// newString returns the STRING or CHAR token with the given value, whose
+// source code is the source code recorded since [*Scanner.startString].
+func (s *Scanner) newString(tokenType token.TokenType, pos token.Position, value string) token.Token {
+	tok := s.newToken(tokenType, pos, value)
+	tok.Source, s.source = s.source.String(), nil
+	return tok
+}
+
 // scanString scans a string token from the input.
-func (s *scanner) scanString(pos token.Position) (token.Token, error) {
+func (s *Scanner) scanString(pos token.Position) (token.Token, error) {
 	var value strings.Builder
+	s.startString()
 
 	for {
 		s.advance()
 		chr := s.current
 		if chr == 0 {
-			return token.Token{}, newError(pos, "expected '\"', found: EOF")
+			return token.Token{}, s.newError(pos, "expected '\"', found: EOF")
 		}
 
 		if chr == '\\' {
@@ -247,22 +624,216 @@ func (s *scanner) scanString(pos token.Position) (token.Token, error) {
 		//
 		// We support multiline strings
 		if !unicode.IsPrint(chr) && chr != '\n' && chr != '\t' {
-			err := newError(pos, fmt.Sprintf("expected printable character, found: %U '%c'", chr, chr))
+			err := s.newError(pos, fmt.Sprintf("expected printable character, found: %U '%c'", chr, chr))
 			return token.Token{}, err
 		}
 
 		value.WriteRune(chr)
 	}
 
-	return s.newToken(token.STRING, pos, value.String()), nil
+	return s.newString(token.STRING, pos, value.String()), nil
+}
+
+// scanRawString scans a raw string, i.e., `r"..."`, in which we do not
+// process escape sequences. To include double quotes, delimit the string
+// using the same number of hashes before the opening double quote and
+// after the closing double quote (e.g., `r#"say "hello""#`).
+func (s *Scanner) scanRawString(pos token.Position) (token.Token, error) {
+	var value strings.Builder
+	s.startString()
+
+	// 1. scan the opening delimiter
+	s.advance() // consume 'r'
+	hashes := 0
+	for s.current == '#' {
+		hashes++
+		s.advance()
+	}
+	if s.current != '"' {
+		return token.Token{}, s.newError(pos, fmt.Sprintf("expected '\"', found: %U '%c'", s.current, s.current))
+	}
+	closing := "\"" + strings.Repeat("#", hashes)
+
+	// 2. scan the content until the closing delimiter
+	for {
+		s.advance()
+		chr := s.current
+		if chr == 0 {
+			return token.Token{}, s.newError(pos, fmt.Sprintf("expected '%s', found: EOF", closing))
+		}
+
+		if chr == '"' && s.lookaheadHashes(hashes) {
+			for range len(closing) {
+				s.advance()
+			}
+			break
+		}
+
+		if !unicode.IsPrint(chr) && chr != '\n' && chr != '\t' {
+			err := s.newError(pos, fmt.Sprintf("expected printable character, found: %U '%c'", chr, chr))
+			return token.Token{}, err
+		}
+
+		value.WriteRune(chr)
+	}
+
+	return s.newString(token.STRING, pos, value.String()), nil
+}
+
+// lookaheadHashes checks if the next characters are the given number of hashes.
+func (s *Scanner) lookaheadHashes(count int) bool {
+	for idx := range count {
+		if s.lookahead(idx) != '#' {
+			return false
+		}
+	}
+	return true
+}
+
+// multilineStringLine is a line of a multiline string.
+type multilineStringLine struct {
+	// indent contains the whitespace at the beginning of the line.
+	indent string
+
+	// text contains the rest of the line after processing escapes.
+	text string
+}
+
+// scanMultilineString scans a triple-quoted string, which starts on the line
+// following the opening delimiter and from which we remove the whitespace
+// prefix common to all its lines, so that it can be indented like the code.
+//
+// For example, the value of the following string is "Hello,\n\tworld!":
+//
+//	(display """
+//	    Hello,
+//	    \tworld!
+//	    """)
+//
+// where the closing delimiter on its own line also contributes to the common
+// prefix and the newline preceding such a line is not part of the string.
+func (s *Scanner) scanMultilineString(pos token.Position) (token.Token, error) {
+	s.startString()
+
+	// 1. the opening delimiter must be followed by a newline
+	for range 3 {
+		s.advance()
+	}
+	for s.current == ' ' || s.current == '\t' {
+		s.advance()
+	}
+	if s.current != '\n' {
+		if s.current == 0 {
+			return token.Token{}, s.newError(pos, "expected newline after '\"\"\"', found: EOF")
+		}
+		return token.Token{}, s.newError(pos, fmt.Sprintf(
+			"expected newline after '\"\"\"', found: %U '%c'", s.current, s.current))
+	}
+
+	// 2. scan the lines until the closing delimiter
+	var (
+		lines    []multilineStringLine
+		line     multilineStringLine
+		text     strings.Builder
+		inIndent = true
+	)
+	for {
+		s.advance()
+		chr := s.current
+		switch {
+		case chr == 0:
+			return token.Token{}, s.newError(pos, "expected '\"\"\"', found: EOF")
+
+		case chr == '\n':
+			line.text = text.String()
+			lines = append(lines, line)
+			line, inIndent = multilineStringLine{}, true
+			text.Reset()
+			continue
+
+		case chr == '"' && s.lookahead(0) == '"' && s.lookahead(1) == '"':
+			for range 3 {
+				s.advance()
+			}
+			line.text = text.String()
+			lines = append(lines, line)
+			return s.newString(token.STRING, pos, dedent(lines)), nil
+
+		case inIndent && (chr == ' ' || chr == '\t'):
+			line.indent += string(chr)
+			continue
+		}
+
+		inIndent = false
+		if chr == '\\' {
+			esc, err := s.scanEscapeSequence(pos)
+			if err != nil {
+				return token.Token{}, err
+			}
+			text.WriteString(esc)
+			continue
+		}
+		if !unicode.IsPrint(chr) && chr != '\t' {
+			err := s.newError(pos, fmt.Sprintf("expected printable character, found: %U '%c'", chr, chr))
+			return token.Token{}, err
+		}
+		text.WriteRune(chr)
+	}
+}
+
+// dedent joins the lines of a multiline string removing the whitespace prefix
+// common to the non-blank lines and to the last line, if blank, which is the
+// line containing the closing delimiter, and which we therefore discard.
+func dedent(lines []multilineStringLine) string {
+	// 1. compute the common prefix
+	last := len(lines) - 1
+	var prefix *string
+	for idx, line := range lines {
+		if line.text == "" && idx != last {
+			continue
+		}
+		if prefix == nil {
+			prefix = &line.indent
+			continue
+		}
+		common := commonPrefix(*prefix, line.indent)
+		prefix = &common
+	}
+
+	// 2. discard the line containing the closing delimiter, if blank
+	if lines[last].text == "" {
+		lines = lines[:last]
+	}
+
+	// 3. join the lines removing the prefix and the indentation of blank lines
+	var out strings.Builder
+	for idx, line := range lines {
+		if idx > 0 {
+			out.WriteString("\n")
+		}
+		if line.text != "" {
+			out.WriteString(strings.TrimPrefix(line.indent, *prefix))
+			out.WriteString(line.text)
+		}
+	}
+	return out.String()
+}
+
+// commonPrefix returns the longest common prefix of the given strings.
+func commonPrefix(a, b string) string {
+	idx := 0
+	for idx < len(a) && idx < len(b) && a[idx] == b[idx] {
+		idx++
+	}
+	return a[:idx]
 }
 
 // scanEscapeSequence scans an escape sequence from the input.
-func (s *scanner) scanEscapeSequence(pos token.Position) (string, error) {
+func (s *Scanner) scanEscapeSequence(pos token.Position) (string, error) {
 	s.advance()
 	chr := s.current
 	if chr == 0 {
-		return "", newError(pos, `expected [nrt"\\] character, found: EOF`)
+		return "", s.newError(pos, `expected [nrtxu"\\] character, found: EOF`)
 	}
 	switch chr {
 	case 'n':
@@ -275,13 +846,138 @@ func (s *scanner) scanEscapeSequence(pos token.Position) (string, error) {
 		return "\"", nil
 	case '\\':
 		return "\\", nil
+	case 'x':
+		return s.scanHexEscape(pos)
+	case 'u':
+		return s.scanUnicodeEscape(pos)
 	default:
-		return "", newError(pos, fmt.Sprintf("unknown escape sequence: \\%U '%c'", chr, chr))
+		return "", s.newError(pos, fmt.Sprintf("unknown escape sequence: \\%U '%c'", chr, chr))
 	}
 }
 
+// scanHexEscape scans the `\xNN` escape sequence, where NN
+// are two hexadecimal digits representing an ASCII character.
+func (s *Scanner) scanHexEscape(pos token.Position) (string, error) {
+	value := 0
+	for range 2 {
+		s.advance()
+		digit, ok := hexDigitValue(s.current)
+		if !ok {
+			return "", s.newError(pos, "expected two hexadecimal digits after '\\x'")
+		}
+		value = value*16 + digit
+	}
+	if value > unicode.MaxASCII {
+		return "", s.newError(pos, "'\\x' escape sequence must be in range [\\x00-\\x7f]")
+	}
+	return string(rune(value)), nil
+}
+
+// scanUnicodeEscape scans the `\u{N...}` escape sequence, where N... are
+// one to six hexadecimal digits representing a Unicode code point.
+func (s *Scanner) scanUnicodeEscape(pos token.Position) (string, error) {
+	s.advance()
+	if s.current != '{' {
+		return "", s.newError(pos, "expected '{' after '\\u'")
+	}
+	value, count := 0, 0
+	for {
+		s.advance()
+		if s.current == '}' {
+			break
+		}
+		digit, ok := hexDigitValue(s.current)
+		if !ok || count >= 6 {
+			return "", s.newError(pos, "expected one to six hexadecimal digits and '}' after '\\u{'")
+		}
+		value, count = value*16+digit, count+1
+	}
+	if count <= 0 {
+		return "", s.newError(pos, "expected one to six hexadecimal digits and '}' after '\\u{'")
+	}
+	if !utf8.ValidRune(rune(value)) {
+		return "", s.newError(pos, fmt.Sprintf("invalid Unicode code point: U+%04X", value))
+	}
+	return string(rune(value)), nil
+}
+
+// hexDigitValue returns the value of the given hexadecimal digit.
+func hexDigitValue(chr rune) (int, bool) {
+	switch {
+	case chr >= '0' && chr <= '9':
+		return int(chr - '0'), true
+	case chr >= 'a' && chr <= 'f':
+		return int(chr-'a') + 10, true
+	case chr >= 'A' && chr <= 'F':
+		return int(chr-'A') + 10, true
+	default:
+		return 0, false
+	}
+}
+
+// scanChar scans a character literal, which is either `#\` followed by a
+// printable character (e.g., `#\a`), by the name of a character (e.g.,
+// `#\space`), or by a Unicode code point (e.g., `#\u{1F600}`).
+func (s *Scanner) scanChar(pos token.Position) (token.Token, error) {
+	s.startString()
+	s.advance() // consume '#'
+	s.advance() // consume '\'
+
+	// 1. scan the character or its name
+	chr := s.current
+	switch {
+	case chr == 0:
+		return token.Token{}, s.newError(pos, "expected character after '#\\', found: EOF")
+
+	case chr == 'u' && s.lookahead(0) == '{':
+		value, err := s.scanUnicodeEscape(pos)
+		if err != nil {
+			return token.Token{}, err
+		}
+		s.advance()
+		chr = []rune(value)[0]
+
+	case unicode.IsLetter(chr) && unicode.IsLetter(s.lookahead(0)):
+		var name strings.Builder
+		for unicode.IsLetter(s.current) {
+			name.WriteRune(s.current)
+			s.advance()
+		}
+		value, found := token.CharNames[name.String()]
+		if !found {
+			return token.Token{}, s.newError(pos, fmt.Sprintf("unknown character name: %s", name.String()))
+		}
+		chr = value
+
+	case unicode.IsPrint(chr):
+		s.advance()
+
+	default:
+		return token.Token{}, s.newError(pos, fmt.Sprintf("expected printable character, found: %U", chr))
+	}
+
+	// 2. make sure the character is followed by a separator
+	if s.current != 0 && !unicode.IsSpace(s.current) && !strings.ContainsRune("()", s.current) {
+		return token.Token{}, s.newError(pos, fmt.Sprintf(
+			"expected [ ()], found: %U '%c'", s.current, s.current))
+	}
+	return s.newString(token.CHAR, pos, string(chr)), nil
+}
+
+// scanKeyword scans a keyword, i.e., `:` followed by an alphabetic atom.
+func (s *Scanner) scanKeyword(pos token.Position) (token.Token, error) {
+	s.advance() // consume ':'
+	tok, err := s.scanAlphabeticAtom(pos)
+	if err != nil {
+		return token.Token{}, err
+	}
+	tok.TokenType = token.KEYWORD
+	tok.Value = ":" + tok.Value
+	return tok, nil
+}
+
 // scanAlphabeticAtom scans an alphabetic atom token from the input.
-func (s *scanner) scanAlphabeticAtom(pos token.Position) (token.Token, error) {
+func (s *Scanner) scanAlphabeticAtom(pos token.Position) (token.Token, error) {
 	var value strings.Builder
 	value.WriteRune(s.current)
 	s.advance()
@@ -291,16 +987,16 @@ func (s *scanner) scanAlphabeticAtom(pos token.Position) (token.Token, error) {
 		if chr == 0 {
 			break
 		}
-		if !unicode.IsLetter(chr) && !unicode.IsDigit(chr) && chr != '_' && chr != '-' {
-			if chr == '!' || chr == '?' {
+		if !unicode.IsLetter(chr) && !unicode.IsDigit(chr) && chr != '_' && chr != '-' && chr != '/' {
+			if chr == '!' || chr == '?' || chr == '*' {
 				value.WriteRune(chr)
 				s.advance()
 				// Check if the next character is a valid separator
-				// after the legitimate `?!` ending rune
+				// after the legitimate `?!*` ending rune
 				chr = s.current
 			}
 			if chr != 0 && !unicode.IsSpace(chr) && !strings.ContainsRune("()", chr) {
-				err := newError(pos, fmt.Sprintf("expected [ ()], found: %U '%c'", chr, chr))
+				err := s.newError(pos, fmt.Sprintf("expected [ ()], found: %U '%c'", chr, chr))
 				return token.Token{}, err
 			}
 			break
@@ -313,7 +1009,7 @@ func (s *scanner) scanAlphabeticAtom(pos token.Position) (token.Token, error) {
 }
 
 // scanSymbolicAtom scans a symbolic atom token from the input.
-func (s *scanner) scanSymbolicAtom(pos token.Position) (token.Token, error) {
+func (s *Scanner) scanSymbolicAtom(pos token.Position) (token.Token, error) {
 	var tok token.Token
 	switch s.current {
 	case '+':
@@ -388,13 +1084,13 @@ func (s *scanner) scanSymbolicAtom(pos token.Position) (token.Token, error) {
 		}
 
 	default:
-		return token.Token{}, newError(pos, fmt.Sprintf(
+		return token.Token{}, s.newError(pos, fmt.Sprintf(
 			"unexpected symbolic atom: %U '%c'", s.current, s.current))
 	}
 
 	if s.current != 0 && !unicode.IsSpace(s.current) &&
 		!strings.ContainsRune("()", s.current) {
-		err := newError(pos, fmt.Sprintf(
+		err := s.newError(pos, fmt.Sprintf(
 			"expected [ ()], found: %U '%c'", s.current, s.current))
 		return token.Token{}, err
 	}
@@ -402,12 +1098,20 @@ func (s *scanner) scanSymbolicAtom(pos token.Position) (token.Token, error) {
 	return tok, nil
 }
 
-// lookaheadIsDigit checks if the next character is a digit.
-func (s *scanner) lookaheadIsDigit() bool {
-	r, _, err := s.reader.ReadRune()
-	if err != nil {
-		return false
+// lookahead returns the byte at the given index after the current rune,
+// or zero when there is no such byte. Because we use lookahead to detect
+// ASCII characters, we do not need to decode runes here.
+func (s *Scanner) lookahead(index int) rune {
+	data, _ := s.reader.Peek(index + 1)
+	if len(data) <= index {
+		return 0
 	}
-	defer s.reader.UnreadRune()
-	return unicode.IsDigit(r)
+	return rune(data[index])
+}
+
+// lookaheadIsNumber checks if the next characters start a number, i.e.,
+// if there is a digit or a dot followed by a digit (e.g., `-.5`).
+func (s *Scanner) lookaheadIsNumber() bool {
+	return isDigitOfBase(s.lookahead(0), 10) ||
+		(s.lookahead(0) == '.' && isDigitOfBase(s.lookahead(1), 10))
 }
@@ -4,6 +4,9 @@ package scanner_test
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"strings"
 	"testing"
 
@@ -91,7 +94,7 @@ func TestScan_ErrorNonPrintableCharInString(t *testing.T) {
 
 func TestScan_ErrorEOFInEscapeSequence(t *testing.T) {
 	input := `"hello\`
-	expectedErrorMsg := "test:1:1: scanner: expected [nrt\"\\\\] character, found: EOF"
+	expectedErrorMsg := "test:1:1: scanner: expected [nrtxu\"\\\\] character, found: EOF"
 	runScanTest(t, input, nil, true, expectedErrorMsg)
 }
 
@@ -105,15 +108,28 @@ func TestScan_AlphabeticAtomEOF(t *testing.T) {
 			},
 			TokenType: token.ATOM,
 			Value:     "abc",
+			TokenEnd: token.Position{
+				FileName:   "test",
+				LineNumber: 1,
+				LineColumn: 4,
+				Offset:     3,
+			},
 		},
 		{
 			TokenPos: token.Position{
 				FileName:   "test",
 				LineNumber: 1,
 				LineColumn: 3,
+				Offset:     3,
 			},
 			TokenType: token.EOF,
 			Value:     "",
+			TokenEnd: token.Position{
+				FileName:   "test",
+				LineNumber: 1,
+				LineColumn: 3,
+				Offset:     3,
+			},
 		},
 	}
 	input := `abc`
@@ -130,15 +146,28 @@ func TestScan_NumberEOF(t *testing.T) {
 			},
 			TokenType: token.NUMBER,
 			Value:     "012",
+			TokenEnd: token.Position{
+				FileName:   "test",
+				LineNumber: 1,
+				LineColumn: 4,
+				Offset:     3,
+			},
 		},
 		{
 			TokenPos: token.Position{
 				FileName:   "test",
 				LineNumber: 1,
 				LineColumn: 3,
+				Offset:     3,
 			},
 			TokenType: token.EOF,
 			Value:     "",
+			TokenEnd: token.Position{
+				FileName:   "test",
+				LineNumber: 1,
+				LineColumn: 3,
+				Offset:     3,
+			},
 		},
 	}
 	input := `012`
@@ -155,17 +184,354 @@ func TestScan_LookAheadIsDigit(t *testing.T) {
 			},
 			TokenType: token.ATOM,
 			Value:     "-",
+			TokenEnd: token.Position{
+				FileName:   "test",
+				LineNumber: 1,
+				LineColumn: 2,
+				Offset:     1,
+			},
 		},
 		{
 			TokenPos: token.Position{
 				FileName:   "test",
 				LineNumber: 1,
 				LineColumn: 1,
+				Offset:     1,
 			},
 			TokenType: token.EOF,
 			Value:     "",
+			TokenEnd: token.Position{
+				FileName:   "test",
+				LineNumber: 1,
+				LineColumn: 1,
+				Offset:     1,
+			},
 		},
 	}
 	input := `-`
 	runScanTest(t, input, expected, false, "")
 }
+
+func TestScanWithTrivia(t *testing.T) {
+	input := ";; header\n\n(a ;; trailing\n\n\n  b) ; after\n;; footer\n"
+	tokens, err := scanner.ScanWithTrivia("test", strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type trivia struct {
+		Value    string
+		Leading  []token.Trivia
+		Trailing []token.Trivia
+	}
+	var got []trivia
+	for _, tok := range tokens {
+		got = append(got, trivia{Value: tok.Value, Leading: tok.Leading, Trailing: tok.Trailing})
+	}
+	expected := []trivia{{
+		Value: "(",
+		Leading: []token.Trivia{
+			{Kind: token.COMMENT, Value: ";; header"},
+			{Kind: token.BLANK},
+		},
+	}, {
+		Value:    "a",
+		Trailing: []token.Trivia{{Kind: token.COMMENT, Value: ";; trailing"}},
+	}, {
+		Value:   "b",
+		Leading: []token.Trivia{{Kind: token.BLANK}, {Kind: token.BLANK}},
+	}, {
+		Value:    ")",
+		Trailing: []token.Trivia{{Kind: token.COMMENT, Value: "; after"}},
+	}, {
+		Value:   "",
+		Leading: []token.Trivia{{Kind: token.COMMENT, Value: ";; footer"}},
+	}}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Fatal(diff)
+	}
+
+	t.Run("the default mode does not retain trivia", func(t *testing.T) {
+		tokens, err := scanner.Scan("test", strings.NewReader(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tok := range tokens {
+			if tok.Leading != nil || tok.Trailing != nil {
+				t.Fatalf("unexpected trivia in %+v", tok)
+			}
+		}
+	})
+}
+
+func TestScanSpans(t *testing.T) {
+	input := "(ciào\n  \"é\")"
+	tokens, err := scanner.Scan("test", strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, tok := range tokens {
+		span := tok.Span()
+		got = append(got, fmt.Sprintf("%s %s [%d,%d)", tok.TokenType, span, span.Start.Offset, span.End.Offset))
+	}
+	expected := []string{
+		"OPEN test:1:1-1:2 [0,1)",
+		"ATOM test:1:2-1:6 [1,6)",
+		"STRING test:2:3-2:6 [9,13)",
+		"CLOSE test:2:6-2:7 [13,14)",
+		"EOF test:2:6-2:6 [14,14)",
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestErrorSpan(t *testing.T) {
+	_, err := scanner.Scan("test", strings.NewReader("(abc$ 1)"))
+	var serr *scanner.Error
+	if !errors.As(err, &serr) {
+		t.Fatalf("expected *scanner.Error, got %v", err)
+	}
+	span := serr.Span()
+	if got := fmt.Sprintf("%s [%d,%d)", span, span.Start.Offset, span.End.Offset); got != "test:1:2-1:6 [1,5)" {
+		t.Fatalf("unexpected span: %s", got)
+	}
+}
+
+func TestScanNumbers(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect string
+	}{
+		// valid literals
+		{"0", "0"},
+		{"-42", "-42"},
+		{"1_000_000", "1_000_000"},
+		{"0xff", "0xff"},
+		{"0XFF", "0XFF"},
+		{"0x_ff_ff", "0x_ff_ff"},
+		{"-0o17", "-0o17"},
+		{"0b1010", "0b1010"},
+		{"3.14", "3.14"},
+		{"1.", "1."},
+		{".5", ".5"},
+		{"-.5", "-.5"},
+		{"1e9", "1e9"},
+		{"6.022E+23", "6.022E+23"},
+		{"1_0.2_5e-1_0", "1_0.2_5e-1_0"},
+		{"1/3", "1/3"},
+		{"-22/7", "-22/7"},
+
+		// invalid literals
+		{"0x", "test:1:1: scanner: hexadecimal literal has no digits"},
+		{"0o18", "test:1:1: scanner: invalid digit '8' in octal literal"},
+		{"0b102", "test:1:1: scanner: invalid digit '2' in binary literal"},
+		{"0xfg", "test:1:1: scanner: expected [ ()], found: U+0067 'g'"},
+		{"0x1.5", "test:1:1: scanner: hexadecimal literal must be an integer"},
+		{"1__000", "test:1:1: scanner: '_' must separate successive digits"},
+		{"1000_", "test:1:1: scanner: '_' must separate successive digits"},
+		{"1._5", "test:1:1: scanner: '_' must separate successive digits"},
+		{"1e", "test:1:1: scanner: exponent has no digits"},
+		{"1e+", "test:1:1: scanner: exponent has no digits"},
+		{"1.2.3", "test:1:1: scanner: multiple dots in number literal"},
+		{"1/", "test:1:1: scanner: rational literal has no denominator"},
+		{"1/2/3", "test:1:1: scanner: expected [ ()], found: U+002F '/'"},
+		{"1.5/2", "test:1:1: scanner: expected [ ()], found: U+002F '/'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tokens, err := scanner.Scan("test", strings.NewReader(tt.input))
+			var got string
+			switch {
+			case err != nil:
+				got = err.Error()
+			case len(tokens) != 2 || tokens[0].TokenType != token.NUMBER:
+				t.Fatalf("expected a single NUMBER token, got %+v", tokens)
+			default:
+				got = tokens[0].Value
+			}
+			if got != tt.expect {
+				t.Fatalf("expected %q, got %q", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestScanStrings(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect string
+	}{
+		// escape sequences
+		{`"a\x41b"`, "aAb"},
+		{`"\u{48}\u{e8}\u{1F600}"`, "Hè😀"},
+		{`"\x80"`, `test:1:1: scanner: '\x' escape sequence must be in range [\x00-\x7f]`},
+		{`"\x4"`, `test:1:1: scanner: expected two hexadecimal digits after '\x'`},
+		{`"\u48"`, `test:1:1: scanner: expected '{' after '\u'`},
+		{`"\u{}"`, `test:1:1: scanner: expected one to six hexadecimal digits and '}' after '\u{'`},
+		{`"\u{1234567}"`, `test:1:1: scanner: expected one to six hexadecimal digits and '}' after '\u{'`},
+		{`"\u{D800}"`, `test:1:1: scanner: invalid Unicode code point: U+D800`},
+
+		// raw strings
+		{`r"C:\path\n"`, `C:\path\n`},
+		{`r#"say "hello""#`, `say "hello"`},
+		{`r##"a "# b"##`, `a "# b`},
+		{"r\"a\nb\"", "a\nb"},
+		{`r#"abc"`, `test:1:1: scanner: expected '"#', found: EOF`},
+		{`r#x`, `test:1:1: scanner: expected '"', found: U+0078 'x'`},
+
+		// multiline strings
+		{"\"\"\"\n    Hello,\n      \\tworld!\n    \"\"\"", "Hello,\n  \tworld!"},
+		{"\"\"\"\n\tfirst\n\n\tsecond\n\t\"\"\"", "first\n\nsecond"},
+		{"\"\"\"  \n  a\n    b\"\"\"", "a\n  b"},
+		{"\"\"\"\n  a \"quoted\" word\n\"\"\"", "  a \"quoted\" word"},
+		{"\"\"\"\n\"\"\"", ""},
+		{"\"\"\"a\n\"\"\"", `test:1:1: scanner: expected newline after '"""', found: U+0061 'a'`},
+		{"\"\"\"\nabc", `test:1:1: scanner: expected '"""', found: EOF`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tokens, err := scanner.Scan("test", strings.NewReader(tt.input))
+			var got string
+			switch {
+			case err != nil:
+				got = err.Error()
+			case len(tokens) != 2 || tokens[0].TokenType != token.STRING:
+				t.Fatalf("expected a single STRING token, got %+v", tokens)
+			case tokens[0].Source != tt.input:
+				t.Fatalf("expected source %q, got %q", tt.input, tokens[0].Source)
+			default:
+				got = tokens[0].Value
+			}
+			if got != tt.expect {
+				t.Fatalf("expected %q, got %q", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestScanCharsAndKeywords(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect token.Token
+		err    string
+	}{
+		// characters
+		{input: `#\a`, expect: token.Token{TokenType: token.CHAR, Value: "a", Source: `#\a`}},
+		{input: `#\(`, expect: token.Token{TokenType: token.CHAR, Value: "(", Source: `#\(`}},
+		{input: `#\è`, expect: token.Token{TokenType: token.CHAR, Value: "è", Source: `#\è`}},
+		{input: `#\space`, expect: token.Token{TokenType: token.CHAR, Value: " ", Source: `#\space`}},
+		{input: `#\newline`, expect: token.Token{TokenType: token.CHAR, Value: "\n", Source: `#\newline`}},
+		{input: `#\u{1F600}`, expect: token.Token{TokenType: token.CHAR, Value: "😀", Source: `#\u{1F600}`}},
+		{input: `#\u`, expect: token.Token{TokenType: token.CHAR, Value: "u", Source: `#\u`}},
+		{input: `#\`, err: `test:1:1: scanner: expected character after '#\', found: EOF`},
+		{input: `#\spaces`, err: `test:1:1: scanner: unknown character name: spaces`},
+		{input: `#\a1`, err: `test:1:1: scanner: expected [ ()], found: U+0031 '1'`},
+
+		// keywords
+		{input: `:name`, expect: token.Token{TokenType: token.KEYWORD, Value: ":name"}},
+		{input: `:valid?`, expect: token.Token{TokenType: token.KEYWORD, Value: ":valid?"}},
+		{input: `:`, expect: token.Token{TokenType: token.ATOM, Value: ":"}},
+		{input: `::`, expect: token.Token{TokenType: token.ATOM, Value: "::"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tokens, err := scanner.Scan("test", strings.NewReader(tt.input))
+			if err != nil {
+				if err.Error() != tt.err {
+					t.Fatalf("expected error %q, got %q", tt.err, err.Error())
+				}
+				return
+			}
+			if tt.err != "" {
+				t.Fatalf("expected error %q, got nil", tt.err)
+			}
+			if len(tokens) != 2 {
+				t.Fatalf("expected a single token, got %+v", tokens)
+			}
+			got := token.Token{TokenType: tokens[0].TokenType, Value: tokens[0].Value, Source: tokens[0].Source}
+			if diff := cmp.Diff(tt.expect, got); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+// chunkReader is an [io.Reader] returning a chunk on each read, which
+// allows us to check that the scanner only reads the input it needs.
+type chunkReader struct {
+	chunks []string
+	err    error
+}
+
+// Read implements [io.Reader].
+func (r *chunkReader) Read(data []byte) (int, error) {
+	if len(r.chunks) <= 0 {
+		return 0, r.err
+	}
+	count := copy(data, r.chunks[0])
+	r.chunks[0] = r.chunks[0][count:]
+	if r.chunks[0] == "" {
+		r.chunks = r.chunks[1:]
+	}
+	return count, nil
+}
+
+func TestScannerNext(t *testing.T) {
+	t.Run("we read the input lazily", func(t *testing.T) {
+		reader := &chunkReader{chunks: []string{"(a b)\n", "c ; comment\n", ")"}, err: io.EOF}
+		sc := scanner.New("<stdin>", reader)
+
+		var values []string
+		for range 4 {
+			tok, err := sc.Next()
+			if err != nil {
+				t.Fatal(err)
+			}
+			values = append(values, tok.Value)
+		}
+		if diff := cmp.Diff([]string{"(", "a", "b", ")"}, values); diff != "" {
+			t.Fatal(diff)
+		}
+		if len(reader.chunks) != 2 {
+			t.Fatalf("expected 2 unread chunks, got %d", len(reader.chunks))
+		}
+
+		var types []token.TokenType
+		for range 4 {
+			tok, err := sc.Next()
+			if err != nil {
+				t.Fatal(err)
+			}
+			types = append(types, tok.TokenType)
+		}
+		expected := []token.TokenType{token.ATOM, token.CLOSE, token.EOF, token.EOF}
+		if diff := cmp.Diff(expected, types); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+
+	t.Run("we return the trailing trivia of the tokens", func(t *testing.T) {
+		sc := scanner.NewWithTrivia("<stdin>", strings.NewReader("a ; one\nb"))
+		tok, err := sc.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []token.Trivia{{Kind: token.COMMENT, Value: "; one"}}
+		if diff := cmp.Diff(expected, tok.Trailing); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+
+	t.Run("we return the errors reading the input", func(t *testing.T) {
+		expected := errors.New("mocked error")
+		sc := scanner.New("<stdin>", &chunkReader{chunks: []string{"a \"b"}, err: expected})
+		if _, err := sc.Next(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := sc.Next(); !errors.Is(err, expected) {
+			t.Fatalf("expected %v, got %v", expected, err)
+		}
+	})
+}
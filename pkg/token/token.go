@@ -16,12 +16,18 @@ const (
 	// bound to a keyword or a symbol name.
 	ATOM TokenType = "ATOM"
 
+	// CHAR represents a character token (e.g., `#\a`).
+	CHAR TokenType = "CHAR"
+
 	// CLOSE represents a closing parenthesis token.
 	CLOSE TokenType = "CLOSE"
 
 	// EOF represents the end of file token.
 	EOF TokenType = "EOF"
 
+	// KEYWORD represents a self-evaluating keyword token (e.g., `:name`).
+	KEYWORD TokenType = "KEYWORD"
+
 	// NUMBER represents a numeric token.
 	NUMBER TokenType = "NUMBER"
 
@@ -33,13 +39,39 @@ const (
 
 	// ELLIPSIS represents an ellipsis token.
 	ELLIPSIS TokenType = "ELLIPSIS"
+
+	// QUOTE represents the `'` token abbreviating `(quote ...)`.
+	QUOTE TokenType = "QUOTE"
+
+	// QUASIQUOTE represents the "`" token starting a quasiquote.
+	QUASIQUOTE TokenType = "QUASIQUOTE"
+
+	// UNQUOTE represents the `,` token starting an unquote.
+	UNQUOTE TokenType = "UNQUOTE"
+
+	// UNQUOTE_SPLICING represents the `,@` token starting an unquote-splicing.
+	UNQUOTE_SPLICING TokenType = "UNQUOTE_SPLICING"
 )
 
+// CharNames maps the names of the characters that we cannot write
+// literally inside a CHAR token (e.g., `#\space`) to such characters.
+var CharNames = map[string]rune{
+	"newline": '\n',
+	"nul":     0,
+	"return":  '\r',
+	"space":   ' ',
+	"tab":     '\t',
+}
+
 // Position represents the position of a token in the source code.
+//
+// Lines and columns are one-based and columns count runes, while
+// the offset is the zero-based offset in bytes from the file start.
 type Position struct {
 	FileName   string
 	LineNumber int
 	LineColumn int
+	Offset     int
 }
 
 // String returns the string representation of the Position.
@@ -47,11 +79,59 @@ func (p Position) String() string {
 	return fmt.Sprintf("%s:%d:%d", p.FileName, p.LineNumber, p.LineColumn)
 }
 
+// Span represents a range of the source code, where the End position
+// is exclusive, i.e., it is the position right after the last character.
+type Span struct {
+	Start Position
+	End   Position
+}
+
+// String returns the string representation of the Span.
+func (s Span) String() string {
+	return fmt.Sprintf("%s-%d:%d", s.Start, s.End.LineNumber, s.End.LineColumn)
+}
+
+// TriviaKind represents the kind of [Trivia].
+type TriviaKind string
+
+const (
+	// BLANK represents an empty line.
+	BLANK TriviaKind = "BLANK"
+
+	// COMMENT represents a `;` comment.
+	COMMENT TriviaKind = "COMMENT"
+)
+
+// Trivia represents source code that does not affect the meaning of
+// the program, such as comments and blank lines, which the scanner only
+// retains when explicitly requested to do so.
+type Trivia struct {
+	Kind TriviaKind
+
+	// Value contains the comment text including the leading `;`
+	// and excluding the final newline, or is empty for blank lines.
+	Value string
+}
+
 // Token represents a token with its type, position, and value.
 type Token struct {
 	TokenPos  Position
 	TokenType TokenType
 	Value     string
+
+	// TokenEnd is the position right after the token.
+	TokenEnd Position
+
+	// Source contains the literal exactly as written in the source code for
+	// STRING and CHAR tokens, whose Value is the string after processing
+	// escapes and the character, respectively.
+	Source string `json:",omitempty"`
+
+	// Leading contains the trivia on the lines preceding the token.
+	Leading []Trivia `json:",omitempty"`
+
+	// Trailing contains the trivia following the token on the same line.
+	Trailing []Trivia `json:",omitempty"`
 }
 
 // Clone creates a copy of the Token.
@@ -60,5 +140,14 @@ func (t Token) Clone() Token {
 		TokenPos:  t.TokenPos,
 		TokenType: t.TokenType,
 		Value:     t.Value,
+		TokenEnd:  t.TokenEnd,
+		Source:    t.Source,
+		Leading:   append([]Trivia(nil), t.Leading...),
+		Trailing:  append([]Trivia(nil), t.Trailing...),
 	}
 }
+
+// Span returns the source code span of the Token.
+func (t Token) Span() Span {
+	return Span{Start: t.TokenPos, End: t.TokenEnd}
+}
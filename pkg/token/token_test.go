@@ -20,6 +20,30 @@ func TestPositionString(t *testing.T) {
 	}
 }
 
+func TestSpanString(t *testing.T) {
+	span := token.Span{
+		Start: token.Position{FileName: "example.go", LineNumber: 10, LineColumn: 5, Offset: 100},
+		End:   token.Position{FileName: "example.go", LineNumber: 11, LineColumn: 2, Offset: 120},
+	}
+	expected := "example.go:10:5-11:2"
+	if span.String() != expected {
+		t.Errorf("expected %s, got %s", expected, span.String())
+	}
+}
+
+func TestTokenSpan(t *testing.T) {
+	tok := token.Token{
+		TokenPos:  token.Position{FileName: "example.go", LineNumber: 10, LineColumn: 5, Offset: 100},
+		TokenType: token.ATOM,
+		Value:     "example",
+		TokenEnd:  token.Position{FileName: "example.go", LineNumber: 10, LineColumn: 12, Offset: 107},
+	}
+	span := tok.Span()
+	if span.Start != tok.TokenPos || span.End != tok.TokenEnd {
+		t.Errorf("expected %v-%v, got %v", tok.TokenPos, tok.TokenEnd, span)
+	}
+}
+
 func TestTokenClone(t *testing.T) {
 	original := token.Token{
 		TokenPos: token.Position{
@@ -29,12 +53,20 @@ func TestTokenClone(t *testing.T) {
 		},
 		TokenType: token.ATOM,
 		Value:     "example",
+		TokenEnd: token.Position{
+			FileName:   "example.go",
+			LineNumber: 10,
+			LineColumn: 12,
+		},
 	}
 	clone := original.Clone()
 
 	if clone.TokenPos != original.TokenPos {
 		t.Errorf("expected %v, got %v", original.TokenPos, clone.TokenPos)
 	}
+	if clone.TokenEnd != original.TokenEnd {
+		t.Errorf("expected %v, got %v", original.TokenEnd, clone.TokenEnd)
+	}
 	if clone.TokenType != original.TokenType {
 		t.Errorf("expected %s, got %s", original.TokenType, clone.TokenType)
 	}
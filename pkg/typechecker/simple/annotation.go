@@ -153,8 +153,12 @@ func newAnnotationParser(tokens []token.Token) *annotationParser {
 //
 //	<atom> ::= "Any"
 //	         | "Bool"
+//	         | "Char"
 //	         | "Float64"
 //	         | "Int"
+//	         | "Keyword"
+//	         | "Quoted"
+//	         | "Rational"
 //	         | "String"
 //	         | "Unit"
 //
@@ -162,7 +166,13 @@ func newAnnotationParser(tokens []token.Token) *annotationParser {
 //
 //	<variadic> ::= OPEN "Variadic" <expr> CLOSE
 //
-//	<decorator> := <callable> | <union> | <variadic>
+//	<vector> ::= OPEN "Vector" <expr> CLOSE
+//
+//	<map> ::= OPEN "Map" <expr> <expr> CLOSE
+//
+//	<generator> ::= OPEN "Generator" <expr> CLOSE
+//
+//	<decorator> := <callable> | <generator> | <map> | <union> | <variadic> | <vector>
 func (p *annotationParser) Parse() (*Callable, error) {
 	// <annotation> ::= <callable> EOF
 	callable, err := p.parseCallable()
@@ -234,8 +244,12 @@ func (p *annotationParser) parseExpr() (visitor.Type, error) {
 func (p *annotationParser) parseAtom() (visitor.Type, error) {
 	//	<atom> ::= "Any"
 	//	         | "Bool"
+	//	         | "Char"
 	//	         | "Float64"
 	//	         | "Int"
+	//	         | "Keyword"
+	//	         | "Quoted"
+	//	         | "Rational"
 	//	         | "String"
 	//	         | "Unit"
 	switch p.peek().Value {
@@ -245,12 +259,24 @@ func (p *annotationParser) parseAtom() (visitor.Type, error) {
 	case "Bool":
 		p.advance()
 		return &Bool{}, nil
+	case "Char":
+		p.advance()
+		return &Char{}, nil
 	case "Float64":
 		p.advance()
 		return &Float64{}, nil
 	case "Int":
 		p.advance()
 		return &Int{}, nil
+	case "Keyword":
+		p.advance()
+		return &Keyword{}, nil
+	case "Quoted":
+		p.advance()
+		return &Quoted{}, nil
+	case "Rational":
+		p.advance()
+		return &Rational{}, nil
 	case "String":
 		p.advance()
 		return &String{}, nil
@@ -264,18 +290,66 @@ func (p *annotationParser) parseAtom() (visitor.Type, error) {
 
 // parseDecorator parses a decorator.
 func (p *annotationParser) parseDecorator() (visitor.Type, error) {
-	// <decorator> := <callable> | <union> | <variadic>
+	// <decorator> := <callable> | <generator> | <map> | <union> | <variadic> | <vector>
 	tok := p.peekNext()
 	switch {
 	case tok.TokenType == token.ATOM && tok.Value == "Callable":
 		return p.parseCallable()
+	case tok.TokenType == token.ATOM && tok.Value == "Generator":
+		return p.parseGenerator()
+	case tok.TokenType == token.ATOM && tok.Value == "Map":
+		return p.parseMap()
 	case tok.TokenType == token.ATOM && tok.Value == "Union":
 		return p.parseUnion()
 	case tok.TokenType == token.ATOM && tok.Value == "Variadic":
 		return p.parseVariadic()
+	case tok.TokenType == token.ATOM && tok.Value == "Vector":
+		return p.parseVector()
 	default:
-		return nil, p.newError("annotation parser: expected 'Callable', 'Union' or 'Variadic'")
+		return nil, p.newError("annotation parser: expected 'Callable', 'Generator', 'Map', 'Union', 'Variadic' or 'Vector'")
+	}
+}
+
+// parseGenerator parses a generator.
+func (p *annotationParser) parseGenerator() (visitor.Type, error) {
+	// <generator> ::= OPEN "Generator" <expr> CLOSE
+	if !p.match(token.OPEN) {
+		return nil, p.newError("annotation parser: expected '('")
+	}
+	if !p.match(token.ATOM) && p.peek().Value != "Generator" {
+		return nil, p.newError("annotation parser: expected 'Generator'")
+	}
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.match(token.CLOSE) {
+		return nil, p.newError("annotation parser: expected ')'")
+	}
+	return &Generator{Type: expr}, nil
+}
+
+// parseMap parses a map.
+func (p *annotationParser) parseMap() (visitor.Type, error) {
+	// <map> ::= OPEN "Map" <expr> <expr> CLOSE
+	if !p.match(token.OPEN) {
+		return nil, p.newError("annotation parser: expected '('")
+	}
+	if !p.match(token.ATOM) && p.peek().Value != "Map" {
+		return nil, p.newError("annotation parser: expected 'Map'")
+	}
+	key, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	value, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.match(token.CLOSE) {
+		return nil, p.newError("annotation parser: expected ')'")
 	}
+	return &Map{Key: key, Value: value}, nil
 }
 
 // parseUnion parses a union.
@@ -320,6 +394,25 @@ func (p *annotationParser) parseVariadic() (visitor.Type, error) {
 	return &Variadic{Type: expr}, nil
 }
 
+// parseVector parses a vector.
+func (p *annotationParser) parseVector() (visitor.Type, error) {
+	// <vector> ::= OPEN "Vector" <expr> CLOSE
+	if !p.match(token.OPEN) {
+		return nil, p.newError("annotation parser: expected '('")
+	}
+	if !p.match(token.ATOM) && p.peek().Value != "Vector" {
+		return nil, p.newError("annotation parser: expected 'Vector'")
+	}
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.match(token.CLOSE) {
+		return nil, p.newError("annotation parser: expected ')'")
+	}
+	return &Vector{Type: expr}, nil
+}
+
 // peek returns the next token without advancing the parser.
 func (p *annotationParser) peek() token.Token {
 	if p.current < len(p.tokens) {
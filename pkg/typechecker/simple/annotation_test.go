@@ -69,6 +69,30 @@ func TestParseAnnotation(t *testing.T) {
 				ReturnType:  &Any{},
 			},
 		},
+		{
+			input: "(Callable (Char (Map Keyword Char)) Keyword)",
+			expected: &Callable{
+				ParamsTypes: []visitor.Type{&Char{}, &Map{Key: &Keyword{}, Value: &Char{}}},
+				ReturnType:  &Keyword{},
+			},
+		},
+		{
+			input: "(Callable ((Vector Int) (Map String (Vector Bool))) Unit)",
+			expected: &Callable{
+				ParamsTypes: []visitor.Type{
+					&Vector{&Int{}},
+					&Map{Key: &String{}, Value: &Vector{&Bool{}}},
+				},
+				ReturnType: &Unit{},
+			},
+		},
+		{
+			input: "(Callable () (Generator Int))",
+			expected: &Callable{
+				ParamsTypes: nil,
+				ReturnType:  &Generator{&Int{}},
+			},
+		},
 		// Error cases
 		{
 			input:         "(Callable (Int) )",
@@ -90,9 +114,17 @@ func TestParseAnnotation(t *testing.T) {
 			input:         "(Callable (Int) (Variadic))",
 			expectedError: "<annotation>:1:26: annotation parser: expected '(' or an atom",
 		},
+		{
+			input:         "(Callable (Int) (Map Int))",
+			expectedError: "<annotation>:1:25: annotation parser: expected '(' or an atom",
+		},
+		{
+			input:         "(Callable (Int) (Vector Int Int))",
+			expectedError: "<annotation>:1:29: annotation parser: expected ')'",
+		},
 		{
 			input:         "(Callable (Int) (UnknownType))",
-			expectedError: "<annotation>:1:17: annotation parser: expected 'Callable', 'Union' or 'Variadic'",
+			expectedError: "<annotation>:1:17: annotation parser: expected 'Callable', 'Generator', 'Map', 'Union', 'Variadic' or 'Vector'",
 		},
 	}
 
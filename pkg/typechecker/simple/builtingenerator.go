@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package simple
+
+import (
+	"context"
+
+	"github.com/bassosimone/buresu/pkg/typechecker/visitor"
+)
+
+// The generator built-ins are generic over the type of the yielded
+// values, which we cannot express with a type annotation, hence we
+// define them here. Since we do not track the types of the values
+// passed to `yield!`, a new generator always yields `Any`.
+
+// newBuiltInGenerator returns the type of the `generator` built-in.
+func newBuiltInGenerator() *Callable {
+	return &Callable{
+		ParamsTypes: []visitor.Type{&Callable{ReturnType: &Any{}}},
+		ReturnType:  &Generator{&Any{}},
+		Body: func(ctx context.Context, args ...visitor.Type) (visitor.Type, error) {
+			return &Generator{&Any{}}, nil
+		},
+	}
+}
+
+// newBuiltInNext returns the type of the `next` built-in.
+func newBuiltInNext() *Callable {
+	return &Callable{
+		ParamsTypes: []visitor.Type{&Generator{&Any{}}},
+		ReturnType:  &Any{},
+		Body: func(ctx context.Context, args ...visitor.Type) (visitor.Type, error) {
+			return generatorElemType(args[0]), nil
+		},
+	}
+}
+
+// newBuiltInDone returns the type of the `done?` built-in.
+func newBuiltInDone() *Callable {
+	return &Callable{
+		ParamsTypes: []visitor.Type{&Generator{&Any{}}},
+		ReturnType:  &Bool{},
+		Body: func(ctx context.Context, args ...visitor.Type) (visitor.Type, error) {
+			return &Bool{}, nil
+		},
+	}
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package simple
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bassosimone/buresu/pkg/typechecker/visitor"
+)
+
+// The map built-ins are generic over the key and value types, which we
+// cannot express with a type annotation, hence we define them here.
+
+// newBuiltInMakeMap returns the type of the `make-map` built-in.
+func newBuiltInMakeMap() *Callable {
+	return &Callable{
+		ParamsTypes: []visitor.Type{},
+		ReturnType:  &Map{Key: &Any{}, Value: &Any{}},
+		Body: func(ctx context.Context, args ...visitor.Type) (visitor.Type, error) {
+			return &Map{Key: &Any{}, Value: &Any{}}, nil
+		},
+	}
+}
+
+// checkMapKeyType ensures the key type is compatible with the map type.
+func checkMapKeyType(mapType, keyType visitor.Type) error {
+	expected, _ := mapKeyValueTypes(mapType)
+	if !sameType(expected, keyType) {
+		return fmt.Errorf("%w for param #2 expected %s, got %s",
+			ErrWrongArgumentType, expected.String(), keyType.String())
+	}
+	return nil
+}
+
+// newBuiltInMapGet returns the type of the `map-get` built-in.
+func newBuiltInMapGet() *Callable {
+	return &Callable{
+		ParamsTypes: []visitor.Type{&Map{Key: &Any{}, Value: &Any{}}, &Any{}},
+		ReturnType:  &Any{},
+		Body: func(ctx context.Context, args ...visitor.Type) (visitor.Type, error) {
+			if err := checkMapKeyType(args[0], args[1]); err != nil {
+				return nil, err
+			}
+			_, valueType := mapKeyValueTypes(args[0])
+			return valueType, nil
+		},
+	}
+}
+
+// newBuiltInMapSet returns the type of the `map-set!` built-in.
+func newBuiltInMapSet() *Callable {
+	return &Callable{
+		ParamsTypes: []visitor.Type{&Map{Key: &Any{}, Value: &Any{}}, &Any{}, &Any{}},
+		ReturnType:  &Any{},
+		Body: func(ctx context.Context, args ...visitor.Type) (visitor.Type, error) {
+			if err := checkMapKeyType(args[0], args[1]); err != nil {
+				return nil, err
+			}
+			_, valueType := mapKeyValueTypes(args[0])
+			if !sameType(valueType, args[2]) {
+				return nil, fmt.Errorf("%w for param #3 expected %s, got %s",
+					ErrWrongArgumentType, valueType.String(), args[2].String())
+			}
+			return args[2], nil
+		},
+	}
+}
+
+// newBuiltInMapHas returns the type of the `map-has?` built-in.
+func newBuiltInMapHas() *Callable {
+	return &Callable{
+		ParamsTypes: []visitor.Type{&Map{Key: &Any{}, Value: &Any{}}, &Any{}},
+		ReturnType:  &Bool{},
+		Body: func(ctx context.Context, args ...visitor.Type) (visitor.Type, error) {
+			if err := checkMapKeyType(args[0], args[1]); err != nil {
+				return nil, err
+			}
+			return &Bool{}, nil
+		},
+	}
+}
+
+// newBuiltInMapDelete returns the type of the `map-delete!` built-in.
+func newBuiltInMapDelete() *Callable {
+	return &Callable{
+		ParamsTypes: []visitor.Type{&Map{Key: &Any{}, Value: &Any{}}, &Any{}},
+		ReturnType:  &Unit{},
+		Body: func(ctx context.Context, args ...visitor.Type) (visitor.Type, error) {
+			if err := checkMapKeyType(args[0], args[1]); err != nil {
+				return nil, err
+			}
+			return &Unit{}, nil
+		},
+	}
+}
+
+// newBuiltInMapKeys returns the type of the `map-keys` built-in.
+func newBuiltInMapKeys() *Callable {
+	return &Callable{
+		ParamsTypes: []visitor.Type{&Map{Key: &Any{}, Value: &Any{}}},
+		ReturnType:  &Vector{&Any{}},
+		Body: func(ctx context.Context, args ...visitor.Type) (visitor.Type, error) {
+			keyType, _ := mapKeyValueTypes(args[0])
+			return &Vector{keyType}, nil
+		},
+	}
+}
@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package simple
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bassosimone/buresu/pkg/typechecker/visitor"
+)
+
+// The vector built-ins are generic over the element type, which we cannot
+// express with a type annotation, hence we define them here.
+
+// newBuiltInMakeVector returns the type of the `make-vector` built-in.
+func newBuiltInMakeVector() *Callable {
+	return &Callable{
+		ParamsTypes: []visitor.Type{&Int{}, &Any{}},
+		ReturnType:  &Vector{&Any{}},
+		Body: func(ctx context.Context, args ...visitor.Type) (visitor.Type, error) {
+			return &Vector{args[1]}, nil
+		},
+	}
+}
+
+// newBuiltInVectorRef returns the type of the `vector-ref` built-in.
+func newBuiltInVectorRef() *Callable {
+	return &Callable{
+		ParamsTypes: []visitor.Type{&Vector{&Any{}}, &Int{}},
+		ReturnType:  &Any{},
+		Body: func(ctx context.Context, args ...visitor.Type) (visitor.Type, error) {
+			return vectorElemType(args[0]), nil
+		},
+	}
+}
+
+// newBuiltInVectorSet returns the type of the `vector-set!` built-in.
+func newBuiltInVectorSet() *Callable {
+	return &Callable{
+		ParamsTypes: []visitor.Type{&Vector{&Any{}}, &Int{}, &Any{}},
+		ReturnType:  &Any{},
+		Body: func(ctx context.Context, args ...visitor.Type) (visitor.Type, error) {
+			elemType := vectorElemType(args[0])
+			if !sameType(elemType, args[2]) {
+				return nil, fmt.Errorf("%w for param #3 expected %s, got %s",
+					ErrWrongArgumentType, elemType.String(), args[2].String())
+			}
+			return args[2], nil
+		},
+	}
+}
+
+// newBuiltInVectorLength returns the type of the `vector-length` built-in.
+func newBuiltInVectorLength() *Callable {
+	return &Callable{
+		ParamsTypes: []visitor.Type{&Vector{&Any{}}},
+		ReturnType:  &Int{},
+		Body: func(ctx context.Context, args ...visitor.Type) (visitor.Type, error) {
+			return &Int{}, nil
+		},
+	}
+}
@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package simple
+
+import (
+	"fmt"
+
+	"github.com/bassosimone/buresu/pkg/typechecker/visitor"
+)
+
+// ElementType implements [visitor.Environment].
+//
+// Strings yield characters, vectors yield their elements, maps yield
+// their keys and generators yield their values, mirroring the evaluator. Since
+// we cannot know what an `Any` or a `Unit` would yield, we use `Any`.
+func (env *Environment) ElementType(kind visitor.Type) (visitor.Type, error) {
+	switch kind := kind.(type) {
+	case *Any, *Unit:
+		return &Any{}, nil
+	case *String:
+		return &Char{}, nil
+	case *Vector:
+		return kind.Type, nil
+	case *Map:
+		return kind.Key, nil
+	case *Generator:
+		return kind.Type, nil
+	case *Union:
+		elems := NewUnion()
+		for _, member := range kind.Types {
+			elem, err := env.ElementType(member)
+			if err != nil {
+				return nil, err
+			}
+			elems.Add(elem)
+		}
+		return maybeMergeUnion(elems), nil
+	default:
+		return nil, fmt.Errorf("expected a sequence type, found %s", kind.String())
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/bassosimone/buresu/pkg/ast"
 	"github.com/bassosimone/buresu/pkg/token"
@@ -15,6 +16,12 @@ import (
 const (
 	// environmentFlagScopeFunc indicates that the scope is a function scope.
 	environmentFlagScopeFunc = 1 << iota
+
+	// environmentFlagScopeGlobal indicates that the scope is the global scope
+	// of a program or of a module, whose parent is the library scope, which
+	// contains the built-in functions and the prelude, and whose symbols we
+	// cannot redefine (see [*Environment.lookupDefined]).
+	environmentFlagScopeGlobal
 )
 
 // Environment is the environment used by the simple evaluator.
@@ -34,6 +41,15 @@ type Environment struct {
 
 	// symbols contains the symbols defined in the current environment.
 	symbols map[string]visitor.Type
+
+	// definitions contains the spans of the nodes that defined the
+	// symbols in the current environment, when known.
+	definitions map[string]token.Span
+
+	// modules contains the imported modules indexed by file path.
+	//
+	// Only the root environment uses this field.
+	modules map[string]visitor.Environment
 }
 
 // Environment implements [visitor.Environment].
@@ -42,10 +58,12 @@ var _ visitor.Environment = (*Environment)(nil)
 // NewEnvironment creates a new [*Environment] instance.
 func NewEnvironment() *Environment {
 	return &Environment{
-		flags:   0,
-		parent:  nil,
-		rts:     NewUnion(),
-		symbols: make(map[string]visitor.Type),
+		flags:       0,
+		parent:      nil,
+		rts:         NewUnion(),
+		symbols:     make(map[string]visitor.Type),
+		definitions: make(map[string]token.Span),
+		modules:     make(map[string]visitor.Environment),
 	}
 }
 
@@ -92,13 +110,27 @@ func (env *Environment) PushBlockScope() visitor.Environment {
 	return env.pushScope(0)
 }
 
+// PushModuleScope implements [visitor.Environment].
+func (env *Environment) PushModuleScope() visitor.Environment {
+	return env.root().pushScope(environmentFlagScopeGlobal)
+}
+
+// root returns the root environment.
+func (env *Environment) root() *Environment {
+	for env.parent != nil {
+		env = env.parent
+	}
+	return env
+}
+
 // pushScope creates a new child environment with the given flags and returns it.
 func (env *Environment) pushScope(flags int) *Environment {
 	return &Environment{
-		flags:   flags,
-		parent:  env,
-		rts:     NewUnion(),
-		symbols: make(map[string]visitor.Type),
+		flags:       flags,
+		parent:      env,
+		rts:         NewUnion(),
+		symbols:     make(map[string]visitor.Type),
+		definitions: make(map[string]token.Span),
 	}
 }
 
@@ -119,18 +151,107 @@ func (env *Environment) GetType(symbol string) (visitor.Type, error) {
 	return env.NewUnitType(), fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
 }
 
+// Symbols returns the sorted names of the symbols visible from
+// the current environment, including the ones of the parents.
+func (env *Environment) Symbols() []string {
+	uniq := make(map[string]struct{})
+	for cur := env; cur != nil; cur = cur.parent {
+		for symbol := range cur.symbols {
+			uniq[symbol] = struct{}{}
+		}
+	}
+	symbols := make([]string, 0, len(uniq))
+	for symbol := range uniq {
+		symbols = append(symbols, symbol)
+	}
+	slices.Sort(symbols)
+	return symbols
+}
+
+// GetModule implements [visitor.Environment].
+func (env *Environment) GetModule(filePath string) (visitor.Environment, bool) {
+	module, found := env.root().modules[filePath]
+	return module, found
+}
+
+// ErrModuleAlreadyDefined is the error returned when a module is already defined.
+var ErrModuleAlreadyDefined = errors.New("module already defined")
+
+// DefineModule implements [visitor.Environment].
+func (env *Environment) DefineModule(filePath string, module visitor.Environment) error {
+	root := env.root()
+	if _, found := root.modules[filePath]; found {
+		return fmt.Errorf("%w: %s", ErrModuleAlreadyDefined, filePath)
+	}
+	root.modules[filePath] = module
+	return nil
+}
+
 // ErrSymbolAlreadyDefined is the error returned when a symbol is already defined.
 var ErrSymbolAlreadyDefined = errors.New("symbol already defined")
 
-// DefineValue implements [visitor.Environment].
-func (env *Environment) DefineType(symbol string, value visitor.Type) error {
+// SymbolAlreadyDefinedError is the [ErrSymbolAlreadyDefined] error
+// containing the span of the previous definition of the symbol.
+type SymbolAlreadyDefinedError struct {
+	// Symbol is the symbol that is already defined.
+	Symbol string
+
+	// Previous is the span of the previous definition, which
+	// is the zero value for built-in symbols.
+	Previous token.Span
+}
+
+// Error returns the error message.
+func (e *SymbolAlreadyDefinedError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrSymbolAlreadyDefined.Error(), e.Symbol)
+}
+
+// Unwrap returns [ErrSymbolAlreadyDefined].
+func (e *SymbolAlreadyDefinedError) Unwrap() error {
+	return ErrSymbolAlreadyDefined
+}
+
+// errSymbolAlreadyDefined returns the [*SymbolAlreadyDefinedError] for the given symbol.
+func (env *Environment) errSymbolAlreadyDefined(symbol string) error {
+	return &SymbolAlreadyDefinedError{Symbol: symbol, Previous: env.definitions[symbol]}
+}
+
+// lookupDefined returns the kind of the given symbol if it is defined in the
+// current scope or, for global scopes, in the library scope, along with the
+// scope defining it, such that we cannot redefine built-ins and prelude symbols.
+func (env *Environment) lookupDefined(symbol string) (visitor.Type, *Environment, bool) {
+	if kind, found := env.symbols[symbol]; found {
+		return kind, env, true
+	}
+	if env.flags&environmentFlagScopeGlobal != 0 {
+		if kind, found := env.parent.symbols[symbol]; found {
+			return kind, env.parent, true
+		}
+	}
+	return nil, nil, false
+}
+
+// setDefinition records the span of the node defining the given symbol.
+func (env *Environment) setDefinition(node ast.Node, symbol string) {
+	if node != nil {
+		env.definitions[symbol] = ast.NodeSpan(node)
+	}
+}
+
+// DefineType implements [visitor.Environment].
+func (env *Environment) DefineType(node ast.Node, symbol string, value visitor.Type) error {
 	if callable, ok := value.(*Callable); ok {
-		return env.defineCallable(symbol, callable)
+		if err := env.defineCallable(symbol, callable); err != nil {
+			return err
+		}
+		env.setDefinition(node, symbol)
+		return nil
 	}
-	if _, found := env.symbols[symbol]; found {
-		return fmt.Errorf("%w: %s", ErrSymbolAlreadyDefined, symbol)
+	if _, owner, found := env.lookupDefined(symbol); found {
+		return owner.errSymbolAlreadyDefined(symbol)
 	}
 	env.symbols[symbol] = value
+	env.setDefinition(node, symbol)
 	return nil
 }
 
@@ -138,13 +259,13 @@ func (env *Environment) DefineType(symbol string, value visitor.Type) error {
 func (env *Environment) defineCallable(symbol string, callable *Callable) error {
 	// if the previous symbol is a callable in the current environment,  owerwrite it
 	// with the new callable and add a reference to it in the new callable
-	if entry, found := env.symbols[symbol]; found {
+	if entry, owner, found := env.lookupDefined(symbol); found {
 		if prevCallable, ok := entry.(*Callable); ok {
 			env.symbols[symbol] = callable
 			callable.Previous = prevCallable
 			return nil
 		}
-		return fmt.Errorf("%w: %s", ErrSymbolAlreadyDefined, symbol)
+		return owner.errSymbolAlreadyDefined(symbol)
 	}
 
 	// Otherwise, search for the symbol in previous scopes. If not found
@@ -189,9 +310,34 @@ func (env *Environment) SetType(symbol string, value visitor.Type) error {
 	return fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
 }
 
+// Error is the error returned by [*Environment.WrapError], which
+// contains the token of the node that caused the error.
+type Error struct {
+	Tok token.Token
+	Err error
+
+	// End is the position right after the node that caused the error.
+	End token.Position
+}
+
+// Span returns the source code span of the node that caused the error.
+func (e *Error) Span() token.Span {
+	return token.Span{Start: e.Tok.TokenPos, End: e.End}
+}
+
+// Error returns the error message with file position details.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: typechecker: %s", e.Tok.TokenPos, e.Err.Error())
+}
+
+// Unwrap returns the wrapped error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
 // WrapError implements [visitor.Environment].
-func (env *Environment) WrapError(tok token.Token, err error) error {
-	return fmt.Errorf("%s: typechecker: %w", tok.TokenPos, err)
+func (env *Environment) WrapError(node ast.Node, err error) error {
+	return &Error{Tok: ast.NodeToken(node), Err: err, End: ast.NodeSpan(node).End}
 }
 
 // CheckCondition implements [visitor.Environment].
@@ -201,7 +347,21 @@ func (env *Environment) CheckCondition(ctx context.Context, node ast.Node) error
 		return err
 	}
 	if _, ok := kind.(*Bool); !ok {
-		return fmt.Errorf("condition must be *simple.Bool, found %T", kind)
+		return fmt.Errorf("condition must be Bool, found %s", kind.String())
 	}
 	return nil
 }
+
+// CheckRangeBound implements [visitor.Environment].
+func (env *Environment) CheckRangeBound(ctx context.Context, node ast.Node) error {
+	kind, err := visitor.Check(ctx, env, node)
+	if err != nil {
+		return err
+	}
+	switch kind.(type) {
+	case *Int, *Any:
+		return nil
+	default:
+		return env.WrapError(node, fmt.Errorf("range bound must be Int, found %s", kind.String()))
+	}
+}
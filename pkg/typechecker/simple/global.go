@@ -4,8 +4,8 @@ package simple
 
 import (
 	"context"
-	"path/filepath"
 
+	"github.com/bassosimone/buresu/internal/rtx"
 	"github.com/bassosimone/buresu/pkg/ast"
 	"github.com/bassosimone/buresu/pkg/includer"
 	"github.com/bassosimone/buresu/pkg/token"
@@ -13,49 +13,84 @@ import (
 )
 
 // NewGlobalEnvironment creates a new global environment loading the
-// standard library runtime from the given base path.
-func NewGlobalEnvironment(ctx context.Context, basePath string) (*Environment, error) {
+// standard library runtime and prelude from the given standard library
+// location using the given optional cache. We do not use the search path,
+// so that user directories cannot replace the runtime and the prelude.
+//
+// The returned environment is the global scope of the program, whose parent
+// is the library environment, which contains the built-in functions, the
+// runtime and the prelude. We use the library environment as the parent of
+// modules, so they cannot see the program's symbols, and programs and modules
+// cannot redefine its symbols.
+func NewGlobalEnvironment(ctx context.Context, stdlib includer.Location, cache *includer.Cache) (*Environment, error) {
 	env := NewEnvironment()
 
 	// define the `display` built-in function
-	env.DefineType("display", &Callable{
+	rtx.Must(env.DefineType(nil, "display", &Callable{
 		ParamsTypes: []visitor.Type{&Variadic{&Any{}}},
 		ReturnType:  &Unit{},
 		Body: func(ctx context.Context, args ...visitor.Type) (visitor.Type, error) {
 			return &Unit{}, nil
 		},
 		Previous: nil,
-	})
+	}))
+
+	// define the collections and generators built-in functions, which are generic
+	builtins := map[string]*Callable{
+		"done?":         newBuiltInDone(),
+		"generator":     newBuiltInGenerator(),
+		"make-map":      newBuiltInMakeMap(),
+		"make-vector":   newBuiltInMakeVector(),
+		"map-delete!":   newBuiltInMapDelete(),
+		"map-get":       newBuiltInMapGet(),
+		"map-has?":      newBuiltInMapHas(),
+		"map-keys":      newBuiltInMapKeys(),
+		"map-set!":      newBuiltInMapSet(),
+		"next":          newBuiltInNext(),
+		"vector-length": newBuiltInVectorLength(),
+		"vector-ref":    newBuiltInVectorRef(),
+		"vector-set!":   newBuiltInVectorSet(),
+	}
+	for name, builtin := range builtins {
+		rtx.Must(env.DefineType(nil, name, builtin))
+	}
 
 	// most of the standard library runtime is defined in the runtime.brs file
-	err := loadStdlibRuntime(ctx, basePath, env)
-	return env, err
+	if err := loadStdlibFile(ctx, stdlib, cache, "<runtime>", "runtime/runtime.brs", env); err != nil {
+		return env, err
+	}
+
+	// the prelude contains library functions written in buresu
+	if err := loadStdlibFile(ctx, stdlib, cache, "<prelude>", "prelude.brs", env); err != nil {
+		return env, err
+	}
+	return env.pushScope(environmentFlagScopeGlobal), nil
 }
 
-// loadStdlibRuntime loads the standard library runtime.
-func loadStdlibRuntime(ctx context.Context, basePath string, tcEnv *Environment) error {
-	// manually create the AST node for including the runtime
-	runtime := &ast.IncludeStmt{
+// loadStdlibFile loads and typechecks a standard library file.
+func loadStdlibFile(ctx context.Context, stdlib includer.Location, cache *includer.Cache, origin, filePath string, tcEnv *Environment) error {
+	// manually create the AST node for including the file
+	include := &ast.IncludeStmt{
 		Token: token.Token{
 			TokenPos: token.Position{
-				FileName:   "<runtime>",
+				FileName:   origin,
 				LineNumber: 1,
 				LineColumn: 1,
 			},
 			TokenType: token.ATOM,
 			Value:     "include",
 		},
-		FilePath: filepath.Join("stdlib", "runtime", "runtime.brs"),
+		FilePath: filePath,
 	}
-	nodes := []ast.Node{runtime}
+	nodes := []ast.Node{include}
 
-	// use the includer to pull the nodes from the runtime file(s)
-	nodes, err := includer.Include(basePath, nodes)
+	// use the includer to pull the nodes from the file(s)
+	nodes, err := includer.IncludeWithCache(cache, []includer.Location{stdlib}, nodes)
 	if err != nil {
 		return err
 	}
 
-	// run the typechecker on the runtime nodes
+	// run the typechecker on the included nodes
 	for _, node := range nodes {
 		if _, err := Check(ctx, tcEnv, node); err != nil {
 			return err
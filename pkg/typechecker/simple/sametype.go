@@ -14,7 +14,61 @@ func sameType(a, b visitor.Type) bool {
 		return true
 	}
 
+	// A union is the same type as another type when all of its members
+	// are the same type as some member of the other type, which allows,
+	// e.g., returning `(Union (Vector Int) (Vector Unit))` from a lambda
+	// declared to return `(Vector Any)`
+	if at, ok := a.(*Union); ok {
+		for _, kind := range at.Types {
+			if !sameType(kind, b) {
+				return false
+			}
+		}
+		return true
+	}
+	if bt, ok := b.(*Union); ok {
+		for _, kind := range bt.Types {
+			if sameType(a, kind) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Compare collections structurally, so that, e.g., `(Vector Any)`
+	// is considered the same as `(Vector Int)`
+	switch at := a.(type) {
+	case *Vector:
+		bt, ok := b.(*Vector)
+		return ok && sameType(at.Type, bt.Type)
+	case *Map:
+		bt, ok := b.(*Map)
+		return ok && sameType(at.Key, bt.Key) && sameType(at.Value, bt.Value)
+	case *Generator:
+		bt, ok := b.(*Generator)
+		return ok && sameType(at.Type, bt.Type)
+	case *Callable:
+		bt, ok := b.(*Callable)
+		return ok && sameCallableType(at, bt)
+	}
+
 	// TODO(bassosimone): consider using a more robust form of comparison
 	// than comparing the string representation of the types
 	return a.String() == b.String()
 }
+
+// sameCallableType compares two callables structurally, so that a lambda
+// taking `Int` can be passed where a `(Callable (Any) Any)` is expected.
+//
+// We only compare the most recent overload of each callable.
+func sameCallableType(a, b *Callable) bool {
+	if len(a.ParamsTypes) != len(b.ParamsTypes) {
+		return false
+	}
+	for idx := range a.ParamsTypes {
+		if !sameType(a.ParamsTypes[idx], b.ParamsTypes[idx]) {
+			return false
+		}
+	}
+	return sameType(a.ReturnType, b.ReturnType)
+}
@@ -5,17 +5,19 @@ package simple_test
 import (
 	"bytes"
 	"context"
-	"path/filepath"
+	"errors"
 	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 
 	"github.com/bassosimone/buresu/internal/txtartesting"
+	"github.com/bassosimone/buresu/pkg/includer"
 	"github.com/bassosimone/buresu/pkg/parser"
 	"github.com/bassosimone/buresu/pkg/scanner"
 	"github.com/bassosimone/buresu/pkg/typechecker/simple"
 	"github.com/bassosimone/buresu/pkg/typechecker/visitor"
+	"github.com/bassosimone/buresu/stdlib"
 )
 
 func TestCheck(t *testing.T) {
@@ -35,10 +37,14 @@ func TestCheck(t *testing.T) {
 			if err != nil {
 				t.Fatalf("failed to parse input code: %v", err)
 			}
+			nodes, err = includer.Include([]includer.Location{includer.DirLocation("testdata")}, nodes)
+			if err != nil {
+				t.Fatalf("failed to include files: %v", err)
+			}
 
 			// Evaluate the parsed nodes
 			ctx := context.Background()
-			env, err := simple.NewGlobalEnvironment(ctx, filepath.Join("..", "..", ".."))
+			env, err := simple.NewGlobalEnvironment(ctx, stdlib.Location(), nil)
 			if err != nil {
 				t.Fatalf("failed to create global environment: %v", err)
 			}
@@ -80,3 +86,22 @@ func TestCheck(t *testing.T) {
 		})
 	}
 }
+
+func TestErrorSpan(t *testing.T) {
+	tokens, err := scanner.Scan("input.brs", strings.NewReader("(block\n  (set! x 1))"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes, err := parser.Parse(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = visitor.Check(context.Background(), simple.NewEnvironment(), nodes[0])
+	var terr *simple.Error
+	if !errors.As(err, &terr) {
+		t.Fatalf("expected *simple.Error, got %v", err)
+	}
+	if got := terr.Span().String(); got != "input.brs:2:3-2:13" {
+		t.Fatalf("unexpected span: %s", got)
+	}
+}
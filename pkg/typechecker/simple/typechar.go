@@ -0,0 +1,21 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package simple
+
+import "github.com/bassosimone/buresu/pkg/typechecker/visitor"
+
+// NewCharType implements [visitor.Environment].
+func (env *Environment) NewCharType() visitor.Type {
+	return &Char{}
+}
+
+// Char represents a character type.
+type Char struct{}
+
+// Ensure Char implements [visitor.Type].
+var _ visitor.Type = (*Char)(nil)
+
+// String implements [visitor.Type].
+func (v *Char) String() string {
+	return "Char"
+}
@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package simple
+
+import (
+	"fmt"
+
+	"github.com/bassosimone/buresu/pkg/typechecker/visitor"
+)
+
+// Generator represents a generator yielding values of the given type.
+type Generator struct {
+	Type visitor.Type
+}
+
+// Ensure Generator implements [visitor.Type].
+var _ visitor.Type = (*Generator)(nil)
+
+// String implements [visitor.Type].
+func (t *Generator) String() string {
+	return fmt.Sprintf("(Generator %s)", t.Type.String())
+}
+
+// generatorElemType returns the type of the values yielded by the
+// given generator type, or [Any] if the type is not a generator.
+func generatorElemType(t visitor.Type) visitor.Type {
+	if gen, ok := t.(*Generator); ok {
+		return gen.Type
+	}
+	return &Any{}
+}
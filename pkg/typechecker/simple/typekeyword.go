@@ -0,0 +1,21 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package simple
+
+import "github.com/bassosimone/buresu/pkg/typechecker/visitor"
+
+// NewKeywordType implements [visitor.Environment].
+func (env *Environment) NewKeywordType() visitor.Type {
+	return &Keyword{}
+}
+
+// Keyword represents a keyword type.
+type Keyword struct{}
+
+// Ensure Keyword implements [visitor.Type].
+var _ visitor.Type = (*Keyword)(nil)
+
+// String implements [visitor.Type].
+func (v *Keyword) String() string {
+	return "Keyword"
+}
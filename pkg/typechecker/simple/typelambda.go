@@ -13,17 +13,30 @@ import (
 
 // NewLambdaType implements [visitor.Environment].
 func (env *Environment) NewLambdaType(node *ast.LambdaExpr) (visitor.Type, error) {
-	lambda := &Callable{
+	var (
+		lambda   *Callable
+		checking bool
+	)
+	lambda = &Callable{
 		ParamsTypes: []visitor.Type{}, // set below
 		ReturnType:  &Any{},
 		Body: func(ctx context.Context, args ...visitor.Type) (visitor.Type, error) {
+			// a recursive call reached while we are still checking the body
+			// would recurse forever (e.g., mutually recursive lambdas defined
+			// using letrec), so we trust the declared return type instead
+			if checking {
+				return lambda.ReturnType, nil
+			}
+			checking = true
+			defer func() { checking = false }()
+
 			// create the environment for the function call, which is a child of the
 			// closure environment with the parameters bound to the arguments
 			closure := env.PushFunctionScope()
 			for idx, arg := range args {
 				// the parser guarantees that params names are not duplicated
 				// so I do not see how this define could fail
-				rtx.Must(closure.DefineType(node.Params[idx], arg))
+				rtx.Must(closure.DefineType(node, node.Params[idx], arg))
 			}
 
 			// check the body of the lambda function in the new environment
@@ -0,0 +1,32 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package simple
+
+import (
+	"fmt"
+
+	"github.com/bassosimone/buresu/pkg/typechecker/visitor"
+)
+
+// Map represents a map from keys to values of the given types.
+type Map struct {
+	Key   visitor.Type
+	Value visitor.Type
+}
+
+// Ensure Map implements [visitor.Type].
+var _ visitor.Type = (*Map)(nil)
+
+// String implements [visitor.Type].
+func (t *Map) String() string {
+	return fmt.Sprintf("(Map %s %s)", t.Key.String(), t.Value.String())
+}
+
+// mapKeyValueTypes returns the key and value types of the given
+// map type, or [Any] for both if the type is not a map.
+func mapKeyValueTypes(t visitor.Type) (visitor.Type, visitor.Type) {
+	if m, ok := t.(*Map); ok {
+		return m.Key, m.Value
+	}
+	return &Any{}, &Any{}
+}
@@ -9,5 +9,16 @@ import (
 
 // NewQuotedType implements [visitor.Environment].
 func (env *Environment) NewQuotedType(node *ast.QuoteExpr) visitor.Type {
-	return &Any{}
+	return &Quoted{}
+}
+
+// Quoted represents the type of a quoted expression.
+type Quoted struct{}
+
+// Ensure Quoted implements [visitor.Type].
+var _ visitor.Type = (*Quoted)(nil)
+
+// String implements [visitor.Type].
+func (v *Quoted) String() string {
+	return "Quoted"
 }
@@ -0,0 +1,21 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package simple
+
+import "github.com/bassosimone/buresu/pkg/typechecker/visitor"
+
+// NewRationalType implements [visitor.Environment].
+func (env *Environment) NewRationalType() visitor.Type {
+	return &Rational{}
+}
+
+// Rational represents a rational type.
+type Rational struct{}
+
+// Ensure Rational implements [visitor.Type].
+var _ visitor.Type = (*Rational)(nil)
+
+// String implements [visitor.Type].
+func (v *Rational) String() string {
+	return "Rational"
+}
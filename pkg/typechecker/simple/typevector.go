@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package simple
+
+import (
+	"fmt"
+
+	"github.com/bassosimone/buresu/pkg/typechecker/visitor"
+)
+
+// Vector represents a vector whose elements have the given type.
+type Vector struct {
+	Type visitor.Type
+}
+
+// Ensure Vector implements [visitor.Type].
+var _ visitor.Type = (*Vector)(nil)
+
+// String implements [visitor.Type].
+func (t *Vector) String() string {
+	return fmt.Sprintf("(Vector %s)", t.Type.String())
+}
+
+// vectorElemType returns the type of the elements of the given
+// vector type, or [Any] if the type is not a vector.
+func vectorElemType(t visitor.Type) visitor.Type {
+	if vec, ok := t.(*Vector); ok {
+		return vec.Type
+	}
+	return &Any{}
+}
@@ -9,6 +9,7 @@ import (
 	"context"
 
 	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/includer"
 	"github.com/bassosimone/buresu/pkg/typechecker/simple"
 	"github.com/bassosimone/buresu/pkg/typechecker/visitor"
 )
@@ -19,10 +20,14 @@ type Type = visitor.Type
 // Environment is the execution environment used by the evaluator.
 type Environment = simple.Environment
 
+// Error is the error containing the token of the node that failed to typecheck.
+type Error = simple.Error
+
 // NewGlobalEnvironment creates a new global environment loading the
-// standard library runtime from the given base path.
-func NewGlobalEnvironment(ctx context.Context, basePath string) (*Environment, error) {
-	return simple.NewGlobalEnvironment(ctx, basePath)
+// standard library runtime and prelude from the given standard library
+// location using the given optional cache.
+func NewGlobalEnvironment(ctx context.Context, stdlib includer.Location, cache *includer.Cache) (*Environment, error) {
+	return simple.NewGlobalEnvironment(ctx, stdlib, cache)
 }
 
 // Check evaluates the type of a node in the AST and returns the result.
@@ -4,8 +4,10 @@ package visitor
 
 import (
 	"context"
+	"errors"
 
 	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/token"
 )
 
 func checkCallExpr(ctx context.Context, env Environment, node *ast.CallExpr) (Type, error) {
@@ -23,5 +25,20 @@ func checkCallExpr(ctx context.Context, env Environment, node *ast.CallExpr) (Ty
 	//
 	// it will be the callable's responsibility to push a
 	// function scope for the arguments
-	return env.Call(ctx, node.Callable, argsTypes...)
+	rvType, err := env.Call(ctx, node.Callable, argsTypes...)
+	if err != nil {
+		return nil, wrapCallError(env, node, err)
+	}
+	return rvType, nil
+}
+
+// wrapCallError wraps the given error using the position of the call unless
+// the error already contains a position (e.g., because it occurred inside the
+// body of the callee), such that we report the innermost failing expression.
+func wrapCallError(env Environment, node *ast.CallExpr, err error) error {
+	var spanner interface{ Span() token.Span }
+	if errors.As(err, &spanner) {
+		return err
+	}
+	return env.WrapError(node, err)
 }
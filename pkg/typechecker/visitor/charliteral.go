@@ -0,0 +1,13 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+)
+
+func checkCharLiteral(_ context.Context, env Environment, _ *ast.CharLiteral) (Type, error) {
+	return env.NewCharType(), nil
+}
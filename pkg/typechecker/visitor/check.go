@@ -21,12 +21,21 @@ func Check(ctx context.Context, env Environment, node ast.Node) (Type, error) {
 	case *ast.BlockExpr:
 		return checkBlockExpr(ctx, env, node)
 
+	case *ast.BreakStmt:
+		return checkBreakStmt(ctx, env, node)
+
 	case *ast.CallExpr:
 		return checkCallExpr(ctx, env, node)
 
+	case *ast.CharLiteral:
+		return checkCharLiteral(ctx, env, node)
+
 	case *ast.CondExpr:
 		return checkCondExpr(ctx, env, node)
 
+	case *ast.ContinueStmt:
+		return checkContinueStmt(ctx, env, node)
+
 	case *ast.DeclareExpr:
 		return checkDeclareExpr(ctx, env, node)
 
@@ -42,15 +51,45 @@ func Check(ctx context.Context, env Environment, node ast.Node) (Type, error) {
 	case *ast.FloatLiteral:
 		return checkFloatLiteral(ctx, env, node)
 
+	case *ast.ForExpr:
+		return checkForExpr(ctx, env, node)
+
+	case *ast.ForeachExpr:
+		return checkForeachExpr(ctx, env, node)
+
+	case *ast.ImportStmt:
+		return checkImportStmt(ctx, env, node)
+
 	case *ast.IntLiteral:
 		return checkIntLiteral(ctx, env, node)
 
+	case *ast.KeywordLiteral:
+		return checkKeywordLiteral(ctx, env, node)
+
 	case *ast.LambdaExpr:
 		return evalLambdaExpr(ctx, env, node)
 
+	case *ast.LetExpr:
+		return checkLetExpr(ctx, env, node)
+
+	case *ast.LetStarExpr:
+		return checkLetStarExpr(ctx, env, node)
+
+	case *ast.LetrecExpr:
+		return checkLetrecExpr(ctx, env, node)
+
+	case *ast.ModuleStmt:
+		return checkModuleStmt(ctx, env, node)
+
+	case *ast.QuasiquoteExpr:
+		return checkQuasiquoteExpr(ctx, env, node)
+
 	case *ast.QuoteExpr:
 		return checkQuoteExpr(ctx, env, node)
 
+	case *ast.RationalLiteral:
+		return checkRationalLiteral(ctx, env, node)
+
 	case *ast.ReturnStmt:
 		return checkReturnStmt(ctx, env, node)
 
@@ -72,6 +111,9 @@ func Check(ctx context.Context, env Environment, node ast.Node) (Type, error) {
 	case *ast.WhileExpr:
 		return checkWhileExpr(ctx, env, node)
 
+	case *ast.YieldStmt:
+		return checkYieldStmt(ctx, env, node)
+
 	default:
 		return nil, fmt.Errorf("unsupported node type: %T", node)
 	}
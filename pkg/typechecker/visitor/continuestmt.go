@@ -0,0 +1,16 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+)
+
+func checkContinueStmt(ctx context.Context, env Environment, node *ast.ContinueStmt) (Type, error) {
+	// The continue! statement interrupts execution and produces no value
+	// but, like for return!, the parser only allows it as a statement
+	// inside a block, hence using Unit as its type is pragmatic.
+	return env.NewUnitType(), nil
+}
@@ -19,8 +19,8 @@ func checkDeclareExpr(ctx context.Context, env Environment, node *ast.DeclareExp
 		return nil, err
 	}
 
-	if err := env.DefineType(node.Symbol, exprType); err != nil {
-		return nil, env.WrapError(node.Token, err)
+	if err := env.DefineType(node, node.Symbol, exprType); err != nil {
+		return nil, env.WrapError(node, err)
 	}
 
 	return exprType, nil
@@ -15,8 +15,8 @@ func checkDefineExpr(ctx context.Context, env Environment, node *ast.DefineExpr)
 		return nil, err
 	}
 
-	if err := env.DefineType(node.Symbol, exprType); err != nil {
-		return nil, env.WrapError(node.Token, err)
+	if err := env.DefineType(node, node.Symbol, exprType); err != nil {
+		return nil, env.WrapError(node, err)
 	}
 
 	return exprType, nil
@@ -6,7 +6,6 @@ import (
 	"context"
 
 	"github.com/bassosimone/buresu/pkg/ast"
-	"github.com/bassosimone/buresu/pkg/token"
 )
 
 // Environment is the generic interface for the environment.
@@ -15,16 +14,33 @@ type Environment interface {
 	// the function can potentially return via `(return! ...)`.
 	AddReturnType(kind Type) error
 
-	// DefineType defines a new symbol type in the current environment.
-	DefineType(symbol string, value Type) error
+	// DefineModule saves the environment of the module imported from
+	// the given file path, so that we check each module only once.
+	DefineModule(filePath string, module Environment) error
+
+	// DefineType defines a new symbol type in the current environment. The node
+	// is the one defining the symbol, which is nil for built-in symbols.
+	DefineType(node ast.Node, symbol string, value Type) error
 
 	// CheckCondition checks whether the current node evaluates
 	// to a boolean value and otherwise returns an error.
 	CheckCondition(ctx context.Context, predicate ast.Node) error
 
+	// CheckRangeBound checks whether the current node evaluates
+	// to an integer value and otherwise returns an error.
+	CheckRangeBound(ctx context.Context, node ast.Node) error
+
 	// Call attempts to call a given node and returns the result type.
 	Call(ctx context.Context, node ast.Node, args ...Type) (Type, error)
 
+	// ElementType returns the type of the elements yielded when
+	// iterating over a value of the given type using `foreach`.
+	ElementType(kind Type) (Type, error)
+
+	// GetModule returns the environment of the module imported from the
+	// given file path, if we have already checked such a module.
+	GetModule(filePath string) (Environment, bool)
+
 	// GetType returns the type associated with the given symbol.
 	//
 	// If the symbol is not found in the current environment, the parent
@@ -38,6 +54,9 @@ type Environment interface {
 	// NewBoolType returns a new bool type instance.
 	NewBoolType() Type
 
+	// NewCharType returns a new char type instance.
+	NewCharType() Type
+
 	// NewEllipsisType returns a new ellipsis type instance.
 	NewEllipsisType() Type
 
@@ -47,12 +66,18 @@ type Environment interface {
 	// NewIntType returns a new int type instance.
 	NewIntType() Type
 
+	// NewKeywordType returns a new keyword type instance.
+	NewKeywordType() Type
+
 	// NewLambdaType returns a new lambda type instance.
 	NewLambdaType(node *ast.LambdaExpr) (Type, error)
 
 	// NewQuotedType returns a new quoted type instance.
 	NewQuotedType(node *ast.QuoteExpr) Type
 
+	// NewRationalType returns a new rational type instance.
+	NewRationalType() Type
+
 	// NewStringType returns a new string type instance.
 	NewStringType() Type
 
@@ -72,9 +97,14 @@ type Environment interface {
 	// use the current environment as its parent.
 	PushFunctionScope() Environment
 
+	// PushModuleScope creates a new environment for checking a module
+	// and returns it. The returned environment will use the root environment
+	// as its parent, so that modules cannot see the importer's symbols.
+	PushModuleScope() Environment
+
 	// SetType sets the type of an existing symbol in the current environment.
 	SetType(symbol string, value Type) error
 
-	// WrapError wraps an error adding contextual token information.
-	WrapError(tok token.Token, err error) error
+	// WrapError wraps an error adding the source code span of the given node.
+	WrapError(node ast.Node, err error) error
 }
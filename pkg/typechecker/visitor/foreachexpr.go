@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+)
+
+func checkForeachExpr(ctx context.Context, env Environment, node *ast.ForeachExpr) (Type, error) {
+	seqType, err := Check(ctx, env, node.Seq)
+	if err != nil {
+		return nil, err
+	}
+	elemType, err := env.ElementType(seqType)
+	if err != nil {
+		return nil, env.WrapError(node.Seq, err)
+	}
+
+	// each iteration binds the loop variable in a fresh block scope
+	scope := env.PushBlockScope()
+	if err := scope.DefineType(node, node.Symbol, elemType); err != nil {
+		return nil, err
+	}
+	if _, err := Check(ctx, scope, node.Expr); err != nil {
+		return nil, err
+	}
+
+	return env.NewUnitType(), nil
+}
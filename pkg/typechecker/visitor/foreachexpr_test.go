@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/token"
+)
+
+func TestCheckForeachExpr(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctxFunc  func() context.Context
+		env      *mockEnvironment
+		wantType Type
+		wantErr  bool
+	}{
+		{
+			name:     "foreach loop with normal context",
+			ctxFunc:  normalContext,
+			env:      &mockEnvironment{},
+			wantType: &mockType{name: "Unit"},
+			wantErr:  false,
+		},
+		{
+			name:     "foreach loop with canceled context",
+			ctxFunc:  canceledContext,
+			env:      &mockEnvironment{},
+			wantType: nil,
+			wantErr:  true,
+		},
+		{
+			name:     "foreach loop over a non-sequence type",
+			ctxFunc:  normalContext,
+			env:      &mockEnvironment{err: errors.New("expected a sequence type")},
+			wantType: nil,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tt.ctxFunc()
+			node := &ast.ForeachExpr{
+				Token:  token.Token{TokenType: token.ATOM, Value: "foreach"},
+				Symbol: "x",
+				Seq:    &ast.StringLiteral{Token: token.Token{TokenType: token.STRING, Value: "\"foobar\""}, Value: "foobar"},
+				Expr:   &ast.UnitExpr{Token: token.Token{TokenType: token.OPEN, Value: "("}},
+			}
+			gotType, err := checkForeachExpr(ctx, tt.env, node)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkForeachExpr() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotType != nil && gotType.String() != tt.wantType.String() {
+				t.Errorf("checkForeachExpr() gotType = %v, want %v", gotType, tt.wantType)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+)
+
+func checkForExpr(ctx context.Context, env Environment, node *ast.ForExpr) (Type, error) {
+	if err := env.CheckRangeBound(ctx, node.From); err != nil {
+		return nil, err
+	}
+	if err := env.CheckRangeBound(ctx, node.To); err != nil {
+		return nil, err
+	}
+
+	// each iteration binds the loop variable in a fresh block scope
+	scope := env.PushBlockScope()
+	if err := scope.DefineType(node, node.Symbol, env.NewIntType()); err != nil {
+		return nil, err
+	}
+	if _, err := Check(ctx, scope, node.Expr); err != nil {
+		return nil, err
+	}
+
+	return env.NewUnitType(), nil
+}
@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+)
+
+// errNotAModule is returned when the import statement does not contain a module,
+// which happens if we did not run the includer before checking.
+var errNotAModule = errors.New("import: missing module declaration")
+
+// checkImportStmt checks an import statement.
+func checkImportStmt(ctx context.Context, env Environment, node *ast.ImportStmt) (Type, error) {
+	// 1. make sure the includer has loaded a module
+	if len(node.Nodes) <= 0 {
+		return nil, env.WrapError(node, errNotAModule)
+	}
+	decl, ok := node.Nodes[0].(*ast.ModuleStmt)
+	if !ok {
+		return nil, env.WrapError(node, errNotAModule)
+	}
+
+	// 2. check the module unless we have already checked it
+	module, found := env.GetModule(node.FilePath)
+	if !found {
+		module = env.PushModuleScope()
+		for _, expr := range node.Nodes {
+			if _, err := Check(ctx, module, expr); err != nil {
+				return nil, err
+			}
+		}
+		if err := env.DefineModule(node.FilePath, module); err != nil {
+			return nil, env.WrapError(node, err)
+		}
+	}
+
+	// 3. bind the type of each exported symbol as `<alias>/<symbol>`
+	for _, symbol := range decl.Exports {
+		kind, err := module.GetType(symbol)
+		if err != nil {
+			return nil, env.WrapError(node, err)
+		}
+		if err := env.DefineType(node, node.Alias+"/"+symbol, kind); err != nil {
+			return nil, env.WrapError(node, err)
+		}
+	}
+	return env.NewUnitType(), nil
+}
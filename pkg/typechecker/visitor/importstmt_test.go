@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/token"
+)
+
+func TestCheckImportStmt(t *testing.T) {
+	env := &mockEnvironment{}
+
+	module := &ast.ModuleStmt{
+		Token:   token.Token{TokenType: token.ATOM, Value: "module"},
+		Name:    "math",
+		Exports: []string{"x"},
+	}
+
+	tests := []struct {
+		name    string
+		nodes   []ast.Node
+		wantErr error
+	}{
+		{
+			name:    "successful import",
+			nodes:   []ast.Node{module},
+			wantErr: nil,
+		},
+		{
+			name:    "import not processed by the includer",
+			nodes:   nil,
+			wantErr: errNotAModule,
+		},
+		{
+			name:    "import without module declaration",
+			nodes:   []ast.Node{&ast.UnitExpr{}},
+			wantErr: errNotAModule,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &ast.ImportStmt{
+				Token:    token.Token{TokenType: token.ATOM, Value: "import"},
+				FilePath: "math.brs",
+				Alias:    "math",
+				Nodes:    tt.nodes,
+			}
+			typ, err := checkImportStmt(normalContext(), env, node)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("checkImportStmt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && typ.String() != "Unit" {
+				t.Errorf("expected Unit, got %s", typ.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+)
+
+func checkKeywordLiteral(_ context.Context, env Environment, _ *ast.KeywordLiteral) (Type, error) {
+	return env.NewKeywordType(), nil
+}
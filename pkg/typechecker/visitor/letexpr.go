@@ -0,0 +1,30 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+)
+
+func checkLetExpr(ctx context.Context, env Environment, node *ast.LetExpr) (Type, error) {
+	// 1. check all the bindings in the enclosing environment
+	types := make([]Type, 0, len(node.Bindings))
+	for _, binding := range node.Bindings {
+		kind, err := Check(ctx, env, binding.Expr)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, kind)
+	}
+
+	// 2. bind the types in a new block scope and check the body
+	env = env.PushBlockScope()
+	for idx, binding := range node.Bindings {
+		if err := env.DefineType(binding.Expr, binding.Symbol, types[idx]); err != nil {
+			return nil, env.WrapError(node, err)
+		}
+	}
+	return Check(ctx, env, node.Expr)
+}
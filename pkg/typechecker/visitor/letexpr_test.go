@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+	"github.com/bassosimone/buresu/pkg/token"
+)
+
+func TestCheckLetExprs(t *testing.T) {
+	env := &mockEnvironment{}
+
+	tok := token.Token{TokenType: token.OPEN, Value: "("}
+	bindings := []ast.LetBinding{
+		{Symbol: "x", Expr: &ast.IntLiteral{Token: token.Token{TokenType: token.NUMBER, Value: "1"}, Value: "1"}},
+	}
+	body := &ast.StringLiteral{Token: token.Token{TokenType: token.STRING, Value: "\"foobar\""}, Value: "foobar"}
+
+	tests := []struct {
+		name     string
+		ctxFunc  func() context.Context
+		node     ast.Node
+		expected Type
+		wantErr  bool
+	}{
+		{
+			name:     "let with normal context",
+			ctxFunc:  normalContext,
+			node:     &ast.LetExpr{Token: tok, Bindings: bindings, Expr: body},
+			expected: &mockType{"String"},
+			wantErr:  false,
+		},
+		{
+			name:     "let* with normal context",
+			ctxFunc:  normalContext,
+			node:     &ast.LetStarExpr{Token: tok, Bindings: bindings, Expr: body},
+			expected: &mockType{"String"},
+			wantErr:  false,
+		},
+		{
+			name:     "letrec with normal context",
+			ctxFunc:  normalContext,
+			node:     &ast.LetrecExpr{Token: tok, Bindings: bindings, Expr: body},
+			expected: &mockType{"String"},
+			wantErr:  false,
+		},
+		{
+			name:     "let with canceled context",
+			ctxFunc:  canceledContext,
+			node:     &ast.LetExpr{Token: tok, Bindings: bindings, Expr: body},
+			expected: nil,
+			wantErr:  true,
+		},
+		{
+			name:     "let* with canceled context",
+			ctxFunc:  canceledContext,
+			node:     &ast.LetStarExpr{Token: tok, Bindings: bindings, Expr: body},
+			expected: nil,
+			wantErr:  true,
+		},
+		{
+			name:     "letrec with canceled context",
+			ctxFunc:  canceledContext,
+			node:     &ast.LetrecExpr{Token: tok, Bindings: bindings, Expr: body},
+			expected: nil,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tt.ctxFunc()
+			got, err := Check(ctx, env, tt.node)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != nil && got.String() != tt.expected.String() {
+				t.Errorf("Check() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+)
+
+func checkLetrecExpr(ctx context.Context, env Environment, node *ast.LetrecExpr) (Type, error) {
+	// 1. create the scope and give all the symbols the unit type such
+	// that the lambdas checked below can refer to each other
+	env = env.PushBlockScope()
+	for _, binding := range node.Bindings {
+		if err := env.DefineType(binding.Expr, binding.Symbol, env.NewUnitType()); err != nil {
+			return nil, env.WrapError(node, err)
+		}
+	}
+
+	// 2. check the bindings inside the new scope and assign their types
+	for _, binding := range node.Bindings {
+		kind, err := Check(ctx, env, binding.Expr)
+		if err != nil {
+			return nil, err
+		}
+		if err := env.SetType(binding.Symbol, kind); err != nil {
+			return nil, env.WrapError(node, err)
+		}
+	}
+
+	// 3. check the body
+	return Check(ctx, env, node.Expr)
+}
@@ -0,0 +1,27 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+)
+
+func checkLetStarExpr(ctx context.Context, env Environment, node *ast.LetStarExpr) (Type, error) {
+	// mirror the evaluator: each binding lives in its own nested block scope
+	env = env.PushBlockScope()
+	for idx, binding := range node.Bindings {
+		kind, err := Check(ctx, env, binding.Expr)
+		if err != nil {
+			return nil, err
+		}
+		if idx > 0 {
+			env = env.PushBlockScope()
+		}
+		if err := env.DefineType(binding.Expr, binding.Symbol, kind); err != nil {
+			return nil, env.WrapError(node, err)
+		}
+	}
+	return Check(ctx, env, node.Expr)
+}
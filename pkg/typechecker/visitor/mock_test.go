@@ -6,7 +6,6 @@ import (
 	"context"
 
 	"github.com/bassosimone/buresu/pkg/ast"
-	"github.com/bassosimone/buresu/pkg/token"
 )
 
 func normalContext() context.Context {
@@ -28,7 +27,11 @@ func (m *mockEnvironment) AddReturnType(kind Type) error {
 	return nil
 }
 
-func (m *mockEnvironment) DefineType(symbol string, value Type) error {
+func (m *mockEnvironment) DefineModule(filePath string, module Environment) error {
+	return nil
+}
+
+func (m *mockEnvironment) DefineType(node ast.Node, symbol string, value Type) error {
 	return nil
 }
 
@@ -36,10 +39,22 @@ func (m *mockEnvironment) CheckCondition(ctx context.Context, predicate ast.Node
 	return nil
 }
 
+func (m *mockEnvironment) CheckRangeBound(ctx context.Context, node ast.Node) error {
+	return nil
+}
+
 func (m *mockEnvironment) Call(ctx context.Context, node ast.Node, args ...Type) (Type, error) {
 	return nil, nil
 }
 
+func (m *mockEnvironment) ElementType(kind Type) (Type, error) {
+	return &mockType{"Any"}, m.err
+}
+
+func (m *mockEnvironment) GetModule(filePath string) (Environment, bool) {
+	return nil, false
+}
+
 func (m *mockEnvironment) GetType(symbol string) (Type, error) {
 	return nil, nil
 }
@@ -72,6 +87,18 @@ func (m *mockEnvironment) NewQuotedType(node *ast.QuoteExpr) Type {
 	return nil
 }
 
+func (m *mockEnvironment) NewCharType() Type {
+	return &mockType{"Char"}
+}
+
+func (m *mockEnvironment) NewKeywordType() Type {
+	return &mockType{"Keyword"}
+}
+
+func (m *mockEnvironment) NewRationalType() Type {
+	return &mockType{"Rational"}
+}
+
 func (m *mockEnvironment) NewStringType() Type {
 	return &mockType{"String"}
 }
@@ -92,11 +119,15 @@ func (m *mockEnvironment) PushFunctionScope() Environment {
 	return m
 }
 
+func (m *mockEnvironment) PushModuleScope() Environment {
+	return m
+}
+
 func (m *mockEnvironment) SetType(symbol string, value Type) error {
 	return nil
 }
 
-func (m *mockEnvironment) WrapError(tok token.Token, err error) error {
+func (m *mockEnvironment) WrapError(node ast.Node, err error) error {
 	return err
 }
 
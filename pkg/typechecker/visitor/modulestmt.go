@@ -0,0 +1,17 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+)
+
+// checkModuleStmt checks a module statement.
+func checkModuleStmt(_ context.Context, env Environment, _ *ast.ModuleStmt) (Type, error) {
+	// The module statement only matters when importing, where checkImportStmt
+	// uses it to know which symbols to export. Otherwise, the module
+	// statement is just a way to get the unit type.
+	return env.NewUnitType(), nil
+}
@@ -0,0 +1,21 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+)
+
+func checkQuasiquoteExpr(ctx context.Context, env Environment, node *ast.QuasiquoteExpr) (Type, error) {
+	// 1. check the unquoted expressions, which we evaluate
+	for _, unquote := range node.Unquotes() {
+		if _, err := Check(ctx, env, unquote.Expr); err != nil {
+			return nil, err
+		}
+	}
+
+	// 2. the result has the same type of a quoted expression
+	return env.NewQuotedType(&ast.QuoteExpr{Token: node.Token, End: node.End, Expr: node.Expr}), nil
+}
@@ -0,0 +1,13 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+)
+
+func checkRationalLiteral(_ context.Context, env Environment, _ *ast.RationalLiteral) (Type, error) {
+	return env.NewRationalType(), nil
+}
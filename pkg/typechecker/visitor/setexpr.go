@@ -15,7 +15,7 @@ func checkSetExpr(ctx context.Context, env Environment, node *ast.SetExpr) (Type
 	}
 
 	if err := env.SetType(node.Symbol, exprType); err != nil {
-		return nil, env.WrapError(node.Token, err)
+		return nil, env.WrapError(node, err)
 	}
 
 	return exprType, nil
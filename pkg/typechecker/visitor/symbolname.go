@@ -9,5 +9,9 @@ import (
 )
 
 func checkSymbolName(_ context.Context, env Environment, node *ast.SymbolName) (Type, error) {
-	return env.GetType(node.Value)
+	value, err := env.GetType(node.Value)
+	if err != nil {
+		return nil, env.WrapError(node, err)
+	}
+	return value, nil
 }
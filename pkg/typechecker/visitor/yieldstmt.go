@@ -0,0 +1,20 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package visitor
+
+import (
+	"context"
+
+	"github.com/bassosimone/buresu/pkg/ast"
+)
+
+func checkYieldStmt(ctx context.Context, env Environment, node *ast.YieldStmt) (Type, error) {
+	if _, err := Check(ctx, env, node.Expr); err != nil {
+		return nil, err
+	}
+
+	// The yield! statement evaluates to unit once the consumer of the
+	// generator resumes it, and we do not track the types of the yielded
+	// values, hence generators always yield values of type Any.
+	return env.NewUnitType(), nil
+}
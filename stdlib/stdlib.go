@@ -0,0 +1,20 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package stdlib contains the standard library embedded into the binary.
+package stdlib
+
+import (
+	"embed"
+
+	"github.com/bassosimone/buresu/pkg/includer"
+)
+
+// FS contains the standard library source files.
+//
+//go:embed prelude.brs runtime/*.brs
+var FS embed.FS
+
+// Location returns the [includer.Location] of the embedded standard library.
+func Location() includer.Location {
+	return includer.Location{Name: "<stdlib>", FS: FS}
+}